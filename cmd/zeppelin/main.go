@@ -6,12 +6,19 @@ import (
 	"fmt"
 	"io/fs"
 	"log"
+	"maps"
 	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 
 	zeppelin "github.com/gronitab/zeppelin"
+	"github.com/gronitab/zeppelin/internal/config"
+	"github.com/gronitab/zeppelin/internal/eventlog"
+	"github.com/gronitab/zeppelin/internal/events"
 	"github.com/gronitab/zeppelin/internal/poller"
 	"github.com/gronitab/zeppelin/internal/server"
 	"github.com/gronitab/zeppelin/internal/sse"
@@ -19,13 +26,63 @@ import (
 )
 
 func main() {
+	configPath := flag.String("config", "", "Configuration file (default "+config.UserPath()+"; each root's "+config.RootFile+" overrides its own town's settings)")
 	port := flag.Int("port", 7331, "HTTP server port")
-	root := flag.String("root", defaultRoot(), "Gas Town root directory")
+	var roots []townRoot
+	flag.Func("root", `Gas Town root directory as "[name=]path" (repeatable; default ~/gt)`, func(v string) error {
+		r, err := parseRoot(v)
+		if err != nil {
+			return err
+		}
+		for _, o := range roots {
+			if o.name == r.name {
+				return fmt.Errorf("town %q given twice", r.name)
+			}
+		}
+		roots = append(roots, r)
+		return nil
+	})
 	bind := flag.String("bind", "127.0.0.1", "Bind address")
+	mailTTL := flag.Duration("mail-ttl", 10*time.Second, "How long mail edges stay visible")
+	maxCommands := flag.Int("max-commands", 4, "Maximum gt/bd commands running at once")
+	cmdTimeout := flag.Duration("cmd-timeout", 10*time.Second, "Default timeout for each gt/bd command")
+	noWatch := flag.Bool("no-watch", false, "Poll on fixed intervals instead of watching the root for changes")
+	record := flag.String("record", "", "Save every gt/bd command's output as a fixture in this directory")
+	replay := flag.String("replay", "", "Serve gt/bd output from fixtures in this directory instead of running commands")
+	dataDir := flag.String("data-dir", defaultDataDir(), `Directory for each town's event log, restored on restart ("" disables; off by default with --record and --replay)`)
+	timeouts := make(map[string]time.Duration)
+	flag.Func("timeout", `Per-command timeout as "command=duration", e.g. "bd list=30s" (repeatable)`, func(v string) error {
+		cmd, d, ok := strings.Cut(v, "=")
+		if !ok || strings.TrimSpace(cmd) == "" {
+			return fmt.Errorf("expected command=duration, got %q", v)
+		}
+		dur, err := time.ParseDuration(d)
+		if err != nil {
+			return err
+		}
+		timeouts[strings.TrimSpace(cmd)] = dur
+		return nil
+	})
 	flag.Parse()
+	if *record != "" && *replay != "" {
+		log.Fatal("--record and --replay are mutually exclusive")
+	}
 
-	store := state.NewStore()
-	broker := sse.NewBroker()
+	// Flags given on the command line override the configuration files.
+	set := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	cfg, roots, err := loadConfig(*configPath, roots)
+	if err != nil {
+		log.Fatalf("config: %v", err)
+	}
+	fromConfig(set, "port", port, cfg.Port)
+	fromConfig(set, "bind", bind, cfg.Bind)
+	fromConfig(set, "data-dir", dataDir, expandHome(cfg.DataDir))
+	if (*record != "" || *replay != "") && !set["data-dir"] {
+		// Fixtures are not the town; keep them out of its log.
+		*dataDir = ""
+	}
+	execRunner := poller.ExecRunner{Binaries: cfg.Commands.Binaries, Env: cfg.Commands.EnvList()}
 
 	// Set up frontend filesystem from embedded assets.
 	frontendFS, err := fs.Sub(zeppelin.FrontendFS, "frontend/dist")
@@ -33,24 +90,112 @@ func main() {
 		log.Fatalf("failed to load frontend: %v", err)
 	}
 
-	srv := server.New(store, broker, frontendFS)
+	// Each town gets its own store, broker and poller. The all-towns broker
+	// serves the combined stream when there are several.
+	all := sse.NewBroker()
+	towns := make([]*server.Town, len(roots))
+	for i, r := range roots {
+		towns[i] = &server.Town{Name: r.name, Root: r.path, Store: state.NewStore(), Broker: sse.NewBroker()}
+		towns[i].Store.SetActivityLimit(r.cfg.Retention.Activity)
+		towns[i].Store.SetHistoryLimit(r.cfg.Retention.History)
+	}
+	var logs []*eventlog.Log
+	if *dataDir != "" {
+		for i, t := range towns {
+			retention := roots[i].cfg.Retention
+			logOpts := eventlog.Options{
+				SegmentSize: retention.LogSegmentSize,
+				Segments:    retention.LogSegments,
+				MaxAge:      time.Duration(retention.LogAge),
+			}
+			l, err := restore(t, filepath.Join(*dataDir, t.Name), logOpts)
+			if err != nil {
+				log.Printf("eventlog: %v; town %q will not be saved", err, t.Name)
+				continue
+			}
+			logs = append(logs, l)
+		}
+	}
+	srv := server.New(towns, all, frontendFS)
+	srv.Token = cfg.Auth.Token
+	if cfg.Retention.Activity > 0 {
+		srv.ActivityLimit = cfg.Retention.Activity
+	}
 
-	// Start the poller.
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	p := poller.New(store, *root, func() {
-		// On state change, broadcast to all SSE clients.
-		if broker.ClientCount() > 0 {
-			snap := store.GetSnapshot()
-			broker.Broadcast(snap)
+	pollers := make([]*poller.Poller, len(towns))
+	for i, t := range towns {
+		// The town's configuration fills in what the flags leave unset.
+		tc := roots[i].cfg
+		opts := poller.Options{
+			MailTTL:        *mailTTL,
+			Intervals:      config.Durations(tc.Intervals),
+			SafetyInterval: time.Duration(tc.SafetyInterval),
+			MaxConcurrent:  *maxCommands,
+			CommandTimeout: *cmdTimeout,
+			Timeouts:       maps.Clone(timeouts),
+			NoWatch:        *noWatch,
+			Runner:         execRunner,
+			Viewers: func() int {
+				return t.Broker.ClientCount() + all.ClientCount()
+			},
 		}
-	})
-	go p.Run(ctx)
+		fromConfig(set, "mail-ttl", &opts.MailTTL, time.Duration(tc.MailTTL))
+		fromConfig(set, "max-commands", &opts.MaxConcurrent, tc.Commands.MaxConcurrent)
+		fromConfig(set, "cmd-timeout", &opts.CommandTimeout, time.Duration(tc.Commands.Timeout))
+		if tc.Watch != nil && !set["no-watch"] {
+			opts.NoWatch = !*tc.Watch
+		}
+		for cmd, d := range config.Durations(tc.Commands.Timeouts) {
+			if _, ok := timeouts[cmd]; !ok {
+				opts.Timeouts[cmd] = d
+			}
+		}
+		switch {
+		case *record != "":
+			opts.Runner = &poller.RecordingRunner{Runner: execRunner, Dir: fixtureDir(*record, t.Name, len(towns)), Root: t.Root}
+		case *replay != "":
+			opts.Runner = &poller.ReplayRunner{Dir: fixtureDir(*replay, t.Name, len(towns)), Root: t.Root}
+		}
+		p := poller.New(t.Store, t.Root, opts, func(diff *state.Diff) {
+			// On state change, broadcast the diff to the town's SSE clients.
+			srv.Publish(t, diff)
+		})
+		p.OnHealth = func(ev state.HealthEvent) {
+			srv.Publish(t, ev)
+		}
+		// The poller backs off while nobody watches; catch up when someone does.
+		t.Broker.OnConnect = func(clients int) {
+			if clients == 1 {
+				p.Refresh()
+			}
+		}
+		pollers[i] = p
+
+		// Tail the events feed so activity reaches clients as soon as it is
+		// written. Its backfill skips what the store restored.
+		tailer := events.NewTailer(filepath.Join(t.Root, ".events.jsonl"), func(a state.Activity) {
+			p.AppendActivity(a)
+		})
+		tailer.Since = lastActivity(t.Store.GetSnapshot())
+		go p.Run(ctx)
+		go tailer.Run(ctx)
+	}
+	all.OnConnect = func(clients int) {
+		if clients == 1 {
+			for _, p := range pollers {
+				p.Refresh()
+			}
+		}
+	}
 
 	addr := fmt.Sprintf("%s:%d", *bind, *port)
 	log.Printf("Zeppelin starting on http://%s", addr)
-	log.Printf("Gas Town root: %s", *root)
+	for _, t := range towns {
+		log.Printf("Gas Town %q: %s", t.Name, t.Root)
+	}
 
 	httpSrv := &http.Server{
 		Addr:    addr,
@@ -70,6 +215,137 @@ func main() {
 	if err := httpSrv.ListenAndServe(); err != http.ErrServerClosed {
 		log.Fatalf("server error: %v", err)
 	}
+	for _, l := range logs {
+		if err := l.Close(); err != nil {
+			log.Printf("eventlog: %v", err)
+		}
+	}
+}
+
+// restore loads the town's last state from its event log in dir, then keeps
+// the log up to date.
+func restore(t *server.Town, dir string, opts eventlog.Options) (*eventlog.Log, error) {
+	l, err := eventlog.Open(dir, opts)
+	if err != nil {
+		return nil, err
+	}
+	snap, diffs, ok, err := l.Load()
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		t.Store.Restore(snap, diffs)
+		log.Printf("Gas Town %q: restored %d nodes from %s", t.Name, len(t.Store.GetSnapshot().Nodes), dir)
+	}
+	t.Store.SetJournal(l)
+	return l, nil
+}
+
+// lastActivity returns the time of the newest activity in snap.
+func lastActivity(snap state.Snapshot) time.Time {
+	var last time.Time
+	for _, a := range snap.Activity {
+		if a.Timestamp.After(last) {
+			last = a.Timestamp
+		}
+	}
+	return last
+}
+
+// townRoot is a named Gas Town root from --root or the configuration.
+type townRoot struct {
+	name, path string
+	cfg        config.Config // the user configuration with the root file's overrides
+}
+
+// parseRoot parses "[name=]path". Without a name, the town is named after
+// the directory.
+func parseRoot(v string) (townRoot, error) {
+	name, path, ok := strings.Cut(v, "=")
+	if !ok {
+		path = v
+		name = filepath.Base(filepath.Clean(v))
+	}
+	if path == "" {
+		return townRoot{}, fmt.Errorf("empty path in %q", v)
+	}
+	if err := config.CheckTownName(name); err != nil {
+		return townRoot{}, fmt.Errorf("%w in %q", err, v)
+	}
+	return townRoot{name: name, path: expandHome(path)}, nil
+}
+
+// loadConfig reads the user configuration, or the file given with --config,
+// and then each town root's, which applies to that town only. Towns given
+// with --root replace those the configuration lists; with neither, ~/gt is
+// served.
+func loadConfig(path string, roots []townRoot) (config.Config, []townRoot, error) {
+	var cfg config.Config
+	required := path != ""
+	if !required {
+		path = config.UserPath()
+	}
+	if path != "" {
+		if err := cfg.Load(path, required); err != nil {
+			return cfg, nil, err
+		}
+	}
+
+	if len(roots) == 0 {
+		for _, name := range slices.Sorted(maps.Keys(cfg.Towns)) {
+			roots = append(roots, townRoot{name: name, path: expandHome(cfg.Towns[name])})
+		}
+	}
+	if len(roots) == 0 {
+		roots = []townRoot{{name: "gt", path: defaultRoot()}}
+	}
+
+	for i := range roots {
+		town, err := cfg.ForTown(roots[i].path)
+		if err != nil {
+			return cfg, nil, err
+		}
+		roots[i].cfg = town
+	}
+	return cfg, roots, nil
+}
+
+// fromConfig replaces a flag's default with the configured value, unless the
+// flag was given or the value is unset.
+func fromConfig[T comparable](set map[string]bool, name string, flagVal *T, cfgVal T) {
+	var zero T
+	if !set[name] && cfgVal != zero {
+		*flagVal = cfgVal
+	}
+}
+
+// expandHome resolves a leading "~/" to the user's home directory.
+func expandHome(path string) string {
+	rest, ok := strings.CutPrefix(path, "~/")
+	if !ok {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, rest)
+}
+
+// fixtureDir keeps each town's fixtures apart when several are served.
+func fixtureDir(dir, town string, towns int) string {
+	if towns == 1 {
+		return dir
+	}
+	return filepath.Join(dir, town)
+}
+
+func defaultDataDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "zeppelin")
 }
 
 func defaultRoot() string {
@@ -1,18 +1,993 @@
-(function(){const e=document.createElement("link").relList;if(e&&e.supports&&e.supports("modulepreload"))return;for(const i of document.querySelectorAll('link[rel="modulepreload"]'))r(i);new MutationObserver(i=>{for(const o of i)if(o.type==="childList")for(const a of o.addedNodes)a.tagName==="LINK"&&a.rel==="modulepreload"&&r(a)}).observe(document,{childList:!0,subtree:!0});function n(i){const o={};return i.integrity&&(o.integrity=i.integrity),i.referrerPolicy&&(o.referrerPolicy=i.referrerPolicy),i.crossOrigin==="use-credentials"?o.credentials="include":i.crossOrigin==="anonymous"?o.credentials="omit":o.credentials="same-origin",o}function r(i){if(i.ep)return;i.ep=!0;const o=n(i);fetch(i.href,o)}})();var Fn={value:()=>{}};function zt(){for(var t=0,e=arguments.length,n={},r;t<e;++t){if(!(r=arguments[t]+"")||r in n||/[\s.]/.test(r))throw new Error("illegal type: "+r);n[r]=[]}return new Yt(n)}function Yt(t){this._=t}function On(t,e){return t.trim().split(/^|\s+/).map(function(n){var r="",i=n.indexOf(".");if(i>=0&&(r=n.slice(i+1),n=n.slice(0,i)),n&&!e.hasOwnProperty(n))throw new Error("unknown type: "+n);return{type:n,name:r}})}Yt.prototype=zt.prototype={constructor:Yt,on:function(t,e){var n=this._,r=On(t+"",n),i,o=-1,a=r.length;if(arguments.length<2){for(;++o<a;)if((i=(t=r[o]).type)&&(i=Yn(n[i],t.name)))return i;return}if(e!=null&&typeof e!="function")throw new Error("invalid callback: "+e);for(;++o<a;)if(i=(t=r[o]).type)n[i]=De(n[i],t.name,e);else if(e==null)for(i in n)n[i]=De(n[i],t.name,null);return this},copy:function(){var t={},e=this._;for(var n in e)t[n]=e[n].slice();return new Yt(t)},call:function(t,e){if((i=arguments.length-2)>0)for(var n=new Array(i),r=0,i,o;r<i;++r)n[r]=arguments[r+2];if(!this._.hasOwnProperty(t))throw new Error("unknown type: "+t);for(o=this._[t],r=0,i=o.length;r<i;++r)o[r].value.apply(e,n)},apply:function(t,e,n){if(!this._.hasOwnProperty(t))throw new Error("unknown type: "+t);for(var r=this._[t],i=0,o=r.length;i<o;++i)r[i].value.apply(e,n)}};function Yn(t,e){for(var n=0,r=t.length,i;n<r;++n)if((i=t[n]).name===e)return i.value}function De(t,e,n){for(var r=0,i=t.length;r<i;++r)if(t[r].name===e){t[r]=Fn,t=t.slice(0,r).concat(t.slice(r+1));break}return n!=null&&t.push({name:e,value:n}),t}var fe="http://www.w3.org/1999/xhtml";const Re={svg:"http://www.w3.org/2000/svg",xhtml:fe,xlink:"http://www.w3.org/1999/xlink",xml:"http://www.w3.org/XML/1998/namespace",xmlns:"http://www.w3.org/2000/xmlns/"};function te(t){var e=t+="",n=e.indexOf(":");return n>=0&&(e=t.slice(0,n))!=="xmlns"&&(t=t.slice(n+1)),Re.hasOwnProperty(e)?{space:Re[e],local:t}:t}function qn(t){return function(){var e=this.ownerDocument,n=this.namespaceURI;return n===fe&&e.documentElement.namespaceURI===fe?e.createElement(t):e.createElementNS(n,t)}}function Vn(t){return function(){return this.ownerDocument.createElementNS(t.space,t.local)}}function cn(t){var e=te(t);return(e.local?Vn:qn)(e)}function Un(){}function Ee(t){return t==null?Un:function(){return this.querySelector(t)}}function Gn(t){typeof t!="function"&&(t=Ee(t));for(var e=this._groups,n=e.length,r=new Array(n),i=0;i<n;++i)for(var o=e[i],a=o.length,s=r[i]=new Array(a),c,u,l=0;l<a;++l)(c=o[l])&&(u=t.call(c,c.__data__,l,o))&&("__data__"in c&&(u.__data__=c.__data__),s[l]=u);return new V(r,this._parents)}function Kn(t){return t==null?[]:Array.isArray(t)?t:Array.from(t)}function Qn(){return[]}function ln(t){return t==null?Qn:function(){return this.querySelectorAll(t)}}function Wn(t){return function(){return Kn(t.apply(this,arguments))}}function Zn(t){typeof t=="function"?t=Wn(t):t=ln(t);for(var e=this._groups,n=e.length,r=[],i=[],o=0;o<n;++o)for(var a=e[o],s=a.length,c,u=0;u<s;++u)(c=a[u])&&(r.push(t.call(c,c.__data__,u,a)),i.push(c));return new V(r,i)}function fn(t){return function(){return this.matches(t)}}function hn(t){return function(e){return e.matches(t)}}var Jn=Array.prototype.find;function jn(t){return function(){return Jn.call(this.children,t)}}function tr(){return this.firstElementChild}function er(t){return this.select(t==null?tr:jn(typeof t=="function"?t:hn(t)))}var nr=Array.prototype.filter;function rr(){return Array.from(this.children)}function ir(t){return function(){return nr.call(this.children,t)}}function or(t){return this.selectAll(t==null?rr:ir(typeof t=="function"?t:hn(t)))}function ar(t){typeof t!="function"&&(t=fn(t));for(var e=this._groups,n=e.length,r=new Array(n),i=0;i<n;++i)for(var o=e[i],a=o.length,s=r[i]=[],c,u=0;u<a;++u)(c=o[u])&&t.call(c,c.__data__,u,o)&&s.push(c);return new V(r,this._parents)}function dn(t){return new Array(t.length)}function sr(){return new V(this._enter||this._groups.map(dn),this._parents)}function Kt(t,e){this.ownerDocument=t.ownerDocument,this.namespaceURI=t.namespaceURI,this._next=null,this._parent=t,this.__data__=e}Kt.prototype={constructor:Kt,appendChild:function(t){return this._parent.insertBefore(t,this._next)},insertBefore:function(t,e){return this._parent.insertBefore(t,e)},querySelector:function(t){return this._parent.querySelector(t)},querySelectorAll:function(t){return this._parent.querySelectorAll(t)}};function ur(t){return function(){return t}}function cr(t,e,n,r,i,o){for(var a=0,s,c=e.length,u=o.length;a<u;++a)(s=e[a])?(s.__data__=o[a],r[a]=s):n[a]=new Kt(t,o[a]);for(;a<c;++a)(s=e[a])&&(i[a]=s)}function lr(t,e,n,r,i,o,a){var s,c,u=new Map,l=e.length,y=o.length,f=new Array(l),h;for(s=0;s<l;++s)(c=e[s])&&(f[s]=h=a.call(c,c.__data__,s,e)+"",u.has(h)?i[s]=c:u.set(h,c));for(s=0;s<y;++s)h=a.call(t,o[s],s,o)+"",(c=u.get(h))?(r[s]=c,c.__data__=o[s],u.delete(h)):n[s]=new Kt(t,o[s]);for(s=0;s<l;++s)(c=e[s])&&u.get(f[s])===c&&(i[s]=c)}function fr(t){return t.__data__}function hr(t,e){if(!arguments.length)return Array.from(this,fr);var n=e?lr:cr,r=this._parents,i=this._groups;typeof t!="function"&&(t=ur(t));for(var o=i.length,a=new Array(o),s=new Array(o),c=new Array(o),u=0;u<o;++u){var l=r[u],y=i[u],f=y.length,h=dr(t.call(l,l&&l.__data__,u,r)),_=h.length,v=s[u]=new Array(_),m=a[u]=new Array(_),p=c[u]=new Array(f);n(l,y,v,m,p,h,e);for(var w=0,k=0,g,N;w<_;++w)if(g=v[w]){for(w>=k&&(k=w+1);!(N=m[k])&&++k<_;);g._next=N||null}}return a=new V(a,r),a._enter=s,a._exit=c,a}function dr(t){return typeof t=="object"&&"length"in t?t:Array.from(t)}function gr(){return new V(this._exit||this._groups.map(dn),this._parents)}function pr(t,e,n){var r=this.enter(),i=this,o=this.exit();return typeof t=="function"?(r=t(r),r&&(r=r.selection())):r=r.append(t+""),e!=null&&(i=e(i),i&&(i=i.selection())),n==null?o.remove():n(o),r&&i?r.merge(i).order():i}function yr(t){for(var e=t.selection?t.selection():t,n=this._groups,r=e._groups,i=n.length,o=r.length,a=Math.min(i,o),s=new Array(i),c=0;c<a;++c)for(var u=n[c],l=r[c],y=u.length,f=s[c]=new Array(y),h,_=0;_<y;++_)(h=u[_]||l[_])&&(f[_]=h);for(;c<i;++c)s[c]=n[c];return new V(s,this._parents)}function mr(){for(var t=this._groups,e=-1,n=t.length;++e<n;)for(var r=t[e],i=r.length-1,o=r[i],a;--i>=0;)(a=r[i])&&(o&&a.compareDocumentPosition(o)^4&&o.parentNode.insertBefore(a,o),o=a);return this}function vr(t){t||(t=xr);function e(y,f){return y&&f?t(y.__data__,f.__data__):!y-!f}for(var n=this._groups,r=n.length,i=new Array(r),o=0;o<r;++o){for(var a=n[o],s=a.length,c=i[o]=new Array(s),u,l=0;l<s;++l)(u=a[l])&&(c[l]=u);c.sort(e)}return new V(i,this._parents).order()}function xr(t,e){return t<e?-1:t>e?1:t>=e?0:NaN}function _r(){var t=arguments[0];return arguments[0]=this,t.apply(null,arguments),this}function wr(){return Array.from(this)}function br(){for(var t=this._groups,e=0,n=t.length;e<n;++e)for(var r=t[e],i=0,o=r.length;i<o;++i){var a=r[i];if(a)return a}return null}function Er(){let t=0;for(const e of this)++t;return t}function Nr(){return!this.node()}function kr(t){for(var e=this._groups,n=0,r=e.length;n<r;++n)for(var i=e[n],o=0,a=i.length,s;o<a;++o)(s=i[o])&&t.call(s,s.__data__,o,i);return this}function Mr(t){return function(){this.removeAttribute(t)}}function $r(t){return function(){this.removeAttributeNS(t.space,t.local)}}function Ar(t,e){return function(){this.setAttribute(t,e)}}function Cr(t,e){return function(){this.setAttributeNS(t.space,t.local,e)}}function Tr(t,e){return function(){var n=e.apply(this,arguments);n==null?this.removeAttribute(t):this.setAttribute(t,n)}}function Sr(t,e){return function(){var n=e.apply(this,arguments);n==null?this.removeAttributeNS(t.space,t.local):this.setAttributeNS(t.space,t.local,n)}}function zr(t,e){var n=te(t);if(arguments.length<2){var r=this.node();return n.local?r.getAttributeNS(n.space,n.local):r.getAttribute(n)}return this.each((e==null?n.local?$r:Mr:typeof e=="function"?n.local?Sr:Tr:n.local?Cr:Ar)(n,e))}function gn(t){return t.ownerDocument&&t.ownerDocument.defaultView||t.document&&t||t.defaultView}function Ir(t){return function(){this.style.removeProperty(t)}}function Dr(t,e,n){return function(){this.style.setProperty(t,e,n)}}function Rr(t,e,n){return function(){var r=e.apply(this,arguments);r==null?this.style.removeProperty(t):this.style.setProperty(t,r,n)}}function Lr(t,e,n){return arguments.length>1?this.each((e==null?Ir:typeof e=="function"?Rr:Dr)(t,e,n??"")):xt(this.node(),t)}function xt(t,e){return t.style.getPropertyValue(e)||gn(t).getComputedStyle(t,null).getPropertyValue(e)}function Br(t){return function(){delete this[t]}}function Pr(t,e){return function(){this[t]=e}}function Xr(t,e){return function(){var n=e.apply(this,arguments);n==null?delete this[t]:this[t]=n}}function Hr(t,e){return arguments.length>1?this.each((e==null?Br:typeof e=="function"?Xr:Pr)(t,e)):this.node()[t]}function pn(t){return t.trim().split(/^|\s+/)}function Ne(t){return t.classList||new yn(t)}function yn(t){this._node=t,this._names=pn(t.getAttribute("class")||"")}yn.prototype={add:function(t){var e=this._names.indexOf(t);e<0&&(this._names.push(t),this._node.setAttribute("class",this._names.join(" ")))},remove:function(t){var e=this._names.indexOf(t);e>=0&&(this._names.splice(e,1),this._node.setAttribute("class",this._names.join(" ")))},contains:function(t){return this._names.indexOf(t)>=0}};function mn(t,e){for(var n=Ne(t),r=-1,i=e.length;++r<i;)n.add(e[r])}function vn(t,e){for(var n=Ne(t),r=-1,i=e.length;++r<i;)n.remove(e[r])}function Fr(t){return function(){mn(this,t)}}function Or(t){return function(){vn(this,t)}}function Yr(t,e){return function(){(e.apply(this,arguments)?mn:vn)(this,t)}}function qr(t,e){var n=pn(t+"");if(arguments.length<2){for(var r=Ne(this.node()),i=-1,o=n.length;++i<o;)if(!r.contains(n[i]))return!1;return!0}return this.each((typeof e=="function"?Yr:e?Fr:Or)(n,e))}function Vr(){this.textContent=""}function Ur(t){return function(){this.textContent=t}}function Gr(t){return function(){var e=t.apply(this,arguments);this.textContent=e??""}}function Kr(t){return arguments.length?this.each(t==null?Vr:(typeof t=="function"?Gr:Ur)(t)):this.node().textContent}function Qr(){this.innerHTML=""}function Wr(t){return function(){this.innerHTML=t}}function Zr(t){return function(){var e=t.apply(this,arguments);this.innerHTML=e??""}}function Jr(t){return arguments.length?this.each(t==null?Qr:(typeof t=="function"?Zr:Wr)(t)):this.node().innerHTML}function jr(){this.nextSibling&&this.parentNode.appendChild(this)}function ti(){return this.each(jr)}function ei(){this.previousSibling&&this.parentNode.insertBefore(this,this.parentNode.firstChild)}function ni(){return this.each(ei)}function ri(t){var e=typeof t=="function"?t:cn(t);return this.select(function(){return this.appendChild(e.apply(this,arguments))})}function ii(){return null}function oi(t,e){var n=typeof t=="function"?t:cn(t),r=e==null?ii:typeof e=="function"?e:Ee(e);return this.select(function(){return this.insertBefore(n.apply(this,arguments),r.apply(this,arguments)||null)})}function ai(){var t=this.parentNode;t&&t.removeChild(this)}function si(){return this.each(ai)}function ui(){var t=this.cloneNode(!1),e=this.parentNode;return e?e.insertBefore(t,this.nextSibling):t}function ci(){var t=this.cloneNode(!0),e=this.parentNode;return e?e.insertBefore(t,this.nextSibling):t}function li(t){return this.select(t?ci:ui)}function fi(t){return arguments.length?this.property("__data__",t):this.node().__data__}function hi(t){return function(e){t.call(this,e,this.__data__)}}function di(t){return t.trim().split(/^|\s+/).map(function(e){var n="",r=e.indexOf(".");return r>=0&&(n=e.slice(r+1),e=e.slice(0,r)),{type:e,name:n}})}function gi(t){return function(){var e=this.__on;if(e){for(var n=0,r=-1,i=e.length,o;n<i;++n)o=e[n],(!t.type||o.type===t.type)&&o.name===t.name?this.removeEventListener(o.type,o.listener,o.options):e[++r]=o;++r?e.length=r:delete this.__on}}}function pi(t,e,n){return function(){var r=this.__on,i,o=hi(e);if(r){for(var a=0,s=r.length;a<s;++a)if((i=r[a]).type===t.type&&i.name===t.name){this.removeEventListener(i.type,i.listener,i.options),this.addEventListener(i.type,i.listener=o,i.options=n),i.value=e;return}}this.addEventListener(t.type,o,n),i={type:t.type,name:t.name,value:e,listener:o,options:n},r?r.push(i):this.__on=[i]}}function yi(t,e,n){var r=di(t+""),i,o=r.length,a;if(arguments.length<2){var s=this.node().__on;if(s){for(var c=0,u=s.length,l;c<u;++c)for(i=0,l=s[c];i<o;++i)if((a=r[i]).type===l.type&&a.name===l.name)return l.value}return}for(s=e?pi:gi,i=0;i<o;++i)this.each(s(r[i],e,n));return this}function xn(t,e,n){var r=gn(t),i=r.CustomEvent;typeof i=="function"?i=new i(e,n):(i=r.document.createEvent("Event"),n?(i.initEvent(e,n.bubbles,n.cancelable),i.detail=n.detail):i.initEvent(e,!1,!1)),t.dispatchEvent(i)}function mi(t,e){return function(){return xn(this,t,e)}}function vi(t,e){return function(){return xn(this,t,e.apply(this,arguments))}}function xi(t,e){return this.each((typeof e=="function"?vi:mi)(t,e))}function*_i(){for(var t=this._groups,e=0,n=t.length;e<n;++e)for(var r=t[e],i=0,o=r.length,a;i<o;++i)(a=r[i])&&(yield a)}var _n=[null];function V(t,e){this._groups=t,this._parents=e}function It(){return new V([[document.documentElement]],_n)}function wi(){return this}V.prototype=It.prototype={constructor:V,select:Gn,selectAll:Zn,selectChild:er,selectChildren:or,filter:ar,data:hr,enter:sr,exit:gr,join:pr,merge:yr,selection:wi,order:mr,sort:vr,call:_r,nodes:wr,node:br,size:Er,empty:Nr,each:kr,attr:zr,style:Lr,property:Hr,classed:qr,text:Kr,html:Jr,raise:ti,lower:ni,append:ri,insert:oi,remove:si,clone:li,datum:fi,on:yi,dispatch:xi,[Symbol.iterator]:_i};function O(t){return typeof t=="string"?new V([[document.querySelector(t)]],[document.documentElement]):new V([[t]],_n)}function bi(t){let e;for(;e=t.sourceEvent;)t=e;return t}function ot(t,e){if(t=bi(t),e===void 0&&(e=t.currentTarget),e){var n=e.ownerSVGElement||e;if(n.createSVGPoint){var r=n.createSVGPoint();return r.x=t.clientX,r.y=t.clientY,r=r.matrixTransform(e.getScreenCTM().inverse()),[r.x,r.y]}if(e.getBoundingClientRect){var i=e.getBoundingClientRect();return[t.clientX-i.left-e.clientLeft,t.clientY-i.top-e.clientTop]}}return[t.pageX,t.pageY]}const Ei={passive:!1},Mt={capture:!0,passive:!1};function ae(t){t.stopImmediatePropagation()}function mt(t){t.preventDefault(),t.stopImmediatePropagation()}function wn(t){var e=t.document.documentElement,n=O(t).on("dragstart.drag",mt,Mt);"onselectstart"in e?n.on("selectstart.drag",mt,Mt):(e.__noselect=e.style.MozUserSelect,e.style.MozUserSelect="none")}function bn(t,e){var n=t.document.documentElement,r=O(t).on("dragstart.drag",null);e&&(r.on("click.drag",mt,Mt),setTimeout(function(){r.on("click.drag",null)},0)),"onselectstart"in n?r.on("selectstart.drag",null):(n.style.MozUserSelect=n.__noselect,delete n.__noselect)}const Rt=t=>()=>t;function he(t,{sourceEvent:e,subject:n,target:r,identifier:i,active:o,x:a,y:s,dx:c,dy:u,dispatch:l}){Object.defineProperties(this,{type:{value:t,enumerable:!0,configurable:!0},sourceEvent:{value:e,enumerable:!0,configurable:!0},subject:{value:n,enumerable:!0,configurable:!0},target:{value:r,enumerable:!0,configurable:!0},identifier:{value:i,enumerable:!0,configurable:!0},active:{value:o,enumerable:!0,configurable:!0},x:{value:a,enumerable:!0,configurable:!0},y:{value:s,enumerable:!0,configurable:!0},dx:{value:c,enumerable:!0,configurable:!0},dy:{value:u,enumerable:!0,configurable:!0},_:{value:l}})}he.prototype.on=function(){var t=this._.on.apply(this._,arguments);return t===this._?this:t};function Ni(t){return!t.ctrlKey&&!t.button}function ki(){return this.parentNode}function Mi(t,e){return e??{x:t.x,y:t.y}}function $i(){return navigator.maxTouchPoints||"ontouchstart"in this}function Ai(){var t=Ni,e=ki,n=Mi,r=$i,i={},o=zt("start","drag","end"),a=0,s,c,u,l,y=0;function f(g){g.on("mousedown.drag",h).filter(r).on("touchstart.drag",m).on("touchmove.drag",p,Ei).on("touchend.drag touchcancel.drag",w).style("touch-action","none").style("-webkit-tap-highlight-color","rgba(0,0,0,0)")}function h(g,N){if(!(l||!t.call(this,g,N))){var M=k(this,e.call(this,g,N),g,N,"mouse");M&&(O(g.view).on("mousemove.drag",_,Mt).on("mouseup.drag",v,Mt),wn(g.view),ae(g),u=!1,s=g.clientX,c=g.clientY,M("start",g))}}function _(g){if(mt(g),!u){var N=g.clientX-s,M=g.clientY-c;u=N*N+M*M>y}i.mouse("drag",g)}function v(g){O(g.view).on("mousemove.drag mouseup.drag",null),bn(g.view,u),mt(g),i.mouse("end",g)}function m(g,N){if(t.call(this,g,N)){var M=g.changedTouches,A=e.call(this,g,N),S=M.length,D,z;for(D=0;D<S;++D)(z=k(this,A,g,N,M[D].identifier,M[D]))&&(ae(g),z("start",g,M[D]))}}function p(g){var N=g.changedTouches,M=N.length,A,S;for(A=0;A<M;++A)(S=i[N[A].identifier])&&(mt(g),S("drag",g,N[A]))}function w(g){var N=g.changedTouches,M=N.length,A,S;for(l&&clearTimeout(l),l=setTimeout(function(){l=null},500),A=0;A<M;++A)(S=i[N[A].identifier])&&(ae(g),S("end",g,N[A]))}function k(g,N,M,A,S,D){var z=o.copy(),R=ot(D||M,N),q,F,d;if((d=n.call(g,new he("beforestart",{sourceEvent:M,target:f,identifier:S,active:a,x:R[0],y:R[1],dx:0,dy:0,dispatch:z}),A))!=null)return q=d.x-R[0]||0,F=d.y-R[1]||0,function b(x,E,$){var C=R,T;switch(x){case"start":i[S]=b,T=a++;break;case"end":delete i[S],--a;case"drag":R=ot($||E,N),T=a;break}z.call(x,g,new he(x,{sourceEvent:E,subject:d,target:f,identifier:S,active:T,x:R[0]+q,y:R[1]+F,dx:R[0]-C[0],dy:R[1]-C[1],dispatch:z}),A)}}return f.filter=function(g){return arguments.length?(t=typeof g=="function"?g:Rt(!!g),f):t},f.container=function(g){return arguments.length?(e=typeof g=="function"?g:Rt(g),f):e},f.subject=function(g){return arguments.length?(n=typeof g=="function"?g:Rt(g),f):n},f.touchable=function(g){return arguments.length?(r=typeof g=="function"?g:Rt(!!g),f):r},f.on=function(){var g=o.on.apply(o,arguments);return g===o?f:g},f.clickDistance=function(g){return arguments.length?(y=(g=+g)*g,f):Math.sqrt(y)},f}function ke(t,e,n){t.prototype=e.prototype=n,n.constructor=t}function En(t,e){var n=Object.create(t.prototype);for(var r in e)n[r]=e[r];return n}function Dt(){}var $t=.7,Qt=1/$t,vt="\\s*([+-]?\\d+)\\s*",At="\\s*([+-]?(?:\\d*\\.)?\\d+(?:[eE][+-]?\\d+)?)\\s*",tt="\\s*([+-]?(?:\\d*\\.)?\\d+(?:[eE][+-]?\\d+)?)%\\s*",Ci=/^#([0-9a-f]{3,8})$/,Ti=new RegExp(`^rgb\\(${vt},${vt},${vt}\\)$`),Si=new RegExp(`^rgb\\(${tt},${tt},${tt}\\)$`),zi=new RegExp(`^rgba\\(${vt},${vt},${vt},${At}\\)$`),Ii=new RegExp(`^rgba\\(${tt},${tt},${tt},${At}\\)$`),Di=new RegExp(`^hsl\\(${At},${tt},${tt}\\)$`),Ri=new RegExp(`^hsla\\(${At},${tt},${tt},${At}\\)$`),Le={aliceblue:15792383,antiquewhite:16444375,aqua:65535,aquamarine:8388564,azure:15794175,beige:16119260,bisque:16770244,black:0,blanchedalmond:16772045,blue:255,blueviolet:9055202,brown:10824234,burlywood:14596231,cadetblue:6266528,chartreuse:8388352,chocolate:13789470,coral:16744272,cornflowerblue:6591981,cornsilk:16775388,crimson:14423100,cyan:65535,darkblue:139,darkcyan:35723,darkgoldenrod:12092939,darkgray:11119017,darkgreen:25600,darkgrey:11119017,darkkhaki:12433259,darkmagenta:9109643,darkolivegreen:5597999,darkorange:16747520,darkorchid:10040012,darkred:9109504,darksalmon:15308410,darkseagreen:9419919,darkslateblue:4734347,darkslategray:3100495,darkslategrey:3100495,darkturquoise:52945,darkviolet:9699539,deeppink:16716947,deepskyblue:49151,dimgray:6908265,dimgrey:6908265,dodgerblue:2003199,firebrick:11674146,floralwhite:16775920,forestgreen:2263842,fuchsia:16711935,gainsboro:14474460,ghostwhite:16316671,gold:16766720,goldenrod:14329120,gray:8421504,green:32768,greenyellow:11403055,grey:8421504,honeydew:15794160,hotpink:16738740,indianred:13458524,indigo:4915330,ivory:16777200,khaki:15787660,lavender:15132410,lavenderblush:16773365,lawngreen:8190976,lemonchiffon:16775885,lightblue:11393254,lightcoral:15761536,lightcyan:14745599,lightgoldenrodyellow:16448210,lightgray:13882323,lightgreen:9498256,lightgrey:13882323,lightpink:16758465,lightsalmon:16752762,lightseagreen:2142890,lightskyblue:8900346,lightslategray:7833753,lightslategrey:7833753,lightsteelblue:11584734,lightyellow:16777184,lime:65280,limegreen:3329330,linen:16445670,magenta:16711935,maroon:8388608,mediumaquamarine:6737322,mediumblue:205,mediumorchid:12211667,mediumpurple:9662683,mediumseagreen:3978097,mediumslateblue:8087790,mediumspringgreen:64154,mediumturquoise:4772300,mediumvioletred:13047173,midnightblue:1644912,mintcream:16121850,mistyrose:16770273,moccasin:16770229,navajowhite:16768685,navy:128,oldlace:16643558,olive:8421376,olivedrab:7048739,orange:16753920,orangered:16729344,orchid:14315734,palegoldenrod:15657130,palegreen:10025880,paleturquoise:11529966,palevioletred:14381203,papayawhip:16773077,peachpuff:16767673,peru:13468991,pink:16761035,plum:14524637,powderblue:11591910,purple:8388736,rebeccapurple:6697881,red:16711680,rosybrown:12357519,royalblue:4286945,saddlebrown:9127187,salmon:16416882,sandybrown:16032864,seagreen:3050327,seashell:16774638,sienna:10506797,silver:12632256,skyblue:8900331,slateblue:6970061,slategray:7372944,slategrey:7372944,snow:16775930,springgreen:65407,steelblue:4620980,tan:13808780,teal:32896,thistle:14204888,tomato:16737095,turquoise:4251856,violet:15631086,wheat:16113331,white:16777215,whitesmoke:16119285,yellow:16776960,yellowgreen:10145074};ke(Dt,Ct,{copy(t){return Object.assign(new this.constructor,this,t)},displayable(){return this.rgb().displayable()},hex:Be,formatHex:Be,formatHex8:Li,formatHsl:Bi,formatRgb:Pe,toString:Pe});function Be(){return this.rgb().formatHex()}function Li(){return this.rgb().formatHex8()}function Bi(){return Nn(this).formatHsl()}function Pe(){return this.rgb().formatRgb()}function Ct(t){var e,n;return t=(t+"").trim().toLowerCase(),(e=Ci.exec(t))?(n=e[1].length,e=parseInt(e[1],16),n===6?Xe(e):n===3?new Y(e>>8&15|e>>4&240,e>>4&15|e&240,(e&15)<<4|e&15,1):n===8?Lt(e>>24&255,e>>16&255,e>>8&255,(e&255)/255):n===4?Lt(e>>12&15|e>>8&240,e>>8&15|e>>4&240,e>>4&15|e&240,((e&15)<<4|e&15)/255):null):(e=Ti.exec(t))?new Y(e[1],e[2],e[3],1):(e=Si.exec(t))?new Y(e[1]*255/100,e[2]*255/100,e[3]*255/100,1):(e=zi.exec(t))?Lt(e[1],e[2],e[3],e[4]):(e=Ii.exec(t))?Lt(e[1]*255/100,e[2]*255/100,e[3]*255/100,e[4]):(e=Di.exec(t))?Oe(e[1],e[2]/100,e[3]/100,1):(e=Ri.exec(t))?Oe(e[1],e[2]/100,e[3]/100,e[4]):Le.hasOwnProperty(t)?Xe(Le[t]):t==="transparent"?new Y(NaN,NaN,NaN,0):null}function Xe(t){return new Y(t>>16&255,t>>8&255,t&255,1)}function Lt(t,e,n,r){return r<=0&&(t=e=n=NaN),new Y(t,e,n,r)}function Pi(t){return t instanceof Dt||(t=Ct(t)),t?(t=t.rgb(),new Y(t.r,t.g,t.b,t.opacity)):new Y}function de(t,e,n,r){return arguments.length===1?Pi(t):new Y(t,e,n,r??1)}function Y(t,e,n,r){this.r=+t,this.g=+e,this.b=+n,this.opacity=+r}ke(Y,de,En(Dt,{brighter(t){return t=t==null?Qt:Math.pow(Qt,t),new Y(this.r*t,this.g*t,this.b*t,this.opacity)},darker(t){return t=t==null?$t:Math.pow($t,t),new Y(this.r*t,this.g*t,this.b*t,this.opacity)},rgb(){return this},clamp(){return new Y(dt(this.r),dt(this.g),dt(this.b),Wt(this.opacity))},displayable(){return-.5<=this.r&&this.r<255.5&&-.5<=this.g&&this.g<255.5&&-.5<=this.b&&this.b<255.5&&0<=this.opacity&&this.opacity<=1},hex:He,formatHex:He,formatHex8:Xi,formatRgb:Fe,toString:Fe}));function He(){return`#${ht(this.r)}${ht(this.g)}${ht(this.b)}`}function Xi(){return`#${ht(this.r)}${ht(this.g)}${ht(this.b)}${ht((isNaN(this.opacity)?1:this.opacity)*255)}`}function Fe(){const t=Wt(this.opacity);return`${t===1?"rgb(":"rgba("}${dt(this.r)}, ${dt(this.g)}, ${dt(this.b)}${t===1?")":`, ${t})`}`}function Wt(t){return isNaN(t)?1:Math.max(0,Math.min(1,t))}function dt(t){return Math.max(0,Math.min(255,Math.round(t)||0))}function ht(t){return t=dt(t),(t<16?"0":"")+t.toString(16)}function Oe(t,e,n,r){return r<=0?t=e=n=NaN:n<=0||n>=1?t=e=NaN:e<=0&&(t=NaN),new K(t,e,n,r)}function Nn(t){if(t instanceof K)return new K(t.h,t.s,t.l,t.opacity);if(t instanceof Dt||(t=Ct(t)),!t)return new K;if(t instanceof K)return t;t=t.rgb();var e=t.r/255,n=t.g/255,r=t.b/255,i=Math.min(e,n,r),o=Math.max(e,n,r),a=NaN,s=o-i,c=(o+i)/2;return s?(e===o?a=(n-r)/s+(n<r)*6:n===o?a=(r-e)/s+2:a=(e-n)/s+4,s/=c<.5?o+i:2-o-i,a*=60):s=c>0&&c<1?0:a,new K(a,s,c,t.opacity)}function Hi(t,e,n,r){return arguments.length===1?Nn(t):new K(t,e,n,r??1)}function K(t,e,n,r){this.h=+t,this.s=+e,this.l=+n,this.opacity=+r}ke(K,Hi,En(Dt,{brighter(t){return t=t==null?Qt:Math.pow(Qt,t),new K(this.h,this.s,this.l*t,this.opacity)},darker(t){return t=t==null?$t:Math.pow($t,t),new K(this.h,this.s,this.l*t,this.opacity)},rgb(){var t=this.h%360+(this.h<0)*360,e=isNaN(t)||isNaN(this.s)?0:this.s,n=this.l,r=n+(n<.5?n:1-n)*e,i=2*n-r;return new Y(se(t>=240?t-240:t+120,i,r),se(t,i,r),se(t<120?t+240:t-120,i,r),this.opacity)},clamp(){return new K(Ye(this.h),Bt(this.s),Bt(this.l),Wt(this.opacity))},displayable(){return(0<=this.s&&this.s<=1||isNaN(this.s))&&0<=this.l&&this.l<=1&&0<=this.opacity&&this.opacity<=1},formatHsl(){const t=Wt(this.opacity);return`${t===1?"hsl(":"hsla("}${Ye(this.h)}, ${Bt(this.s)*100}%, ${Bt(this.l)*100}%${t===1?")":`, ${t})`}`}}));function Ye(t){return t=(t||0)%360,t<0?t+360:t}function Bt(t){return Math.max(0,Math.min(1,t||0))}function se(t,e,n){return(t<60?e+(n-e)*t/60:t<180?n:t<240?e+(n-e)*(240-t)/60:e)*255}const kn=t=>()=>t;function Fi(t,e){return function(n){return t+n*e}}function Oi(t,e,n){return t=Math.pow(t,n),e=Math.pow(e,n)-t,n=1/n,function(r){return Math.pow(t+r*e,n)}}function Yi(t){return(t=+t)==1?Mn:function(e,n){return n-e?Oi(e,n,t):kn(isNaN(e)?n:e)}}function Mn(t,e){var n=e-t;return n?Fi(t,n):kn(isNaN(t)?e:t)}const qe=(function t(e){var n=Yi(e);function r(i,o){var a=n((i=de(i)).r,(o=de(o)).r),s=n(i.g,o.g),c=n(i.b,o.b),u=Mn(i.opacity,o.opacity);return function(l){return i.r=a(l),i.g=s(l),i.b=c(l),i.opacity=u(l),i+""}}return r.gamma=t,r})(1);function ut(t,e){return t=+t,e=+e,function(n){return t*(1-n)+e*n}}var ge=/[-+]?(?:\d+\.?\d*|\.?\d+)(?:[eE][-+]?\d+)?/g,ue=new RegExp(ge.source,"g");function qi(t){return function(){return t}}function Vi(t){return function(e){return t(e)+""}}function Ui(t,e){var n=ge.lastIndex=ue.lastIndex=0,r,i,o,a=-1,s=[],c=[];for(t=t+"",e=e+"";(r=ge.exec(t))&&(i=ue.exec(e));)(o=i.index)>n&&(o=e.slice(n,o),s[a]?s[a]+=o:s[++a]=o),(r=r[0])===(i=i[0])?s[a]?s[a]+=i:s[++a]=i:(s[++a]=null,c.push({i:a,x:ut(r,i)})),n=ue.lastIndex;return n<e.length&&(o=e.slice(n),s[a]?s[a]+=o:s[++a]=o),s.length<2?c[0]?Vi(c[0].x):qi(e):(e=c.length,function(u){for(var l=0,y;l<e;++l)s[(y=c[l]).i]=y.x(u);return s.join("")})}var Ve=180/Math.PI,pe={translateX:0,translateY:0,rotate:0,skewX:0,scaleX:1,scaleY:1};function $n(t,e,n,r,i,o){var a,s,c;return(a=Math.sqrt(t*t+e*e))&&(t/=a,e/=a),(c=t*n+e*r)&&(n-=t*c,r-=e*c),(s=Math.sqrt(n*n+r*r))&&(n/=s,r/=s,c/=s),t*r<e*n&&(t=-t,e=-e,c=-c,a=-a),{translateX:i,translateY:o,rotate:Math.atan2(e,t)*Ve,skewX:Math.atan(c)*Ve,scaleX:a,scaleY:s}}var Pt;function Gi(t){const e=new(typeof DOMMatrix=="function"?DOMMatrix:WebKitCSSMatrix)(t+"");return e.isIdentity?pe:$n(e.a,e.b,e.c,e.d,e.e,e.f)}function Ki(t){return t==null||(Pt||(Pt=document.createElementNS("http://www.w3.org/2000/svg","g")),Pt.setAttribute("transform",t),!(t=Pt.transform.baseVal.consolidate()))?pe:(t=t.matrix,$n(t.a,t.b,t.c,t.d,t.e,t.f))}function An(t,e,n,r){function i(u){return u.length?u.pop()+" ":""}function o(u,l,y,f,h,_){if(u!==y||l!==f){var v=h.push("translate(",null,e,null,n);_.push({i:v-4,x:ut(u,y)},{i:v-2,x:ut(l,f)})}else(y||f)&&h.push("translate("+y+e+f+n)}function a(u,l,y,f){u!==l?(u-l>180?l+=360:l-u>180&&(u+=360),f.push({i:y.push(i(y)+"rotate(",null,r)-2,x:ut(u,l)})):l&&y.push(i(y)+"rotate("+l+r)}function s(u,l,y,f){u!==l?f.push({i:y.push(i(y)+"skewX(",null,r)-2,x:ut(u,l)}):l&&y.push(i(y)+"skewX("+l+r)}function c(u,l,y,f,h,_){if(u!==y||l!==f){var v=h.push(i(h)+"scale(",null,",",null,")");_.push({i:v-4,x:ut(u,y)},{i:v-2,x:ut(l,f)})}else(y!==1||f!==1)&&h.push(i(h)+"scale("+y+","+f+")")}return function(u,l){var y=[],f=[];return u=t(u),l=t(l),o(u.translateX,u.translateY,l.translateX,l.translateY,y,f),a(u.rotate,l.rotate,y,f),s(u.skewX,l.skewX,y,f),c(u.scaleX,u.scaleY,l.scaleX,l.scaleY,y,f),u=l=null,function(h){for(var _=-1,v=f.length,m;++_<v;)y[(m=f[_]).i]=m.x(h);return y.join("")}}}var Qi=An(Gi,"px, ","px)","deg)"),Wi=An(Ki,", ",")",")"),Zi=1e-12;function Ue(t){return((t=Math.exp(t))+1/t)/2}function Ji(t){return((t=Math.exp(t))-1/t)/2}function ji(t){return((t=Math.exp(2*t))-1)/(t+1)}const to=(function t(e,n,r){function i(o,a){var s=o[0],c=o[1],u=o[2],l=a[0],y=a[1],f=a[2],h=l-s,_=y-c,v=h*h+_*_,m,p;if(v<Zi)p=Math.log(f/u)/e,m=function(A){return[s+A*h,c+A*_,u*Math.exp(e*A*p)]};else{var w=Math.sqrt(v),k=(f*f-u*u+r*v)/(2*u*n*w),g=(f*f-u*u-r*v)/(2*f*n*w),N=Math.log(Math.sqrt(k*k+1)-k),M=Math.log(Math.sqrt(g*g+1)-g);p=(M-N)/e,m=function(A){var S=A*p,D=Ue(N),z=u/(n*w)*(D*ji(e*S+N)-Ji(N));return[s+z*h,c+z*_,u*D/Ue(e*S+N)]}}return m.duration=p*1e3*e/Math.SQRT2,m}return i.rho=function(o){var a=Math.max(.001,+o),s=a*a,c=s*s;return t(a,s,c)},i})(Math.SQRT2,2,4);var _t=0,Nt=0,wt=0,Cn=1e3,Zt,kt,Jt=0,pt=0,ee=0,Tt=typeof performance=="object"&&performance.now?performance:Date,Tn=typeof window=="object"&&window.requestAnimationFrame?window.requestAnimationFrame.bind(window):function(t){setTimeout(t,17)};function Me(){return pt||(Tn(eo),pt=Tt.now()+ee)}function eo(){pt=0}function jt(){this._call=this._time=this._next=null}jt.prototype=ne.prototype={constructor:jt,restart:function(t,e,n){if(typeof t!="function")throw new TypeError("callback is not a function");n=(n==null?Me():+n)+(e==null?0:+e),!this._next&&kt!==this&&(kt?kt._next=this:Zt=this,kt=this),this._call=t,this._time=n,ye()},stop:function(){this._call&&(this._call=null,this._time=1/0,ye())}};function ne(t,e,n){var r=new jt;return r.restart(t,e,n),r}function no(){Me(),++_t;for(var t=Zt,e;t;)(e=pt-t._time)>=0&&t._call.call(void 0,e),t=t._next;--_t}function Ge(){pt=(Jt=Tt.now())+ee,_t=Nt=0;try{no()}finally{_t=0,io(),pt=0}}function ro(){var t=Tt.now(),e=t-Jt;e>Cn&&(ee-=e,Jt=t)}function io(){for(var t,e=Zt,n,r=1/0;e;)e._call?(r>e._time&&(r=e._time),t=e,e=e._next):(n=e._next,e._next=null,e=t?t._next=n:Zt=n);kt=t,ye(r)}function ye(t){if(!_t){Nt&&(Nt=clearTimeout(Nt));var e=t-pt;e>24?(t<1/0&&(Nt=setTimeout(Ge,t-Tt.now()-ee)),wt&&(wt=clearInterval(wt))):(wt||(Jt=Tt.now(),wt=setInterval(ro,Cn)),_t=1,Tn(Ge))}}function Ke(t,e,n){var r=new jt;return e=e==null?0:+e,r.restart(i=>{r.stop(),t(i+e)},e,n),r}var oo=zt("start","end","cancel","interrupt"),ao=[],Sn=0,Qe=1,me=2,qt=3,We=4,ve=5,Vt=6;function re(t,e,n,r,i,o){var a=t.__transition;if(!a)t.__transition={};else if(n in a)return;so(t,n,{name:e,index:r,group:i,on:oo,tween:ao,time:o.time,delay:o.delay,duration:o.duration,ease:o.ease,timer:null,state:Sn})}function $e(t,e){var n=W(t,e);if(n.state>Sn)throw new Error("too late; already scheduled");return n}function et(t,e){var n=W(t,e);if(n.state>qt)throw new Error("too late; already running");return n}function W(t,e){var n=t.__transition;if(!n||!(n=n[e]))throw new Error("transition not found");return n}function so(t,e,n){var r=t.__transition,i;r[e]=n,n.timer=ne(o,0,n.time);function o(u){n.state=Qe,n.timer.restart(a,n.delay,n.time),n.delay<=u&&a(u-n.delay)}function a(u){var l,y,f,h;if(n.state!==Qe)return c();for(l in r)if(h=r[l],h.name===n.name){if(h.state===qt)return Ke(a);h.state===We?(h.state=Vt,h.timer.stop(),h.on.call("interrupt",t,t.__data__,h.index,h.group),delete r[l]):+l<e&&(h.state=Vt,h.timer.stop(),h.on.call("cancel",t,t.__data__,h.index,h.group),delete r[l])}if(Ke(function(){n.state===qt&&(n.state=We,n.timer.restart(s,n.delay,n.time),s(u))}),n.state=me,n.on.call("start",t,t.__data__,n.index,n.group),n.state===me){for(n.state=qt,i=new Array(f=n.tween.length),l=0,y=-1;l<f;++l)(h=n.tween[l].value.call(t,t.__data__,n.index,n.group))&&(i[++y]=h);i.length=y+1}}function s(u){for(var l=u<n.duration?n.ease.call(null,u/n.duration):(n.timer.restart(c),n.state=ve,1),y=-1,f=i.length;++y<f;)i[y].call(t,l);n.state===ve&&(n.on.call("end",t,t.__data__,n.index,n.group),c())}function c(){n.state=Vt,n.timer.stop(),delete r[e];for(var u in r)return;delete t.__transition}}function Ut(t,e){var n=t.__transition,r,i,o=!0,a;if(n){e=e==null?null:e+"";for(a in n){if((r=n[a]).name!==e){o=!1;continue}i=r.state>me&&r.state<ve,r.state=Vt,r.timer.stop(),r.on.call(i?"interrupt":"cancel",t,t.__data__,r.index,r.group),delete n[a]}o&&delete t.__transition}}function uo(t){return this.each(function(){Ut(this,t)})}function co(t,e){var n,r;return function(){var i=et(this,t),o=i.tween;if(o!==n){r=n=o;for(var a=0,s=r.length;a<s;++a)if(r[a].name===e){r=r.slice(),r.splice(a,1);break}}i.tween=r}}function lo(t,e,n){var r,i;if(typeof n!="function")throw new Error;return function(){var o=et(this,t),a=o.tween;if(a!==r){i=(r=a).slice();for(var s={name:e,value:n},c=0,u=i.length;c<u;++c)if(i[c].name===e){i[c]=s;break}c===u&&i.push(s)}o.tween=i}}function fo(t,e){var n=this._id;if(t+="",arguments.length<2){for(var r=W(this.node(),n).tween,i=0,o=r.length,a;i<o;++i)if((a=r[i]).name===t)return a.value;return null}return this.each((e==null?co:lo)(n,t,e))}function Ae(t,e,n){var r=t._id;return t.each(function(){var i=et(this,r);(i.value||(i.value={}))[e]=n.apply(this,arguments)}),function(i){return W(i,r).value[e]}}function zn(t,e){var n;return(typeof e=="number"?ut:e instanceof Ct?qe:(n=Ct(e))?(e=n,qe):Ui)(t,e)}function ho(t){return function(){this.removeAttribute(t)}}function go(t){return function(){this.removeAttributeNS(t.space,t.local)}}function po(t,e,n){var r,i=n+"",o;return function(){var a=this.getAttribute(t);return a===i?null:a===r?o:o=e(r=a,n)}}function yo(t,e,n){var r,i=n+"",o;return function(){var a=this.getAttributeNS(t.space,t.local);return a===i?null:a===r?o:o=e(r=a,n)}}function mo(t,e,n){var r,i,o;return function(){var a,s=n(this),c;return s==null?void this.removeAttribute(t):(a=this.getAttribute(t),c=s+"",a===c?null:a===r&&c===i?o:(i=c,o=e(r=a,s)))}}function vo(t,e,n){var r,i,o;return function(){var a,s=n(this),c;return s==null?void this.removeAttributeNS(t.space,t.local):(a=this.getAttributeNS(t.space,t.local),c=s+"",a===c?null:a===r&&c===i?o:(i=c,o=e(r=a,s)))}}function xo(t,e){var n=te(t),r=n==="transform"?Wi:zn;return this.attrTween(t,typeof e=="function"?(n.local?vo:mo)(n,r,Ae(this,"attr."+t,e)):e==null?(n.local?go:ho)(n):(n.local?yo:po)(n,r,e))}function _o(t,e){return function(n){this.setAttribute(t,e.call(this,n))}}function wo(t,e){return function(n){this.setAttributeNS(t.space,t.local,e.call(this,n))}}function bo(t,e){var n,r;function i(){var o=e.apply(this,arguments);return o!==r&&(n=(r=o)&&wo(t,o)),n}return i._value=e,i}function Eo(t,e){var n,r;function i(){var o=e.apply(this,arguments);return o!==r&&(n=(r=o)&&_o(t,o)),n}return i._value=e,i}function No(t,e){var n="attr."+t;if(arguments.length<2)return(n=this.tween(n))&&n._value;if(e==null)return this.tween(n,null);if(typeof e!="function")throw new Error;var r=te(t);return this.tween(n,(r.local?bo:Eo)(r,e))}function ko(t,e){return function(){$e(this,t).delay=+e.apply(this,arguments)}}function Mo(t,e){return e=+e,function(){$e(this,t).delay=e}}function $o(t){var e=this._id;return arguments.length?this.each((typeof t=="function"?ko:Mo)(e,t)):W(this.node(),e).delay}function Ao(t,e){return function(){et(this,t).duration=+e.apply(this,arguments)}}function Co(t,e){return e=+e,function(){et(this,t).duration=e}}function To(t){var e=this._id;return arguments.length?this.each((typeof t=="function"?Ao:Co)(e,t)):W(this.node(),e).duration}function So(t,e){if(typeof e!="function")throw new Error;return function(){et(this,t).ease=e}}function zo(t){var e=this._id;return arguments.length?this.each(So(e,t)):W(this.node(),e).ease}function Io(t,e){return function(){var n=e.apply(this,arguments);if(typeof n!="function")throw new Error;et(this,t).ease=n}}function Do(t){if(typeof t!="function")throw new Error;return this.each(Io(this._id,t))}function Ro(t){typeof t!="function"&&(t=fn(t));for(var e=this._groups,n=e.length,r=new Array(n),i=0;i<n;++i)for(var o=e[i],a=o.length,s=r[i]=[],c,u=0;u<a;++u)(c=o[u])&&t.call(c,c.__data__,u,o)&&s.push(c);return new st(r,this._parents,this._name,this._id)}function Lo(t){if(t._id!==this._id)throw new Error;for(var e=this._groups,n=t._groups,r=e.length,i=n.length,o=Math.min(r,i),a=new Array(r),s=0;s<o;++s)for(var c=e[s],u=n[s],l=c.length,y=a[s]=new Array(l),f,h=0;h<l;++h)(f=c[h]||u[h])&&(y[h]=f);for(;s<r;++s)a[s]=e[s];return new st(a,this._parents,this._name,this._id)}function Bo(t){return(t+"").trim().split(/^|\s+/).every(function(e){var n=e.indexOf(".");return n>=0&&(e=e.slice(0,n)),!e||e==="start"})}function Po(t,e,n){var r,i,o=Bo(e)?$e:et;return function(){var a=o(this,t),s=a.on;s!==r&&(i=(r=s).copy()).on(e,n),a.on=i}}function Xo(t,e){var n=this._id;return arguments.length<2?W(this.node(),n).on.on(t):this.each(Po(n,t,e))}function Ho(t){return function(){var e=this.parentNode;for(var n in this.__transition)if(+n!==t)return;e&&e.removeChild(this)}}function Fo(){return this.on("end.remove",Ho(this._id))}function Oo(t){var e=this._name,n=this._id;typeof t!="function"&&(t=Ee(t));for(var r=this._groups,i=r.length,o=new Array(i),a=0;a<i;++a)for(var s=r[a],c=s.length,u=o[a]=new Array(c),l,y,f=0;f<c;++f)(l=s[f])&&(y=t.call(l,l.__data__,f,s))&&("__data__"in l&&(y.__data__=l.__data__),u[f]=y,re(u[f],e,n,f,u,W(l,n)));return new st(o,this._parents,e,n)}function Yo(t){var e=this._name,n=this._id;typeof t!="function"&&(t=ln(t));for(var r=this._groups,i=r.length,o=[],a=[],s=0;s<i;++s)for(var c=r[s],u=c.length,l,y=0;y<u;++y)if(l=c[y]){for(var f=t.call(l,l.__data__,y,c),h,_=W(l,n),v=0,m=f.length;v<m;++v)(h=f[v])&&re(h,e,n,v,f,_);o.push(f),a.push(l)}return new st(o,a,e,n)}var qo=It.prototype.constructor;function Vo(){return new qo(this._groups,this._parents)}function Uo(t,e){var n,r,i;return function(){var o=xt(this,t),a=(this.style.removeProperty(t),xt(this,t));return o===a?null:o===n&&a===r?i:i=e(n=o,r=a)}}function In(t){return function(){this.style.removeProperty(t)}}function Go(t,e,n){var r,i=n+"",o;return function(){var a=xt(this,t);return a===i?null:a===r?o:o=e(r=a,n)}}function Ko(t,e,n){var r,i,o;return function(){var a=xt(this,t),s=n(this),c=s+"";return s==null&&(c=s=(this.style.removeProperty(t),xt(this,t))),a===c?null:a===r&&c===i?o:(i=c,o=e(r=a,s))}}function Qo(t,e){var n,r,i,o="style."+e,a="end."+o,s;return function(){var c=et(this,t),u=c.on,l=c.value[o]==null?s||(s=In(e)):void 0;(u!==n||i!==l)&&(r=(n=u).copy()).on(a,i=l),c.on=r}}function Wo(t,e,n){var r=(t+="")=="transform"?Qi:zn;return e==null?this.styleTween(t,Uo(t,r)).on("end.style."+t,In(t)):typeof e=="function"?this.styleTween(t,Ko(t,r,Ae(this,"style."+t,e))).each(Qo(this._id,t)):this.styleTween(t,Go(t,r,e),n).on("end.style."+t,null)}function Zo(t,e,n){return function(r){this.style.setProperty(t,e.call(this,r),n)}}function Jo(t,e,n){var r,i;function o(){var a=e.apply(this,arguments);return a!==i&&(r=(i=a)&&Zo(t,a,n)),r}return o._value=e,o}function jo(t,e,n){var r="style."+(t+="");if(arguments.length<2)return(r=this.tween(r))&&r._value;if(e==null)return this.tween(r,null);if(typeof e!="function")throw new Error;return this.tween(r,Jo(t,e,n??""))}function ta(t){return function(){this.textContent=t}}function ea(t){return function(){var e=t(this);this.textContent=e??""}}function na(t){return this.tween("text",typeof t=="function"?ea(Ae(this,"text",t)):ta(t==null?"":t+""))}function ra(t){return function(e){this.textContent=t.call(this,e)}}function ia(t){var e,n;function r(){var i=t.apply(this,arguments);return i!==n&&(e=(n=i)&&ra(i)),e}return r._value=t,r}function oa(t){var e="text";if(arguments.length<1)return(e=this.tween(e))&&e._value;if(t==null)return this.tween(e,null);if(typeof t!="function")throw new Error;return this.tween(e,ia(t))}function aa(){for(var t=this._name,e=this._id,n=Dn(),r=this._groups,i=r.length,o=0;o<i;++o)for(var a=r[o],s=a.length,c,u=0;u<s;++u)if(c=a[u]){var l=W(c,e);re(c,t,n,u,a,{time:l.time+l.delay+l.duration,delay:0,duration:l.duration,ease:l.ease})}return new st(r,this._parents,t,n)}function sa(){var t,e,n=this,r=n._id,i=n.size();return new Promise(function(o,a){var s={value:a},c={value:function(){--i===0&&o()}};n.each(function(){var u=et(this,r),l=u.on;l!==t&&(e=(t=l).copy(),e._.cancel.push(s),e._.interrupt.push(s),e._.end.push(c)),u.on=e}),i===0&&o()})}var ua=0;function st(t,e,n,r){this._groups=t,this._parents=e,this._name=n,this._id=r}function Dn(){return++ua}var it=It.prototype;st.prototype={constructor:st,select:Oo,selectAll:Yo,selectChild:it.selectChild,selectChildren:it.selectChildren,filter:Ro,merge:Lo,selection:Vo,transition:aa,call:it.call,nodes:it.nodes,node:it.node,size:it.size,empty:it.empty,each:it.each,on:Xo,attr:xo,attrTween:No,style:Wo,styleTween:jo,text:na,textTween:oa,remove:Fo,tween:fo,delay:$o,duration:To,ease:zo,easeVarying:Do,end:sa,[Symbol.iterator]:it[Symbol.iterator]};function ca(t){return((t*=2)<=1?t*t*t:(t-=2)*t*t+2)/2}var la={time:null,delay:0,duration:250,ease:ca};function fa(t,e){for(var n;!(n=t.__transition)||!(n=n[e]);)if(!(t=t.parentNode))throw new Error(`transition ${e} not found`);return n}function ha(t){var e,n;t instanceof st?(e=t._id,t=t._name):(e=Dn(),(n=la).time=Me(),t=t==null?null:t+"");for(var r=this._groups,i=r.length,o=0;o<i;++o)for(var a=r[o],s=a.length,c,u=0;u<s;++u)(c=a[u])&&re(c,t,e,u,a,n||fa(c,e));return new st(r,this._parents,t,e)}It.prototype.interrupt=uo;It.prototype.transition=ha;function Ze(t,e){var n,r=1;t==null&&(t=0),e==null&&(e=0);function i(){var o,a=n.length,s,c=0,u=0;for(o=0;o<a;++o)s=n[o],c+=s.x,u+=s.y;for(c=(c/a-t)*r,u=(u/a-e)*r,o=0;o<a;++o)s=n[o],s.x-=c,s.y-=u}return i.initialize=function(o){n=o},i.x=function(o){return arguments.length?(t=+o,i):t},i.y=function(o){return arguments.length?(e=+o,i):e},i.strength=function(o){return arguments.length?(r=+o,i):r},i}function da(t){const e=+this._x.call(null,t),n=+this._y.call(null,t);return Rn(this.cover(e,n),e,n,t)}function Rn(t,e,n,r){if(isNaN(e)||isNaN(n))return t;var i,o=t._root,a={data:r},s=t._x0,c=t._y0,u=t._x1,l=t._y1,y,f,h,_,v,m,p,w;if(!o)return t._root=a,t;for(;o.length;)if((v=e>=(y=(s+u)/2))?s=y:u=y,(m=n>=(f=(c+l)/2))?c=f:l=f,i=o,!(o=o[p=m<<1|v]))return i[p]=a,t;if(h=+t._x.call(null,o.data),_=+t._y.call(null,o.data),e===h&&n===_)return a.next=o,i?i[p]=a:t._root=a,t;do i=i?i[p]=new Array(4):t._root=new Array(4),(v=e>=(y=(s+u)/2))?s=y:u=y,(m=n>=(f=(c+l)/2))?c=f:l=f;while((p=m<<1|v)===(w=(_>=f)<<1|h>=y));return i[w]=o,i[p]=a,t}function ga(t){var e,n,r=t.length,i,o,a=new Array(r),s=new Array(r),c=1/0,u=1/0,l=-1/0,y=-1/0;for(n=0;n<r;++n)isNaN(i=+this._x.call(null,e=t[n]))||isNaN(o=+this._y.call(null,e))||(a[n]=i,s[n]=o,i<c&&(c=i),i>l&&(l=i),o<u&&(u=o),o>y&&(y=o));if(c>l||u>y)return this;for(this.cover(c,u).cover(l,y),n=0;n<r;++n)Rn(this,a[n],s[n],t[n]);return this}function pa(t,e){if(isNaN(t=+t)||isNaN(e=+e))return this;var n=this._x0,r=this._y0,i=this._x1,o=this._y1;if(isNaN(n))i=(n=Math.floor(t))+1,o=(r=Math.floor(e))+1;else{for(var a=i-n||1,s=this._root,c,u;n>t||t>=i||r>e||e>=o;)switch(u=(e<r)<<1|t<n,c=new Array(4),c[u]=s,s=c,a*=2,u){case 0:i=n+a,o=r+a;break;case 1:n=i-a,o=r+a;break;case 2:i=n+a,r=o-a;break;case 3:n=i-a,r=o-a;break}this._root&&this._root.length&&(this._root=s)}return this._x0=n,this._y0=r,this._x1=i,this._y1=o,this}function ya(){var t=[];return this.visit(function(e){if(!e.length)do t.push(e.data);while(e=e.next)}),t}function ma(t){return arguments.length?this.cover(+t[0][0],+t[0][1]).cover(+t[1][0],+t[1][1]):isNaN(this._x0)?void 0:[[this._x0,this._y0],[this._x1,this._y1]]}function X(t,e,n,r,i){this.node=t,this.x0=e,this.y0=n,this.x1=r,this.y1=i}function va(t,e,n){var r,i=this._x0,o=this._y0,a,s,c,u,l=this._x1,y=this._y1,f=[],h=this._root,_,v;for(h&&f.push(new X(h,i,o,l,y)),n==null?n=1/0:(i=t-n,o=e-n,l=t+n,y=e+n,n*=n);_=f.pop();)if(!(!(h=_.node)||(a=_.x0)>l||(s=_.y0)>y||(c=_.x1)<i||(u=_.y1)<o))if(h.length){var m=(a+c)/2,p=(s+u)/2;f.push(new X(h[3],m,p,c,u),new X(h[2],a,p,m,u),new X(h[1],m,s,c,p),new X(h[0],a,s,m,p)),(v=(e>=p)<<1|t>=m)&&(_=f[f.length-1],f[f.length-1]=f[f.length-1-v],f[f.length-1-v]=_)}else{var w=t-+this._x.call(null,h.data),k=e-+this._y.call(null,h.data),g=w*w+k*k;if(g<n){var N=Math.sqrt(n=g);i=t-N,o=e-N,l=t+N,y=e+N,r=h.data}}return r}function xa(t){if(isNaN(l=+this._x.call(null,t))||isNaN(y=+this._y.call(null,t)))return this;var e,n=this._root,r,i,o,a=this._x0,s=this._y0,c=this._x1,u=this._y1,l,y,f,h,_,v,m,p;if(!n)return this;if(n.length)for(;;){if((_=l>=(f=(a+c)/2))?a=f:c=f,(v=y>=(h=(s+u)/2))?s=h:u=h,e=n,!(n=n[m=v<<1|_]))return this;if(!n.length)break;(e[m+1&3]||e[m+2&3]||e[m+3&3])&&(r=e,p=m)}for(;n.data!==t;)if(i=n,!(n=n.next))return this;return(o=n.next)&&delete n.next,i?(o?i.next=o:delete i.next,this):e?(o?e[m]=o:delete e[m],(n=e[0]||e[1]||e[2]||e[3])&&n===(e[3]||e[2]||e[1]||e[0])&&!n.length&&(r?r[p]=n:this._root=n),this):(this._root=o,this)}function _a(t){for(var e=0,n=t.length;e<n;++e)this.remove(t[e]);return this}function wa(){return this._root}function ba(){var t=0;return this.visit(function(e){if(!e.length)do++t;while(e=e.next)}),t}function Ea(t){var e=[],n,r=this._root,i,o,a,s,c;for(r&&e.push(new X(r,this._x0,this._y0,this._x1,this._y1));n=e.pop();)if(!t(r=n.node,o=n.x0,a=n.y0,s=n.x1,c=n.y1)&&r.length){var u=(o+s)/2,l=(a+c)/2;(i=r[3])&&e.push(new X(i,u,l,s,c)),(i=r[2])&&e.push(new X(i,o,l,u,c)),(i=r[1])&&e.push(new X(i,u,a,s,l)),(i=r[0])&&e.push(new X(i,o,a,u,l))}return this}function Na(t){var e=[],n=[],r;for(this._root&&e.push(new X(this._root,this._x0,this._y0,this._x1,this._y1));r=e.pop();){var i=r.node;if(i.length){var o,a=r.x0,s=r.y0,c=r.x1,u=r.y1,l=(a+c)/2,y=(s+u)/2;(o=i[0])&&e.push(new X(o,a,s,l,y)),(o=i[1])&&e.push(new X(o,l,s,c,y)),(o=i[2])&&e.push(new X(o,a,y,l,u)),(o=i[3])&&e.push(new X(o,l,y,c,u))}n.push(r)}for(;r=n.pop();)t(r.node,r.x0,r.y0,r.x1,r.y1);return this}function ka(t){return t[0]}function Ma(t){return arguments.length?(this._x=t,this):this._x}function $a(t){return t[1]}function Aa(t){return arguments.length?(this._y=t,this):this._y}function Ce(t,e,n){var r=new Te(e??ka,n??$a,NaN,NaN,NaN,NaN);return t==null?r:r.addAll(t)}function Te(t,e,n,r,i,o){this._x=t,this._y=e,this._x0=n,this._y0=r,this._x1=i,this._y1=o,this._root=void 0}function Je(t){for(var e={data:t.data},n=e;t=t.next;)n=n.next={data:t.data};return e}var H=Ce.prototype=Te.prototype;H.copy=function(){var t=new Te(this._x,this._y,this._x0,this._y0,this._x1,this._y1),e=this._root,n,r;if(!e)return t;if(!e.length)return t._root=Je(e),t;for(n=[{source:e,target:t._root=new Array(4)}];e=n.pop();)for(var i=0;i<4;++i)(r=e.source[i])&&(r.length?n.push({source:r,target:e.target[i]=new Array(4)}):e.target[i]=Je(r));return t};H.add=da;H.addAll=ga;H.cover=pa;H.data=ya;H.extent=ma;H.find=va;H.remove=xa;H.removeAll=_a;H.root=wa;H.size=ba;H.visit=Ea;H.visitAfter=Na;H.x=Ma;H.y=Aa;function gt(t){return function(){return t}}function ct(t){return(t()-.5)*1e-6}function Ca(t){return t.x+t.vx}function Ta(t){return t.y+t.vy}function Sa(t){var e,n,r,i=1,o=1;typeof t!="function"&&(t=gt(t==null?1:+t));function a(){for(var u,l=e.length,y,f,h,_,v,m,p=0;p<o;++p)for(y=Ce(e,Ca,Ta).visitAfter(s),u=0;u<l;++u)f=e[u],v=n[f.index],m=v*v,h=f.x+f.vx,_=f.y+f.vy,y.visit(w);function w(k,g,N,M,A){var S=k.data,D=k.r,z=v+D;if(S){if(S.index>f.index){var R=h-S.x-S.vx,q=_-S.y-S.vy,F=R*R+q*q;F<z*z&&(R===0&&(R=ct(r),F+=R*R),q===0&&(q=ct(r),F+=q*q),F=(z-(F=Math.sqrt(F)))/F*i,f.vx+=(R*=F)*(z=(D*=D)/(m+D)),f.vy+=(q*=F)*z,S.vx-=R*(z=1-z),S.vy-=q*z)}return}return g>h+z||M<h-z||N>_+z||A<_-z}}function s(u){if(u.data)return u.r=n[u.data.index];for(var l=u.r=0;l<4;++l)u[l]&&u[l].r>u.r&&(u.r=u[l].r)}function c(){if(e){var u,l=e.length,y;for(n=new Array(l),u=0;u<l;++u)y=e[u],n[y.index]=+t(y,u,e)}}return a.initialize=function(u,l){e=u,r=l,c()},a.iterations=function(u){return arguments.length?(o=+u,a):o},a.strength=function(u){return arguments.length?(i=+u,a):i},a.radius=function(u){return arguments.length?(t=typeof u=="function"?u:gt(+u),c(),a):t},a}function za(t){return t.index}function je(t,e){var n=t.get(e);if(!n)throw new Error("node not found: "+e);return n}function Ia(t){var e=za,n=y,r,i=gt(30),o,a,s,c,u,l=1;t==null&&(t=[]);function y(m){return 1/Math.min(s[m.source.index],s[m.target.index])}function f(m){for(var p=0,w=t.length;p<l;++p)for(var k=0,g,N,M,A,S,D,z;k<w;++k)g=t[k],N=g.source,M=g.target,A=M.x+M.vx-N.x-N.vx||ct(u),S=M.y+M.vy-N.y-N.vy||ct(u),D=Math.sqrt(A*A+S*S),D=(D-o[k])/D*m*r[k],A*=D,S*=D,M.vx-=A*(z=c[k]),M.vy-=S*z,N.vx+=A*(z=1-z),N.vy+=S*z}function h(){if(a){var m,p=a.length,w=t.length,k=new Map(a.map((N,M)=>[e(N,M,a),N])),g;for(m=0,s=new Array(p);m<w;++m)g=t[m],g.index=m,typeof g.source!="object"&&(g.source=je(k,g.source)),typeof g.target!="object"&&(g.target=je(k,g.target)),s[g.source.index]=(s[g.source.index]||0)+1,s[g.target.index]=(s[g.target.index]||0)+1;for(m=0,c=new Array(w);m<w;++m)g=t[m],c[m]=s[g.source.index]/(s[g.source.index]+s[g.target.index]);r=new Array(w),_(),o=new Array(w),v()}}function _(){if(a)for(var m=0,p=t.length;m<p;++m)r[m]=+n(t[m],m,t)}function v(){if(a)for(var m=0,p=t.length;m<p;++m)o[m]=+i(t[m],m,t)}return f.initialize=function(m,p){a=m,u=p,h()},f.links=function(m){return arguments.length?(t=m,h(),f):t},f.id=function(m){return arguments.length?(e=m,f):e},f.iterations=function(m){return arguments.length?(l=+m,f):l},f.strength=function(m){return arguments.length?(n=typeof m=="function"?m:gt(+m),_(),f):n},f.distance=function(m){return arguments.length?(i=typeof m=="function"?m:gt(+m),v(),f):i},f}const Da=1664525,Ra=1013904223,tn=4294967296;function La(){let t=1;return()=>(t=(Da*t+Ra)%tn)/tn}function Ba(t){return t.x}function Pa(t){return t.y}var Xa=10,Ha=Math.PI*(3-Math.sqrt(5));function Fa(t){var e,n=1,r=.001,i=1-Math.pow(r,1/300),o=0,a=.6,s=new Map,c=ne(y),u=zt("tick","end"),l=La();t==null&&(t=[]);function y(){f(),u.call("tick",e),n<r&&(c.stop(),u.call("end",e))}function f(v){var m,p=t.length,w;v===void 0&&(v=1);for(var k=0;k<v;++k)for(n+=(o-n)*i,s.forEach(function(g){g(n)}),m=0;m<p;++m)w=t[m],w.fx==null?w.x+=w.vx*=a:(w.x=w.fx,w.vx=0),w.fy==null?w.y+=w.vy*=a:(w.y=w.fy,w.vy=0);return e}function h(){for(var v=0,m=t.length,p;v<m;++v){if(p=t[v],p.index=v,p.fx!=null&&(p.x=p.fx),p.fy!=null&&(p.y=p.fy),isNaN(p.x)||isNaN(p.y)){var w=Xa*Math.sqrt(.5+v),k=v*Ha;p.x=w*Math.cos(k),p.y=w*Math.sin(k)}(isNaN(p.vx)||isNaN(p.vy))&&(p.vx=p.vy=0)}}function _(v){return v.initialize&&v.initialize(t,l),v}return h(),e={tick:f,restart:function(){return c.restart(y),e},stop:function(){return c.stop(),e},nodes:function(v){return arguments.length?(t=v,h(),s.forEach(_),e):t},alpha:function(v){return arguments.length?(n=+v,e):n},alphaMin:function(v){return arguments.length?(r=+v,e):r},alphaDecay:function(v){return arguments.length?(i=+v,e):+i},alphaTarget:function(v){return arguments.length?(o=+v,e):o},velocityDecay:function(v){return arguments.length?(a=1-v,e):1-a},randomSource:function(v){return arguments.length?(l=v,s.forEach(_),e):l},force:function(v,m){return arguments.length>1?(m==null?s.delete(v):s.set(v,_(m)),e):s.get(v)},find:function(v,m,p){var w=0,k=t.length,g,N,M,A,S;for(p==null?p=1/0:p*=p,w=0;w<k;++w)A=t[w],g=v-A.x,N=m-A.y,M=g*g+N*N,M<p&&(S=A,p=M);return S},on:function(v,m){return arguments.length>1?(u.on(v,m),e):u.on(v)}}}function Oa(){var t,e,n,r,i=gt(-30),o,a=1,s=1/0,c=.81;function u(h){var _,v=t.length,m=Ce(t,Ba,Pa).visitAfter(y);for(r=h,_=0;_<v;++_)e=t[_],m.visit(f)}function l(){if(t){var h,_=t.length,v;for(o=new Array(_),h=0;h<_;++h)v=t[h],o[v.index]=+i(v,h,t)}}function y(h){var _=0,v,m,p=0,w,k,g;if(h.length){for(w=k=g=0;g<4;++g)(v=h[g])&&(m=Math.abs(v.value))&&(_+=v.value,p+=m,w+=m*v.x,k+=m*v.y);h.x=w/p,h.y=k/p}else{v=h,v.x=v.data.x,v.y=v.data.y;do _+=o[v.data.index];while(v=v.next)}h.value=_}function f(h,_,v,m){if(!h.value)return!0;var p=h.x-e.x,w=h.y-e.y,k=m-_,g=p*p+w*w;if(k*k/c<g)return g<s&&(p===0&&(p=ct(n),g+=p*p),w===0&&(w=ct(n),g+=w*w),g<a&&(g=Math.sqrt(a*g)),e.vx+=p*h.value*r/g,e.vy+=w*h.value*r/g),!0;if(h.length||g>=s)return;(h.data!==e||h.next)&&(p===0&&(p=ct(n),g+=p*p),w===0&&(w=ct(n),g+=w*w),g<a&&(g=Math.sqrt(a*g)));do h.data!==e&&(k=o[h.data.index]*r/g,e.vx+=p*k,e.vy+=w*k);while(h=h.next)}return u.initialize=function(h,_){t=h,n=_,l()},u.strength=function(h){return arguments.length?(i=typeof h=="function"?h:gt(+h),l(),u):i},u.distanceMin=function(h){return arguments.length?(a=h*h,u):Math.sqrt(a)},u.distanceMax=function(h){return arguments.length?(s=h*h,u):Math.sqrt(s)},u.theta=function(h){return arguments.length?(c=h*h,u):Math.sqrt(c)},u}const Xt=t=>()=>t;function Ya(t,{sourceEvent:e,target:n,transform:r,dispatch:i}){Object.defineProperties(this,{type:{value:t,enumerable:!0,configurable:!0},sourceEvent:{value:e,enumerable:!0,configurable:!0},target:{value:n,enumerable:!0,configurable:!0},transform:{value:r,enumerable:!0,configurable:!0},_:{value:i}})}function at(t,e,n){this.k=t,this.x=e,this.y=n}at.prototype={constructor:at,scale:function(t){return t===1?this:new at(this.k*t,this.x,this.y)},translate:function(t,e){return t===0&e===0?this:new at(this.k,this.x+this.k*t,this.y+this.k*e)},apply:function(t){return[t[0]*this.k+this.x,t[1]*this.k+this.y]},applyX:function(t){return t*this.k+this.x},applyY:function(t){return t*this.k+this.y},invert:function(t){return[(t[0]-this.x)/this.k,(t[1]-this.y)/this.k]},invertX:function(t){return(t-this.x)/this.k},invertY:function(t){return(t-this.y)/this.k},rescaleX:function(t){return t.copy().domain(t.range().map(this.invertX,this).map(t.invert,t))},rescaleY:function(t){return t.copy().domain(t.range().map(this.invertY,this).map(t.invert,t))},toString:function(){return"translate("+this.x+","+this.y+") scale("+this.k+")"}};var ie=new at(1,0,0);at.prototype;function ce(t){t.stopImmediatePropagation()}function bt(t){t.preventDefault(),t.stopImmediatePropagation()}function qa(t){return(!t.ctrlKey||t.type==="wheel")&&!t.button}function Va(){var t=this;return t instanceof SVGElement?(t=t.ownerSVGElement||t,t.hasAttribute("viewBox")?(t=t.viewBox.baseVal,[[t.x,t.y],[t.x+t.width,t.y+t.height]]):[[0,0],[t.width.baseVal.value,t.height.baseVal.value]]):[[0,0],[t.clientWidth,t.clientHeight]]}function en(){return this.__zoom||ie}function Ua(t){return-t.deltaY*(t.deltaMode===1?.05:t.deltaMode?1:.002)*(t.ctrlKey?10:1)}function Ga(){return navigator.maxTouchPoints||"ontouchstart"in this}function Ka(t,e,n){var r=t.invertX(e[0][0])-n[0][0],i=t.invertX(e[1][0])-n[1][0],o=t.invertY(e[0][1])-n[0][1],a=t.invertY(e[1][1])-n[1][1];return t.translate(i>r?(r+i)/2:Math.min(0,r)||Math.max(0,i),a>o?(o+a)/2:Math.min(0,o)||Math.max(0,a))}function Qa(){var t=qa,e=Va,n=Ka,r=Ua,i=Ga,o=[0,1/0],a=[[-1/0,-1/0],[1/0,1/0]],s=250,c=to,u=zt("start","zoom","end"),l,y,f,h=500,_=150,v=0,m=10;function p(d){d.property("__zoom",en).on("wheel.zoom",S,{passive:!1}).on("mousedown.zoom",D).on("dblclick.zoom",z).filter(i).on("touchstart.zoom",R).on("touchmove.zoom",q).on("touchend.zoom touchcancel.zoom",F).style("-webkit-tap-highlight-color","rgba(0,0,0,0)")}p.transform=function(d,b,x,E){var $=d.selection?d.selection():d;$.property("__zoom",en),d!==$?N(d,b,x,E):$.interrupt().each(function(){M(this,arguments).event(E).start().zoom(null,typeof b=="function"?b.apply(this,arguments):b).end()})},p.scaleBy=function(d,b,x,E){p.scaleTo(d,function(){var $=this.__zoom.k,C=typeof b=="function"?b.apply(this,arguments):b;return $*C},x,E)},p.scaleTo=function(d,b,x,E){p.transform(d,function(){var $=e.apply(this,arguments),C=this.__zoom,T=x==null?g($):typeof x=="function"?x.apply(this,arguments):x,I=C.invert(T),L=typeof b=="function"?b.apply(this,arguments):b;return n(k(w(C,L),T,I),$,a)},x,E)},p.translateBy=function(d,b,x,E){p.transform(d,function(){return n(this.__zoom.translate(typeof b=="function"?b.apply(this,arguments):b,typeof x=="function"?x.apply(this,arguments):x),e.apply(this,arguments),a)},null,E)},p.translateTo=function(d,b,x,E,$){p.transform(d,function(){var C=e.apply(this,arguments),T=this.__zoom,I=E==null?g(C):typeof E=="function"?E.apply(this,arguments):E;return n(ie.translate(I[0],I[1]).scale(T.k).translate(typeof b=="function"?-b.apply(this,arguments):-b,typeof x=="function"?-x.apply(this,arguments):-x),C,a)},E,$)};function w(d,b){return b=Math.max(o[0],Math.min(o[1],b)),b===d.k?d:new at(b,d.x,d.y)}function k(d,b,x){var E=b[0]-x[0]*d.k,$=b[1]-x[1]*d.k;return E===d.x&&$===d.y?d:new at(d.k,E,$)}function g(d){return[(+d[0][0]+ +d[1][0])/2,(+d[0][1]+ +d[1][1])/2]}function N(d,b,x,E){d.on("start.zoom",function(){M(this,arguments).event(E).start()}).on("interrupt.zoom end.zoom",function(){M(this,arguments).event(E).end()}).tween("zoom",function(){var $=this,C=arguments,T=M($,C).event(E),I=e.apply($,C),L=x==null?g(I):typeof x=="function"?x.apply($,C):x,Z=Math.max(I[1][0]-I[0][0],I[1][1]-I[0][1]),B=$.__zoom,U=typeof b=="function"?b.apply($,C):b,nt=c(B.invert(L).concat(Z/B.k),U.invert(L).concat(Z/U.k));return function(G){if(G===1)G=U;else{var rt=nt(G),oe=Z/rt[2];G=new at(oe,L[0]-rt[0]*oe,L[1]-rt[1]*oe)}T.zoom(null,G)}})}function M(d,b,x){return!x&&d.__zooming||new A(d,b)}function A(d,b){this.that=d,this.args=b,this.active=0,this.sourceEvent=null,this.extent=e.apply(d,b),this.taps=0}A.prototype={event:function(d){return d&&(this.sourceEvent=d),this},start:function(){return++this.active===1&&(this.that.__zooming=this,this.emit("start")),this},zoom:function(d,b){return this.mouse&&d!=="mouse"&&(this.mouse[1]=b.invert(this.mouse[0])),this.touch0&&d!=="touch"&&(this.touch0[1]=b.invert(this.touch0[0])),this.touch1&&d!=="touch"&&(this.touch1[1]=b.invert(this.touch1[0])),this.that.__zoom=b,this.emit("zoom"),this},end:function(){return--this.active===0&&(delete this.that.__zooming,this.emit("end")),this},emit:function(d){var b=O(this.that).datum();u.call(d,this.that,new Ya(d,{sourceEvent:this.sourceEvent,target:p,transform:this.that.__zoom,dispatch:u}),b)}};function S(d,...b){if(!t.apply(this,arguments))return;var x=M(this,b).event(d),E=this.__zoom,$=Math.max(o[0],Math.min(o[1],E.k*Math.pow(2,r.apply(this,arguments)))),C=ot(d);if(x.wheel)(x.mouse[0][0]!==C[0]||x.mouse[0][1]!==C[1])&&(x.mouse[1]=E.invert(x.mouse[0]=C)),clearTimeout(x.wheel);else{if(E.k===$)return;x.mouse=[C,E.invert(C)],Ut(this),x.start()}bt(d),x.wheel=setTimeout(T,_),x.zoom("mouse",n(k(w(E,$),x.mouse[0],x.mouse[1]),x.extent,a));function T(){x.wheel=null,x.end()}}function D(d,...b){if(f||!t.apply(this,arguments))return;var x=d.currentTarget,E=M(this,b,!0).event(d),$=O(d.view).on("mousemove.zoom",L,!0).on("mouseup.zoom",Z,!0),C=ot(d,x),T=d.clientX,I=d.clientY;wn(d.view),ce(d),E.mouse=[C,this.__zoom.invert(C)],Ut(this),E.start();function L(B){if(bt(B),!E.moved){var U=B.clientX-T,nt=B.clientY-I;E.moved=U*U+nt*nt>v}E.event(B).zoom("mouse",n(k(E.that.__zoom,E.mouse[0]=ot(B,x),E.mouse[1]),E.extent,a))}function Z(B){$.on("mousemove.zoom mouseup.zoom",null),bn(B.view,E.moved),bt(B),E.event(B).end()}}function z(d,...b){if(t.apply(this,arguments)){var x=this.__zoom,E=ot(d.changedTouches?d.changedTouches[0]:d,this),$=x.invert(E),C=x.k*(d.shiftKey?.5:2),T=n(k(w(x,C),E,$),e.apply(this,b),a);bt(d),s>0?O(this).transition().duration(s).call(N,T,E,d):O(this).call(p.transform,T,E,d)}}function R(d,...b){if(t.apply(this,arguments)){var x=d.touches,E=x.length,$=M(this,b,d.changedTouches.length===E).event(d),C,T,I,L;for(ce(d),T=0;T<E;++T)I=x[T],L=ot(I,this),L=[L,this.__zoom.invert(L),I.identifier],$.touch0?!$.touch1&&$.touch0[2]!==L[2]&&($.touch1=L,$.taps=0):($.touch0=L,C=!0,$.taps=1+!!l);l&&(l=clearTimeout(l)),C&&($.taps<2&&(y=L[0],l=setTimeout(function(){l=null},h)),Ut(this),$.start())}}function q(d,...b){if(this.__zooming){var x=M(this,b).event(d),E=d.changedTouches,$=E.length,C,T,I,L;for(bt(d),C=0;C<$;++C)T=E[C],I=ot(T,this),x.touch0&&x.touch0[2]===T.identifier?x.touch0[0]=I:x.touch1&&x.touch1[2]===T.identifier&&(x.touch1[0]=I);if(T=x.that.__zoom,x.touch1){var Z=x.touch0[0],B=x.touch0[1],U=x.touch1[0],nt=x.touch1[1],G=(G=U[0]-Z[0])*G+(G=U[1]-Z[1])*G,rt=(rt=nt[0]-B[0])*rt+(rt=nt[1]-B[1])*rt;T=w(T,Math.sqrt(G/rt)),I=[(Z[0]+U[0])/2,(Z[1]+U[1])/2],L=[(B[0]+nt[0])/2,(B[1]+nt[1])/2]}else if(x.touch0)I=x.touch0[0],L=x.touch0[1];else return;x.zoom("touch",n(k(T,I,L),x.extent,a))}}function F(d,...b){if(this.__zooming){var x=M(this,b).event(d),E=d.changedTouches,$=E.length,C,T;for(ce(d),f&&clearTimeout(f),f=setTimeout(function(){f=null},h),C=0;C<$;++C)T=E[C],x.touch0&&x.touch0[2]===T.identifier?delete x.touch0:x.touch1&&x.touch1[2]===T.identifier&&delete x.touch1;if(x.touch1&&!x.touch0&&(x.touch0=x.touch1,delete x.touch1),x.touch0)x.touch0[1]=this.__zoom.invert(x.touch0[0]);else if(x.end(),x.taps===2&&(T=ot(T,this),Math.hypot(y[0]-T[0],y[1]-T[1])<m)){var I=O(this).on("dblclick.zoom");I&&I.apply(this,arguments)}}}return p.wheelDelta=function(d){return arguments.length?(r=typeof d=="function"?d:Xt(+d),p):r},p.filter=function(d){return arguments.length?(t=typeof d=="function"?d:Xt(!!d),p):t},p.touchable=function(d){return arguments.length?(i=typeof d=="function"?d:Xt(!!d),p):i},p.extent=function(d){return arguments.length?(e=typeof d=="function"?d:Xt([[+d[0][0],+d[0][1]],[+d[1][0],+d[1][1]]]),p):e},p.scaleExtent=function(d){return arguments.length?(o[0]=+d[0],o[1]=+d[1],p):[o[0],o[1]]},p.translateExtent=function(d){return arguments.length?(a[0][0]=+d[0][0],a[1][0]=+d[1][0],a[0][1]=+d[0][1],a[1][1]=+d[1][1],p):[[a[0][0],a[0][1]],[a[1][0],a[1][1]]]},p.constrain=function(d){return arguments.length?(n=d,p):n},p.duration=function(d){return arguments.length?(s=+d,p):s},p.interpolate=function(d){return arguments.length?(c=d,p):c},p.on=function(){var d=u.on.apply(u,arguments);return d===u?p:d},p.clickDistance=function(d){return arguments.length?(v=(d=+d)*d,p):Math.sqrt(v)},p.tapDistance=function(d){return arguments.length?(m=+d,p):m},p}let P,J,Q,Gt,xe,lt,_e,St=[],Se,we=()=>{},be=()=>{};const Ln={mayor:48,deacon:36,overseer:40,witness:32,refinery:36,polecat:24,crew:24,bead:8,convoy:32};let Ht=new Set;function Wa(t,e,n){we=e||we,be=n||be,P=O(t);const r=P.node().getBoundingClientRect();P.attr("width",r.width).attr("height",r.height),P.append("defs").append("marker").attr("id","arrow-orange").attr("viewBox","0 -5 10 10").attr("refX",20).attr("refY",0).attr("markerWidth",6).attr("markerHeight",6).attr("orient","auto").append("path").attr("d","M0,-5L10,0L0,5").attr("fill","#e85d26"),J=P.append("g"),Gt=Qa().scaleExtent([.2,4]).on("zoom",o=>J.attr("transform",o.transform)),P.call(Gt),P.on("dblclick.zoom",null),P.on("dblclick",o=>{o.target===P.node()&&P.transition().duration(500).call(Gt.transform,ie)}),J.append("g").attr("class","rig-layer"),J.append("g").attr("class","edge-layer"),J.append("g").attr("class","particle-layer"),J.append("g").attr("class","node-layer"),Se=O("body").append("div").attr("class","tooltip").style("display","none"),Q=Fa().force("charge",Oa().strength(-200)).force("center",Ze(r.width/2,r.height/2)).force("collision",Sa().radius(o=>(Ln[o.type]||24)+10)).force("link",Ia().id(o=>o.id).distance(120).strength(.3)).on("tick",rs),ne(()=>{Q.alpha()<.01&&(St.forEach(o=>{!o.fx&&!o.fy&&(o.vx+=(Math.random()-.5)*.3,o.vy+=(Math.random()-.5)*.3)}),Q.alpha(.005).restart())}),window.addEventListener("resize",()=>{const o=P.node().getBoundingClientRect();P.attr("width",o.width).attr("height",o.height),Q.force("center",Ze(o.width/2,o.height/2)),Q.alpha(.1).restart()}),document.addEventListener("click",()=>{const o=document.getElementById("context-menu");o&&o.classList.add("hidden")})}function nn(t){if(!P)return;const e=t.nodes||[],n=t.edges||[],r=new Set(e.map(l=>l.id)),i=new Set,o=new Set;if(Ht.size>0){for(const l of r)Ht.has(l)||i.add(l);for(const l of Ht)r.has(l)||o.add(l)}Ht=r;const a={};St.forEach(l=>{a[l.id]=l}),e.forEach(l=>{a[l.id]&&(l.x=a[l.id].x,l.y=a[l.id].y,l.vx=a[l.id].vx,l.vy=a[l.id].vy)}),St=e;const s={};e.forEach(l=>{s[l.id]=l});const c=n.filter(l=>{var y;return s[typeof l.source=="string"?l.source:(y=l.source)==null?void 0:y.id]}).map(l=>({...l,source:typeof l.source=="string"?l.source:l.source.id,target:typeof l.target=="string"?l.target:l.target.id})),u={};e.forEach(l=>{l.rig&&(u[l.rig]||(u[l.rig]=[]),u[l.rig].push(l))}),Za(u),ja(c),ts(e,i),Q.nodes(e),Q.force("link").links(c),Q.alpha(.3).restart(),is(u)}function Za(t){const e=J.select(".rig-layer"),n=Object.entries(t).map(([o,a])=>({name:o,nodes:a})),r=e.selectAll(".rig-group").data(n,o=>o.name),i=r.enter().append("g").attr("class","rig-group");i.append("rect").attr("class","rig-container"),i.append("text").attr("class","rig-label"),i.on("dblclick",(o,a)=>{o.stopPropagation(),Ja(a)}),r.exit().remove(),_e=r.merge(i)}function Ja(t){if(!t.nodes.length)return;const e=80;let n=1/0,r=1/0,i=-1/0,o=-1/0;if(t.nodes.forEach(_=>{_.x!==void 0&&(n=Math.min(n,_.x),r=Math.min(r,_.y),i=Math.max(i,_.x),o=Math.max(o,_.y))}),n===1/0)return;const a=P.node().getBoundingClientRect(),s=i-n+e*2,c=o-r+e*2,u=(n+i)/2,l=(r+o)/2,y=Math.min(a.width/s,a.height/c,2.5),f=a.width/2-u*y,h=a.height/2-l*y;P.transition().duration(500).call(Gt.transform,ie.translate(f,h).scale(y))}function ja(t){const n=J.select(".edge-layer").selectAll(".edge").data(t,i=>i.type+":"+(i.source.id||i.source)+":"+(i.target.id||i.target)),r=n.enter().append("line").attr("class",i=>"edge edge-"+i.type);n.exit().remove(),lt=n.merge(r),lt.filter(i=>i.type==="monitoring").classed("heartbeat",!0)}function ts(t,e){const r=J.select(".node-layer").selectAll(".node").data(t,a=>a.id),i=r.enter().append("g").attr("class",a=>"node node-"+a.type+" state-"+a.state).call(Ai().on("start",ss).on("drag",us).on("end",cs)).on("click",(a,s)=>{a.stopPropagation(),we(s)}).on("contextmenu",(a,s)=>{a.preventDefault(),a.stopPropagation(),be(a,s)}).on("mouseenter",os).on("mouseleave",as);i.each(function(a){const s=O(this),c=Ln[a.type]||24;switch(a.type){case"mayor":case"deacon":es(s,c);break;case"overseer":ns(s,c);break;case"witness":case"polecat":s.append("circle").attr("class","shape").attr("r",c/2);break;case"refinery":case"crew":s.append("rect").attr("class","shape").attr("x",-c/2).attr("y",-c*.4).attr("width",c).attr("height",c*.8).attr("rx",4);break;case"bead":s.append("circle").attr("class","shape").attr("r",c/2);break;case"convoy":s.append("rect").attr("class","shape").attr("x",-c/2).attr("y",-7).attr("width",c).attr("height",14).attr("rx",7);break;default:s.append("circle").attr("class","shape").attr("r",c/2)}a.type!=="bead"&&s.append("text").attr("dy",c/2+14).text(a.label)}),i.filter(a=>e.has(a.id)).style("opacity",0).transition().duration(600).style("opacity",1),i.filter(a=>e.has(a.id)&&a.type==="polecat").each(function(){O(this).append("circle").attr("class","spawn-ring").attr("r",0).attr("fill","none").attr("stroke","#39ff14").attr("stroke-width",3).attr("opacity",.8).transition().duration(600).attr("r",30).attr("opacity",0).attr("stroke-width",0).remove()}),r.attr("class",a=>"node node-"+a.type+" state-"+a.state);const o=r.merge(i);o.classed("breathing",a=>a.type==="polecat"&&a.state==="working"||["witness","refinery","mayor","deacon"].includes(a.type)&&a.state==="running"),o.classed("pulse-yellow",a=>a.type==="bead"&&a.state==="hooked"),r.exit().transition().duration(800).style("opacity",0).attr("transform",a=>`translate(${a.x},${a.y}) scale(0.3)`).remove(),xe=o}function es(t,e){const n=e/2,r=[];for(let i=0;i<6;i++){const o=Math.PI/3*i-Math.PI/2;r.push([n*Math.cos(o),n*Math.sin(o)])}t.append("polygon").attr("class","shape").attr("points",r.map(i=>i.join(",")).join(" "))}function ns(t,e){const n=e/2,r=[[0,-n],[n,0],[0,n],[-n,0]];t.append("polygon").attr("class","shape").attr("points",r.map(i=>i.join(",")).join(" "))}function rs(){lt&&lt.attr("x1",t=>t.source.x).attr("y1",t=>t.source.y).attr("x2",t=>t.target.x).attr("y2",t=>t.target.y),xe&&xe.attr("transform",t=>`translate(${t.x},${t.y})`),_e&&_e.each(function(t){const e=O(this),n=40;let r=1/0,i=1/0,o=-1/0,a=-1/0;t.nodes.forEach(s=>{s.x!==void 0&&(r=Math.min(r,s.x),i=Math.min(i,s.y),o=Math.max(o,s.x),a=Math.max(a,s.y))}),r!==1/0&&(e.select(".rig-container").attr("x",r-n).attr("y",i-n).attr("width",o-r+n*2).attr("height",a-i+n*2),e.select(".rig-label").attr("x",r-n+8).attr("y",i-n+16).text(t.name))})}function is(t){const e=P.node().getBoundingClientRect(),n=Object.keys(t),r={};n.forEach((i,o)=>{const a=2*Math.PI*o/n.length,s=e.width/2+Math.cos(a)*Math.min(e.width,e.height)*.25,c=e.height/2+Math.sin(a)*Math.min(e.width,e.height)*.25;r[i]={x:s,y:c}}),Q.force("cluster",i=>{St.forEach(o=>{if(o.rig&&r[o.rig]){const a=r[o.rig];o.vx+=(a.x-o.x)*i*.15,o.vy+=(a.y-o.y)*i*.15}})})}function os(t,e){var i,o;const r={working:"#4a8db7",idle:"#444455",running:"#39ff14",nuked:"#2a2a3e",spawning:"#39ff14",stopped:"#ff3344",unassigned:"#444455",hooked:"#f0c040",in_progress:"#4a8db7",in_refinery:"#e85d26",merged:"#39ff14",closed:"#39ff14",rejected:"#ff3344",escalated:"#cc44ff"}[e.state]||"#8888a0";Se.style("display","block").style("left",t.pageX+12+"px").style("top",t.pageY-8+"px").html(`
-      <div class="tt-label">${e.label}</div>
-      <div class="tt-type">${e.type}${e.rig?" · "+e.rig:""}</div>
-      <div class="tt-state" style="color: ${r}">${e.state}</div>
-      ${(i=e.metadata)!=null&&i.hooked_bead?`<div class="tt-type">hook: ${e.metadata.hooked_bead}</div>`:""}
-      ${(o=e.metadata)!=null&&o.title?`<div class="tt-type">${e.metadata.title}</div>`:""}
-    `),lt&&lt.style("opacity",a=>{const s=a.source.id||a.source,c=a.target.id||a.target;return s===e.id||c===e.id?1:.15})}function as(){Se.style("display","none"),lt&&lt.style("opacity",null)}function ss(t,e){t.active||Q.alphaTarget(.3).restart(),e.fx=e.x,e.fy=e.y}function us(t,e){e.fx=t.x,e.fy=t.y}function cs(t,e){t.active||Q.alphaTarget(0),e.fx=null,e.fy=null}function ls(t){const e=St.find(r=>r.id===t);if(!e)return;J.select(".particle-layer").append("circle").attr("cx",e.x).attr("cy",e.y).attr("r",18).attr("fill","none").attr("stroke","#39ff14").attr("stroke-width",3).attr("opacity",.8).transition().duration(500).attr("r",60).attr("opacity",0).attr("stroke-width",0).remove()}let ze,Bn,Ie,rn;function yt(t){const e=document.createElement("div");return e.textContent=String(t),e.innerHTML}function Et(t,e){return`<div class="field-label">${yt(t)}</div><div class="field-value">${e}</div>`}function fs(t){return`<span class="state-badge" style="background: ${{working:"var(--accent-blue)",idle:"var(--node-idle)",running:"var(--accent-green)",stopped:"var(--accent-red)",nuked:"var(--text-muted)",spawning:"var(--accent-green)",unassigned:"var(--node-idle)",hooked:"var(--accent-yellow)",in_progress:"var(--accent-blue)",in_refinery:"var(--accent-orange)",merged:"var(--accent-green)",closed:"var(--accent-green)",rejected:"var(--accent-red)",escalated:"var(--accent-magenta)"}[t]||"var(--text-secondary)"}; color: #0a0a0f;">${t}</span>`}function hs(t){switch(t.type){case"bead":return`bd show ${t.label}`;case"polecat":return`gt peek ${t.rig}/polecats/${t.label}`;case"witness":return`gt peek ${t.rig}/witness`;case"refinery":return`gt peek ${t.rig}/refinery`;default:return null}}function ds(t){let e="";e+=Et("ID",yt(t.id)),e+=Et("Type",yt(t.type)),e+=Et("State",fs(t.state,t.type)),t.rig&&(e+=Et("Rig",yt(t.rig))),t.metadata&&Object.entries(t.metadata).forEach(([r,i])=>{i&&(e+=Et(r.replace(/_/g," "),yt(i)))});const n=hs(t);return n&&(e+=`
+(function(){const e=document.createElement("link").relList;if(e&&e.supports&&e.supports("modulepreload"))return;for(const i of document.querySelectorAll('link[rel="modulepreload"]'))r(i);new MutationObserver(i=>{for(const o of i)if(o.type==="childList")for(const a of o.addedNodes)a.tagName==="LINK"&&a.rel==="modulepreload"&&r(a)}).observe(document,{childList:!0,subtree:!0});function n(i){const o={};return i.integrity&&(o.integrity=i.integrity),i.referrerPolicy&&(o.referrerPolicy=i.referrerPolicy),i.crossOrigin==="use-credentials"?o.credentials="include":i.crossOrigin==="anonymous"?o.credentials="omit":o.credentials="same-origin",o}function r(i){if(i.ep)return;i.ep=!0;const o=n(i);fetch(i.href,o)}})();var Fn={value:()=>{}};function zt(){for(var t=0,e=arguments.length,n={},r;t<e;++t){if(!(r=arguments[t]+"")||r in n||/[\s.]/.test(r))throw new Error("illegal type: "+r);n[r]=[]}return new Yt(n)}function Yt(t){this._=t}function On(t,e){return t.trim().split(/^|\s+/).map(function(n){var r="",i=n.indexOf(".");if(i>=0&&(r=n.slice(i+1),n=n.slice(0,i)),n&&!e.hasOwnProperty(n))throw new Error("unknown type: "+n);return{type:n,name:r}})}Yt.prototype=zt.prototype={constructor:Yt,on:function(t,e){var n=this._,r=On(t+"",n),i,o=-1,a=r.length;if(arguments.length<2){for(;++o<a;)if((i=(t=r[o]).type)&&(i=Yn(n[i],t.name)))return i;return}if(e!=null&&typeof e!="function")throw new Error("invalid callback: "+e);for(;++o<a;)if(i=(t=r[o]).type)n[i]=De(n[i],t.name,e);else if(e==null)for(i in n)n[i]=De(n[i],t.name,null);return this},copy:function(){var t={},e=this._;for(var n in e)t[n]=e[n].slice();return new Yt(t)},call:function(t,e){if((i=arguments.length-2)>0)for(var n=new Array(i),r=0,i,o;r<i;++r)n[r]=arguments[r+2];if(!this._.hasOwnProperty(t))throw new Error("unknown type: "+t);for(o=this._[t],r=0,i=o.length;r<i;++r)o[r].value.apply(e,n)},apply:function(t,e,n){if(!this._.hasOwnProperty(t))throw new Error("unknown type: "+t);for(var r=this._[t],i=0,o=r.length;i<o;++i)r[i].value.apply(e,n)}};function Yn(t,e){for(var n=0,r=t.length,i;n<r;++n)if((i=t[n]).name===e)return i.value}function De(t,e,n){for(var r=0,i=t.length;r<i;++r)if(t[r].name===e){t[r]=Fn,t=t.slice(0,r).concat(t.slice(r+1));break}return n!=null&&t.push({name:e,value:n}),t}var fe="http://www.w3.org/1999/xhtml";const Re={svg:"http://www.w3.org/2000/svg",xhtml:fe,xlink:"http://www.w3.org/1999/xlink",xml:"http://www.w3.org/XML/1998/namespace",xmlns:"http://www.w3.org/2000/xmlns/"};function te(t){var e=t+="",n=e.indexOf(":");return n>=0&&(e=t.slice(0,n))!=="xmlns"&&(t=t.slice(n+1)),Re.hasOwnProperty(e)?{space:Re[e],local:t}:t}function qn(t){return function(){var e=this.ownerDocument,n=this.namespaceURI;return n===fe&&e.documentElement.namespaceURI===fe?e.createElement(t):e.createElementNS(n,t)}}function Vn(t){return function(){return this.ownerDocument.createElementNS(t.space,t.local)}}function cn(t){var e=te(t);return(e.local?Vn:qn)(e)}function Un(){}function Ee(t){return t==null?Un:function(){return this.querySelector(t)}}function Gn(t){typeof t!="function"&&(t=Ee(t));for(var e=this._groups,n=e.length,r=new Array(n),i=0;i<n;++i)for(var o=e[i],a=o.length,s=r[i]=new Array(a),c,u,l=0;l<a;++l)(c=o[l])&&(u=t.call(c,c.__data__,l,o))&&("__data__"in c&&(u.__data__=c.__data__),s[l]=u);return new V(r,this._parents)}function Kn(t){return t==null?[]:Array.isArray(t)?t:Array.from(t)}function Qn(){return[]}function ln(t){return t==null?Qn:function(){return this.querySelectorAll(t)}}function Wn(t){return function(){return Kn(t.apply(this,arguments))}}function Zn(t){typeof t=="function"?t=Wn(t):t=ln(t);for(var e=this._groups,n=e.length,r=[],i=[],o=0;o<n;++o)for(var a=e[o],s=a.length,c,u=0;u<s;++u)(c=a[u])&&(r.push(t.call(c,c.__data__,u,a)),i.push(c));return new V(r,i)}function fn(t){return function(){return this.matches(t)}}function hn(t){return function(e){return e.matches(t)}}var Jn=Array.prototype.find;function jn(t){return function(){return Jn.call(this.children,t)}}function tr(){return this.firstElementChild}function er(t){return this.select(t==null?tr:jn(typeof t=="function"?t:hn(t)))}var nr=Array.prototype.filter;function rr(){return Array.from(this.children)}function ir(t){return function(){return nr.call(this.children,t)}}function or(t){return this.selectAll(t==null?rr:ir(typeof t=="function"?t:hn(t)))}function ar(t){typeof t!="function"&&(t=fn(t));for(var e=this._groups,n=e.length,r=new Array(n),i=0;i<n;++i)for(var o=e[i],a=o.length,s=r[i]=[],c,u=0;u<a;++u)(c=o[u])&&t.call(c,c.__data__,u,o)&&s.push(c);return new V(r,this._parents)}function dn(t){return new Array(t.length)}function sr(){return new V(this._enter||this._groups.map(dn),this._parents)}function Kt(t,e){this.ownerDocument=t.ownerDocument,this.namespaceURI=t.namespaceURI,this._next=null,this._parent=t,this.__data__=e}Kt.prototype={constructor:Kt,appendChild:function(t){return this._parent.insertBefore(t,this._next)},insertBefore:function(t,e){return this._parent.insertBefore(t,e)},querySelector:function(t){return this._parent.querySelector(t)},querySelectorAll:function(t){return this._parent.querySelectorAll(t)}};function ur(t){return function(){return t}}function cr(t,e,n,r,i,o){for(var a=0,s,c=e.length,u=o.length;a<u;++a)(s=e[a])?(s.__data__=o[a],r[a]=s):n[a]=new Kt(t,o[a]);for(;a<c;++a)(s=e[a])&&(i[a]=s)}function lr(t,e,n,r,i,o,a){var s,c,u=new Map,l=e.length,y=o.length,f=new Array(l),h;for(s=0;s<l;++s)(c=e[s])&&(f[s]=h=a.call(c,c.__data__,s,e)+"",u.has(h)?i[s]=c:u.set(h,c));for(s=0;s<y;++s)h=a.call(t,o[s],s,o)+"",(c=u.get(h))?(r[s]=c,c.__data__=o[s],u.delete(h)):n[s]=new Kt(t,o[s]);for(s=0;s<l;++s)(c=e[s])&&u.get(f[s])===c&&(i[s]=c)}function fr(t){return t.__data__}function hr(t,e){if(!arguments.length)return Array.from(this,fr);var n=e?lr:cr,r=this._parents,i=this._groups;typeof t!="function"&&(t=ur(t));for(var o=i.length,a=new Array(o),s=new Array(o),c=new Array(o),u=0;u<o;++u){var l=r[u],y=i[u],f=y.length,h=dr(t.call(l,l&&l.__data__,u,r)),_=h.length,v=s[u]=new Array(_),m=a[u]=new Array(_),p=c[u]=new Array(f);n(l,y,v,m,p,h,e);for(var w=0,k=0,g,N;w<_;++w)if(g=v[w]){for(w>=k&&(k=w+1);!(N=m[k])&&++k<_;);g._next=N||null}}return a=new V(a,r),a._enter=s,a._exit=c,a}function dr(t){return typeof t=="object"&&"length"in t?t:Array.from(t)}function gr(){return new V(this._exit||this._groups.map(dn),this._parents)}function pr(t,e,n){var r=this.enter(),i=this,o=this.exit();return typeof t=="function"?(r=t(r),r&&(r=r.selection())):r=r.append(t+""),e!=null&&(i=e(i),i&&(i=i.selection())),n==null?o.remove():n(o),r&&i?r.merge(i).order():i}function yr(t){for(var e=t.selection?t.selection():t,n=this._groups,r=e._groups,i=n.length,o=r.length,a=Math.min(i,o),s=new Array(i),c=0;c<a;++c)for(var u=n[c],l=r[c],y=u.length,f=s[c]=new Array(y),h,_=0;_<y;++_)(h=u[_]||l[_])&&(f[_]=h);for(;c<i;++c)s[c]=n[c];return new V(s,this._parents)}function mr(){for(var t=this._groups,e=-1,n=t.length;++e<n;)for(var r=t[e],i=r.length-1,o=r[i],a;--i>=0;)(a=r[i])&&(o&&a.compareDocumentPosition(o)^4&&o.parentNode.insertBefore(a,o),o=a);return this}function vr(t){t||(t=xr);function e(y,f){return y&&f?t(y.__data__,f.__data__):!y-!f}for(var n=this._groups,r=n.length,i=new Array(r),o=0;o<r;++o){for(var a=n[o],s=a.length,c=i[o]=new Array(s),u,l=0;l<s;++l)(u=a[l])&&(c[l]=u);c.sort(e)}return new V(i,this._parents).order()}function xr(t,e){return t<e?-1:t>e?1:t>=e?0:NaN}function _r(){var t=arguments[0];return arguments[0]=this,t.apply(null,arguments),this}function wr(){return Array.from(this)}function br(){for(var t=this._groups,e=0,n=t.length;e<n;++e)for(var r=t[e],i=0,o=r.length;i<o;++i){var a=r[i];if(a)return a}return null}function Er(){let t=0;for(const e of this)++t;return t}function Nr(){return!this.node()}function kr(t){for(var e=this._groups,n=0,r=e.length;n<r;++n)for(var i=e[n],o=0,a=i.length,s;o<a;++o)(s=i[o])&&t.call(s,s.__data__,o,i);return this}function Mr(t){return function(){this.removeAttribute(t)}}function $r(t){return function(){this.removeAttributeNS(t.space,t.local)}}function Ar(t,e){return function(){this.setAttribute(t,e)}}function Cr(t,e){return function(){this.setAttributeNS(t.space,t.local,e)}}function Tr(t,e){return function(){var n=e.apply(this,arguments);n==null?this.removeAttribute(t):this.setAttribute(t,n)}}function Sr(t,e){return function(){var n=e.apply(this,arguments);n==null?this.removeAttributeNS(t.space,t.local):this.setAttributeNS(t.space,t.local,n)}}function zr(t,e){var n=te(t);if(arguments.length<2){var r=this.node();return n.local?r.getAttributeNS(n.space,n.local):r.getAttribute(n)}return this.each((e==null?n.local?$r:Mr:typeof e=="function"?n.local?Sr:Tr:n.local?Cr:Ar)(n,e))}function gn(t){return t.ownerDocument&&t.ownerDocument.defaultView||t.document&&t||t.defaultView}function Ir(t){return function(){this.style.removeProperty(t)}}function Dr(t,e,n){return function(){this.style.setProperty(t,e,n)}}function Rr(t,e,n){return function(){var r=e.apply(this,arguments);r==null?this.style.removeProperty(t):this.style.setProperty(t,r,n)}}function Lr(t,e,n){return arguments.length>1?this.each((e==null?Ir:typeof e=="function"?Rr:Dr)(t,e,n??"")):xt(this.node(),t)}function xt(t,e){return t.style.getPropertyValue(e)||gn(t).getComputedStyle(t,null).getPropertyValue(e)}function Br(t){return function(){delete this[t]}}function Pr(t,e){return function(){this[t]=e}}function Xr(t,e){return function(){var n=e.apply(this,arguments);n==null?delete this[t]:this[t]=n}}function Hr(t,e){return arguments.length>1?this.each((e==null?Br:typeof e=="function"?Xr:Pr)(t,e)):this.node()[t]}function pn(t){return t.trim().split(/^|\s+/)}function Ne(t){return t.classList||new yn(t)}function yn(t){this._node=t,this._names=pn(t.getAttribute("class")||"")}yn.prototype={add:function(t){var e=this._names.indexOf(t);e<0&&(this._names.push(t),this._node.setAttribute("class",this._names.join(" ")))},remove:function(t){var e=this._names.indexOf(t);e>=0&&(this._names.splice(e,1),this._node.setAttribute("class",this._names.join(" ")))},contains:function(t){return this._names.indexOf(t)>=0}};function mn(t,e){for(var n=Ne(t),r=-1,i=e.length;++r<i;)n.add(e[r])}function vn(t,e){for(var n=Ne(t),r=-1,i=e.length;++r<i;)n.remove(e[r])}function Fr(t){return function(){mn(this,t)}}function Or(t){return function(){vn(this,t)}}function Yr(t,e){return function(){(e.apply(this,arguments)?mn:vn)(this,t)}}function qr(t,e){var n=pn(t+"");if(arguments.length<2){for(var r=Ne(this.node()),i=-1,o=n.length;++i<o;)if(!r.contains(n[i]))return!1;return!0}return this.each((typeof e=="function"?Yr:e?Fr:Or)(n,e))}function Vr(){this.textContent=""}function Ur(t){return function(){this.textContent=t}}function Gr(t){return function(){var e=t.apply(this,arguments);this.textContent=e??""}}function Kr(t){return arguments.length?this.each(t==null?Vr:(typeof t=="function"?Gr:Ur)(t)):this.node().textContent}function Qr(){this.innerHTML=""}function Wr(t){return function(){this.innerHTML=t}}function Zr(t){return function(){var e=t.apply(this,arguments);this.innerHTML=e??""}}function Jr(t){return arguments.length?this.each(t==null?Qr:(typeof t=="function"?Zr:Wr)(t)):this.node().innerHTML}function jr(){this.nextSibling&&this.parentNode.appendChild(this)}function ti(){return this.each(jr)}function ei(){this.previousSibling&&this.parentNode.insertBefore(this,this.parentNode.firstChild)}function ni(){return this.each(ei)}function ri(t){var e=typeof t=="function"?t:cn(t);return this.select(function(){return this.appendChild(e.apply(this,arguments))})}function ii(){return null}function oi(t,e){var n=typeof t=="function"?t:cn(t),r=e==null?ii:typeof e=="function"?e:Ee(e);return this.select(function(){return this.insertBefore(n.apply(this,arguments),r.apply(this,arguments)||null)})}function ai(){var t=this.parentNode;t&&t.removeChild(this)}function si(){return this.each(ai)}function ui(){var t=this.cloneNode(!1),e=this.parentNode;return e?e.insertBefore(t,this.nextSibling):t}function ci(){var t=this.cloneNode(!0),e=this.parentNode;return e?e.insertBefore(t,this.nextSibling):t}function li(t){return this.select(t?ci:ui)}function fi(t){return arguments.length?this.property("__data__",t):this.node().__data__}function hi(t){return function(e){t.call(this,e,this.__data__)}}function di(t){return t.trim().split(/^|\s+/).map(function(e){var n="",r=e.indexOf(".");return r>=0&&(n=e.slice(r+1),e=e.slice(0,r)),{type:e,name:n}})}function gi(t){return function(){var e=this.__on;if(e){for(var n=0,r=-1,i=e.length,o;n<i;++n)o=e[n],(!t.type||o.type===t.type)&&o.name===t.name?this.removeEventListener(o.type,o.listener,o.options):e[++r]=o;++r?e.length=r:delete this.__on}}}function pi(t,e,n){return function(){var r=this.__on,i,o=hi(e);if(r){for(var a=0,s=r.length;a<s;++a)if((i=r[a]).type===t.type&&i.name===t.name){this.removeEventListener(i.type,i.listener,i.options),this.addEventListener(i.type,i.listener=o,i.options=n),i.value=e;return}}this.addEventListener(t.type,o,n),i={type:t.type,name:t.name,value:e,listener:o,options:n},r?r.push(i):this.__on=[i]}}function yi(t,e,n){var r=di(t+""),i,o=r.length,a;if(arguments.length<2){var s=this.node().__on;if(s){for(var c=0,u=s.length,l;c<u;++c)for(i=0,l=s[c];i<o;++i)if((a=r[i]).type===l.type&&a.name===l.name)return l.value}return}for(s=e?pi:gi,i=0;i<o;++i)this.each(s(r[i],e,n));return this}function xn(t,e,n){var r=gn(t),i=r.CustomEvent;typeof i=="function"?i=new i(e,n):(i=r.document.createEvent("Event"),n?(i.initEvent(e,n.bubbles,n.cancelable),i.detail=n.detail):i.initEvent(e,!1,!1)),t.dispatchEvent(i)}function mi(t,e){return function(){return xn(this,t,e)}}function vi(t,e){return function(){return xn(this,t,e.apply(this,arguments))}}function xi(t,e){return this.each((typeof e=="function"?vi:mi)(t,e))}function*_i(){for(var t=this._groups,e=0,n=t.length;e<n;++e)for(var r=t[e],i=0,o=r.length,a;i<o;++i)(a=r[i])&&(yield a)}var _n=[null];function V(t,e){this._groups=t,this._parents=e}function It(){return new V([[document.documentElement]],_n)}function wi(){return this}V.prototype=It.prototype={constructor:V,select:Gn,selectAll:Zn,selectChild:er,selectChildren:or,filter:ar,data:hr,enter:sr,exit:gr,join:pr,merge:yr,selection:wi,order:mr,sort:vr,call:_r,nodes:wr,node:br,size:Er,empty:Nr,each:kr,attr:zr,style:Lr,property:Hr,classed:qr,text:Kr,html:Jr,raise:ti,lower:ni,append:ri,insert:oi,remove:si,clone:li,datum:fi,on:yi,dispatch:xi,[Symbol.iterator]:_i};function O(t){return typeof t=="string"?new V([[document.querySelector(t)]],[document.documentElement]):new V([[t]],_n)}function bi(t){let e;for(;e=t.sourceEvent;)t=e;return t}function ot(t,e){if(t=bi(t),e===void 0&&(e=t.currentTarget),e){var n=e.ownerSVGElement||e;if(n.createSVGPoint){var r=n.createSVGPoint();return r.x=t.clientX,r.y=t.clientY,r=r.matrixTransform(e.getScreenCTM().inverse()),[r.x,r.y]}if(e.getBoundingClientRect){var i=e.getBoundingClientRect();return[t.clientX-i.left-e.clientLeft,t.clientY-i.top-e.clientTop]}}return[t.pageX,t.pageY]}const Ei={passive:!1},Mt={capture:!0,passive:!1};function ae(t){t.stopImmediatePropagation()}function mt(t){t.preventDefault(),t.stopImmediatePropagation()}function wn(t){var e=t.document.documentElement,n=O(t).on("dragstart.drag",mt,Mt);"onselectstart"in e?n.on("selectstart.drag",mt,Mt):(e.__noselect=e.style.MozUserSelect,e.style.MozUserSelect="none")}function bn(t,e){var n=t.document.documentElement,r=O(t).on("dragstart.drag",null);e&&(r.on("click.drag",mt,Mt),setTimeout(function(){r.on("click.drag",null)},0)),"onselectstart"in n?r.on("selectstart.drag",null):(n.style.MozUserSelect=n.__noselect,delete n.__noselect)}const Rt=t=>()=>t;function he(t,{sourceEvent:e,subject:n,target:r,identifier:i,active:o,x:a,y:s,dx:c,dy:u,dispatch:l}){Object.defineProperties(this,{type:{value:t,enumerable:!0,configurable:!0},sourceEvent:{value:e,enumerable:!0,configurable:!0},subject:{value:n,enumerable:!0,configurable:!0},target:{value:r,enumerable:!0,configurable:!0},identifier:{value:i,enumerable:!0,configurable:!0},active:{value:o,enumerable:!0,configurable:!0},x:{value:a,enumerable:!0,configurable:!0},y:{value:s,enumerable:!0,configurable:!0},dx:{value:c,enumerable:!0,configurable:!0},dy:{value:u,enumerable:!0,configurable:!0},_:{value:l}})}he.prototype.on=function(){var t=this._.on.apply(this._,arguments);return t===this._?this:t};function Ni(t){return!t.ctrlKey&&!t.button}function ki(){return this.parentNode}function Mi(t,e){return e??{x:t.x,y:t.y}}function $i(){return navigator.maxTouchPoints||"ontouchstart"in this}function Ai(){var t=Ni,e=ki,n=Mi,r=$i,i={},o=zt("start","drag","end"),a=0,s,c,u,l,y=0;function f(g){g.on("mousedown.drag",h).filter(r).on("touchstart.drag",m).on("touchmove.drag",p,Ei).on("touchend.drag touchcancel.drag",w).style("touch-action","none").style("-webkit-tap-highlight-color","rgba(0,0,0,0)")}function h(g,N){if(!(l||!t.call(this,g,N))){var M=k(this,e.call(this,g,N),g,N,"mouse");M&&(O(g.view).on("mousemove.drag",_,Mt).on("mouseup.drag",v,Mt),wn(g.view),ae(g),u=!1,s=g.clientX,c=g.clientY,M("start",g))}}function _(g){if(mt(g),!u){var N=g.clientX-s,M=g.clientY-c;u=N*N+M*M>y}i.mouse("drag",g)}function v(g){O(g.view).on("mousemove.drag mouseup.drag",null),bn(g.view,u),mt(g),i.mouse("end",g)}function m(g,N){if(t.call(this,g,N)){var M=g.changedTouches,A=e.call(this,g,N),S=M.length,D,z;for(D=0;D<S;++D)(z=k(this,A,g,N,M[D].identifier,M[D]))&&(ae(g),z("start",g,M[D]))}}function p(g){var N=g.changedTouches,M=N.length,A,S;for(A=0;A<M;++A)(S=i[N[A].identifier])&&(mt(g),S("drag",g,N[A]))}function w(g){var N=g.changedTouches,M=N.length,A,S;for(l&&clearTimeout(l),l=setTimeout(function(){l=null},500),A=0;A<M;++A)(S=i[N[A].identifier])&&(ae(g),S("end",g,N[A]))}function k(g,N,M,A,S,D){var z=o.copy(),R=ot(D||M,N),q,F,d;if((d=n.call(g,new he("beforestart",{sourceEvent:M,target:f,identifier:S,active:a,x:R[0],y:R[1],dx:0,dy:0,dispatch:z}),A))!=null)return q=d.x-R[0]||0,F=d.y-R[1]||0,function b(x,E,$){var C=R,T;switch(x){case"start":i[S]=b,T=a++;break;case"end":delete i[S],--a;case"drag":R=ot($||E,N),T=a;break}z.call(x,g,new he(x,{sourceEvent:E,subject:d,target:f,identifier:S,active:T,x:R[0]+q,y:R[1]+F,dx:R[0]-C[0],dy:R[1]-C[1],dispatch:z}),A)}}return f.filter=function(g){return arguments.length?(t=typeof g=="function"?g:Rt(!!g),f):t},f.container=function(g){return arguments.length?(e=typeof g=="function"?g:Rt(g),f):e},f.subject=function(g){return arguments.length?(n=typeof g=="function"?g:Rt(g),f):n},f.touchable=function(g){return arguments.length?(r=typeof g=="function"?g:Rt(!!g),f):r},f.on=function(){var g=o.on.apply(o,arguments);return g===o?f:g},f.clickDistance=function(g){return arguments.length?(y=(g=+g)*g,f):Math.sqrt(y)},f}function ke(t,e,n){t.prototype=e.prototype=n,n.constructor=t}function En(t,e){var n=Object.create(t.prototype);for(var r in e)n[r]=e[r];return n}function Dt(){}var $t=.7,Qt=1/$t,vt="\\s*([+-]?\\d+)\\s*",At="\\s*([+-]?(?:\\d*\\.)?\\d+(?:[eE][+-]?\\d+)?)\\s*",tt="\\s*([+-]?(?:\\d*\\.)?\\d+(?:[eE][+-]?\\d+)?)%\\s*",Ci=/^#([0-9a-f]{3,8})$/,Ti=new RegExp(`^rgb\\(${vt},${vt},${vt}\\)$`),Si=new RegExp(`^rgb\\(${tt},${tt},${tt}\\)$`),zi=new RegExp(`^rgba\\(${vt},${vt},${vt},${At}\\)$`),Ii=new RegExp(`^rgba\\(${tt},${tt},${tt},${At}\\)$`),Di=new RegExp(`^hsl\\(${At},${tt},${tt}\\)$`),Ri=new RegExp(`^hsla\\(${At},${tt},${tt},${At}\\)$`),Le={aliceblue:15792383,antiquewhite:16444375,aqua:65535,aquamarine:8388564,azure:15794175,beige:16119260,bisque:16770244,black:0,blanchedalmond:16772045,blue:255,blueviolet:9055202,brown:10824234,burlywood:14596231,cadetblue:6266528,chartreuse:8388352,chocolate:13789470,coral:16744272,cornflowerblue:6591981,cornsilk:16775388,crimson:14423100,cyan:65535,darkblue:139,darkcyan:35723,darkgoldenrod:12092939,darkgray:11119017,darkgreen:25600,darkgrey:11119017,darkkhaki:12433259,darkmagenta:9109643,darkolivegreen:5597999,darkorange:16747520,darkorchid:10040012,darkred:9109504,darksalmon:15308410,darkseagreen:9419919,darkslateblue:4734347,darkslategray:3100495,darkslategrey:3100495,darkturquoise:52945,darkviolet:9699539,deeppink:16716947,deepskyblue:49151,dimgray:6908265,dimgrey:6908265,dodgerblue:2003199,firebrick:11674146,floralwhite:16775920,forestgreen:2263842,fuchsia:16711935,gainsboro:14474460,ghostwhite:16316671,gold:16766720,goldenrod:14329120,gray:8421504,green:32768,greenyellow:11403055,grey:8421504,honeydew:15794160,hotpink:16738740,indianred:13458524,indigo:4915330,ivory:16777200,khaki:15787660,lavender:15132410,lavenderblush:16773365,lawngreen:8190976,lemonchiffon:16775885,lightblue:11393254,lightcoral:15761536,lightcyan:14745599,lightgoldenrodyellow:16448210,lightgray:13882323,lightgreen:9498256,lightgrey:13882323,lightpink:16758465,lightsalmon:16752762,lightseagreen:2142890,lightskyblue:8900346,lightslategray:7833753,lightslategrey:7833753,lightsteelblue:11584734,lightyellow:16777184,lime:65280,limegreen:3329330,linen:16445670,magenta:16711935,maroon:8388608,mediumaquamarine:6737322,mediumblue:205,mediumorchid:12211667,mediumpurple:9662683,mediumseagreen:3978097,mediumslateblue:8087790,mediumspringgreen:64154,mediumturquoise:4772300,mediumvioletred:13047173,midnightblue:1644912,mintcream:16121850,mistyrose:16770273,moccasin:16770229,navajowhite:16768685,navy:128,oldlace:16643558,olive:8421376,olivedrab:7048739,orange:16753920,orangered:16729344,orchid:14315734,palegoldenrod:15657130,palegreen:10025880,paleturquoise:11529966,palevioletred:14381203,papayawhip:16773077,peachpuff:16767673,peru:13468991,pink:16761035,plum:14524637,powderblue:11591910,purple:8388736,rebeccapurple:6697881,red:16711680,rosybrown:12357519,royalblue:4286945,saddlebrown:9127187,salmon:16416882,sandybrown:16032864,seagreen:3050327,seashell:16774638,sienna:10506797,silver:12632256,skyblue:8900331,slateblue:6970061,slategray:7372944,slategrey:7372944,snow:16775930,springgreen:65407,steelblue:4620980,tan:13808780,teal:32896,thistle:14204888,tomato:16737095,turquoise:4251856,violet:15631086,wheat:16113331,white:16777215,whitesmoke:16119285,yellow:16776960,yellowgreen:10145074};ke(Dt,Ct,{copy(t){return Object.assign(new this.constructor,this,t)},displayable(){return this.rgb().displayable()},hex:Be,formatHex:Be,formatHex8:Li,formatHsl:Bi,formatRgb:Pe,toString:Pe});function Be(){return this.rgb().formatHex()}function Li(){return this.rgb().formatHex8()}function Bi(){return Nn(this).formatHsl()}function Pe(){return this.rgb().formatRgb()}function Ct(t){var e,n;return t=(t+"").trim().toLowerCase(),(e=Ci.exec(t))?(n=e[1].length,e=parseInt(e[1],16),n===6?Xe(e):n===3?new Y(e>>8&15|e>>4&240,e>>4&15|e&240,(e&15)<<4|e&15,1):n===8?Lt(e>>24&255,e>>16&255,e>>8&255,(e&255)/255):n===4?Lt(e>>12&15|e>>8&240,e>>8&15|e>>4&240,e>>4&15|e&240,((e&15)<<4|e&15)/255):null):(e=Ti.exec(t))?new Y(e[1],e[2],e[3],1):(e=Si.exec(t))?new Y(e[1]*255/100,e[2]*255/100,e[3]*255/100,1):(e=zi.exec(t))?Lt(e[1],e[2],e[3],e[4]):(e=Ii.exec(t))?Lt(e[1]*255/100,e[2]*255/100,e[3]*255/100,e[4]):(e=Di.exec(t))?Oe(e[1],e[2]/100,e[3]/100,1):(e=Ri.exec(t))?Oe(e[1],e[2]/100,e[3]/100,e[4]):Le.hasOwnProperty(t)?Xe(Le[t]):t==="transparent"?new Y(NaN,NaN,NaN,0):null}function Xe(t){return new Y(t>>16&255,t>>8&255,t&255,1)}function Lt(t,e,n,r){return r<=0&&(t=e=n=NaN),new Y(t,e,n,r)}function Pi(t){return t instanceof Dt||(t=Ct(t)),t?(t=t.rgb(),new Y(t.r,t.g,t.b,t.opacity)):new Y}function de(t,e,n,r){return arguments.length===1?Pi(t):new Y(t,e,n,r??1)}function Y(t,e,n,r){this.r=+t,this.g=+e,this.b=+n,this.opacity=+r}ke(Y,de,En(Dt,{brighter(t){return t=t==null?Qt:Math.pow(Qt,t),new Y(this.r*t,this.g*t,this.b*t,this.opacity)},darker(t){return t=t==null?$t:Math.pow($t,t),new Y(this.r*t,this.g*t,this.b*t,this.opacity)},rgb(){return this},clamp(){return new Y(dt(this.r),dt(this.g),dt(this.b),Wt(this.opacity))},displayable(){return-.5<=this.r&&this.r<255.5&&-.5<=this.g&&this.g<255.5&&-.5<=this.b&&this.b<255.5&&0<=this.opacity&&this.opacity<=1},hex:He,formatHex:He,formatHex8:Xi,formatRgb:Fe,toString:Fe}));function He(){return`#${ht(this.r)}${ht(this.g)}${ht(this.b)}`}function Xi(){return`#${ht(this.r)}${ht(this.g)}${ht(this.b)}${ht((isNaN(this.opacity)?1:this.opacity)*255)}`}function Fe(){const t=Wt(this.opacity);return`${t===1?"rgb(":"rgba("}${dt(this.r)}, ${dt(this.g)}, ${dt(this.b)}${t===1?")":`, ${t})`}`}function Wt(t){return isNaN(t)?1:Math.max(0,Math.min(1,t))}function dt(t){return Math.max(0,Math.min(255,Math.round(t)||0))}function ht(t){return t=dt(t),(t<16?"0":"")+t.toString(16)}function Oe(t,e,n,r){return r<=0?t=e=n=NaN:n<=0||n>=1?t=e=NaN:e<=0&&(t=NaN),new K(t,e,n,r)}function Nn(t){if(t instanceof K)return new K(t.h,t.s,t.l,t.opacity);if(t instanceof Dt||(t=Ct(t)),!t)return new K;if(t instanceof K)return t;t=t.rgb();var e=t.r/255,n=t.g/255,r=t.b/255,i=Math.min(e,n,r),o=Math.max(e,n,r),a=NaN,s=o-i,c=(o+i)/2;return s?(e===o?a=(n-r)/s+(n<r)*6:n===o?a=(r-e)/s+2:a=(e-n)/s+4,s/=c<.5?o+i:2-o-i,a*=60):s=c>0&&c<1?0:a,new K(a,s,c,t.opacity)}function Hi(t,e,n,r){return arguments.length===1?Nn(t):new K(t,e,n,r??1)}function K(t,e,n,r){this.h=+t,this.s=+e,this.l=+n,this.opacity=+r}ke(K,Hi,En(Dt,{brighter(t){return t=t==null?Qt:Math.pow(Qt,t),new K(this.h,this.s,this.l*t,this.opacity)},darker(t){return t=t==null?$t:Math.pow($t,t),new K(this.h,this.s,this.l*t,this.opacity)},rgb(){var t=this.h%360+(this.h<0)*360,e=isNaN(t)||isNaN(this.s)?0:this.s,n=this.l,r=n+(n<.5?n:1-n)*e,i=2*n-r;return new Y(se(t>=240?t-240:t+120,i,r),se(t,i,r),se(t<120?t+240:t-120,i,r),this.opacity)},clamp(){return new K(Ye(this.h),Bt(this.s),Bt(this.l),Wt(this.opacity))},displayable(){return(0<=this.s&&this.s<=1||isNaN(this.s))&&0<=this.l&&this.l<=1&&0<=this.opacity&&this.opacity<=1},formatHsl(){const t=Wt(this.opacity);return`${t===1?"hsl(":"hsla("}${Ye(this.h)}, ${Bt(this.s)*100}%, ${Bt(this.l)*100}%${t===1?")":`, ${t})`}`}}));function Ye(t){return t=(t||0)%360,t<0?t+360:t}function Bt(t){return Math.max(0,Math.min(1,t||0))}function se(t,e,n){return(t<60?e+(n-e)*t/60:t<180?n:t<240?e+(n-e)*(240-t)/60:e)*255}const kn=t=>()=>t;function Fi(t,e){return function(n){return t+n*e}}function Oi(t,e,n){return t=Math.pow(t,n),e=Math.pow(e,n)-t,n=1/n,function(r){return Math.pow(t+r*e,n)}}function Yi(t){return(t=+t)==1?Mn:function(e,n){return n-e?Oi(e,n,t):kn(isNaN(e)?n:e)}}function Mn(t,e){var n=e-t;return n?Fi(t,n):kn(isNaN(t)?e:t)}const qe=(function t(e){var n=Yi(e);function r(i,o){var a=n((i=de(i)).r,(o=de(o)).r),s=n(i.g,o.g),c=n(i.b,o.b),u=Mn(i.opacity,o.opacity);return function(l){return i.r=a(l),i.g=s(l),i.b=c(l),i.opacity=u(l),i+""}}return r.gamma=t,r})(1);function ut(t,e){return t=+t,e=+e,function(n){return t*(1-n)+e*n}}var ge=/[-+]?(?:\d+\.?\d*|\.?\d+)(?:[eE][-+]?\d+)?/g,ue=new RegExp(ge.source,"g");function qi(t){return function(){return t}}function Vi(t){return function(e){return t(e)+""}}function Ui(t,e){var n=ge.lastIndex=ue.lastIndex=0,r,i,o,a=-1,s=[],c=[];for(t=t+"",e=e+"";(r=ge.exec(t))&&(i=ue.exec(e));)(o=i.index)>n&&(o=e.slice(n,o),s[a]?s[a]+=o:s[++a]=o),(r=r[0])===(i=i[0])?s[a]?s[a]+=i:s[++a]=i:(s[++a]=null,c.push({i:a,x:ut(r,i)})),n=ue.lastIndex;return n<e.length&&(o=e.slice(n),s[a]?s[a]+=o:s[++a]=o),s.length<2?c[0]?Vi(c[0].x):qi(e):(e=c.length,function(u){for(var l=0,y;l<e;++l)s[(y=c[l]).i]=y.x(u);return s.join("")})}var Ve=180/Math.PI,pe={translateX:0,translateY:0,rotate:0,skewX:0,scaleX:1,scaleY:1};function $n(t,e,n,r,i,o){var a,s,c;return(a=Math.sqrt(t*t+e*e))&&(t/=a,e/=a),(c=t*n+e*r)&&(n-=t*c,r-=e*c),(s=Math.sqrt(n*n+r*r))&&(n/=s,r/=s,c/=s),t*r<e*n&&(t=-t,e=-e,c=-c,a=-a),{translateX:i,translateY:o,rotate:Math.atan2(e,t)*Ve,skewX:Math.atan(c)*Ve,scaleX:a,scaleY:s}}var Pt;function Gi(t){const e=new(typeof DOMMatrix=="function"?DOMMatrix:WebKitCSSMatrix)(t+"");return e.isIdentity?pe:$n(e.a,e.b,e.c,e.d,e.e,e.f)}function Ki(t){return t==null||(Pt||(Pt=document.createElementNS("http://www.w3.org/2000/svg","g")),Pt.setAttribute("transform",t),!(t=Pt.transform.baseVal.consolidate()))?pe:(t=t.matrix,$n(t.a,t.b,t.c,t.d,t.e,t.f))}function An(t,e,n,r){function i(u){return u.length?u.pop()+" ":""}function o(u,l,y,f,h,_){if(u!==y||l!==f){var v=h.push("translate(",null,e,null,n);_.push({i:v-4,x:ut(u,y)},{i:v-2,x:ut(l,f)})}else(y||f)&&h.push("translate("+y+e+f+n)}function a(u,l,y,f){u!==l?(u-l>180?l+=360:l-u>180&&(u+=360),f.push({i:y.push(i(y)+"rotate(",null,r)-2,x:ut(u,l)})):l&&y.push(i(y)+"rotate("+l+r)}function s(u,l,y,f){u!==l?f.push({i:y.push(i(y)+"skewX(",null,r)-2,x:ut(u,l)}):l&&y.push(i(y)+"skewX("+l+r)}function c(u,l,y,f,h,_){if(u!==y||l!==f){var v=h.push(i(h)+"scale(",null,",",null,")");_.push({i:v-4,x:ut(u,y)},{i:v-2,x:ut(l,f)})}else(y!==1||f!==1)&&h.push(i(h)+"scale("+y+","+f+")")}return function(u,l){var y=[],f=[];return u=t(u),l=t(l),o(u.translateX,u.translateY,l.translateX,l.translateY,y,f),a(u.rotate,l.rotate,y,f),s(u.skewX,l.skewX,y,f),c(u.scaleX,u.scaleY,l.scaleX,l.scaleY,y,f),u=l=null,function(h){for(var _=-1,v=f.length,m;++_<v;)y[(m=f[_]).i]=m.x(h);return y.join("")}}}var Qi=An(Gi,"px, ","px)","deg)"),Wi=An(Ki,", ",")",")"),Zi=1e-12;function Ue(t){return((t=Math.exp(t))+1/t)/2}function Ji(t){return((t=Math.exp(t))-1/t)/2}function ji(t){return((t=Math.exp(2*t))-1)/(t+1)}const to=(function t(e,n,r){function i(o,a){var s=o[0],c=o[1],u=o[2],l=a[0],y=a[1],f=a[2],h=l-s,_=y-c,v=h*h+_*_,m,p;if(v<Zi)p=Math.log(f/u)/e,m=function(A){return[s+A*h,c+A*_,u*Math.exp(e*A*p)]};else{var w=Math.sqrt(v),k=(f*f-u*u+r*v)/(2*u*n*w),g=(f*f-u*u-r*v)/(2*f*n*w),N=Math.log(Math.sqrt(k*k+1)-k),M=Math.log(Math.sqrt(g*g+1)-g);p=(M-N)/e,m=function(A){var S=A*p,D=Ue(N),z=u/(n*w)*(D*ji(e*S+N)-Ji(N));return[s+z*h,c+z*_,u*D/Ue(e*S+N)]}}return m.duration=p*1e3*e/Math.SQRT2,m}return i.rho=function(o){var a=Math.max(.001,+o),s=a*a,c=s*s;return t(a,s,c)},i})(Math.SQRT2,2,4);var _t=0,Nt=0,wt=0,Cn=1e3,Zt,kt,Jt=0,pt=0,ee=0,Tt=typeof performance=="object"&&performance.now?performance:Date,Tn=typeof window=="object"&&window.requestAnimationFrame?window.requestAnimationFrame.bind(window):function(t){setTimeout(t,17)};function Me(){return pt||(Tn(eo),pt=Tt.now()+ee)}function eo(){pt=0}function jt(){this._call=this._time=this._next=null}jt.prototype=ne.prototype={constructor:jt,restart:function(t,e,n){if(typeof t!="function")throw new TypeError("callback is not a function");n=(n==null?Me():+n)+(e==null?0:+e),!this._next&&kt!==this&&(kt?kt._next=this:Zt=this,kt=this),this._call=t,this._time=n,ye()},stop:function(){this._call&&(this._call=null,this._time=1/0,ye())}};function ne(t,e,n){var r=new jt;return r.restart(t,e,n),r}function no(){Me(),++_t;for(var t=Zt,e;t;)(e=pt-t._time)>=0&&t._call.call(void 0,e),t=t._next;--_t}function Ge(){pt=(Jt=Tt.now())+ee,_t=Nt=0;try{no()}finally{_t=0,io(),pt=0}}function ro(){var t=Tt.now(),e=t-Jt;e>Cn&&(ee-=e,Jt=t)}function io(){for(var t,e=Zt,n,r=1/0;e;)e._call?(r>e._time&&(r=e._time),t=e,e=e._next):(n=e._next,e._next=null,e=t?t._next=n:Zt=n);kt=t,ye(r)}function ye(t){if(!_t){Nt&&(Nt=clearTimeout(Nt));var e=t-pt;e>24?(t<1/0&&(Nt=setTimeout(Ge,t-Tt.now()-ee)),wt&&(wt=clearInterval(wt))):(wt||(Jt=Tt.now(),wt=setInterval(ro,Cn)),_t=1,Tn(Ge))}}function Ke(t,e,n){var r=new jt;return e=e==null?0:+e,r.restart(i=>{r.stop(),t(i+e)},e,n),r}var oo=zt("start","end","cancel","interrupt"),ao=[],Sn=0,Qe=1,me=2,qt=3,We=4,ve=5,Vt=6;function re(t,e,n,r,i,o){var a=t.__transition;if(!a)t.__transition={};else if(n in a)return;so(t,n,{name:e,index:r,group:i,on:oo,tween:ao,time:o.time,delay:o.delay,duration:o.duration,ease:o.ease,timer:null,state:Sn})}function $e(t,e){var n=W(t,e);if(n.state>Sn)throw new Error("too late; already scheduled");return n}function et(t,e){var n=W(t,e);if(n.state>qt)throw new Error("too late; already running");return n}function W(t,e){var n=t.__transition;if(!n||!(n=n[e]))throw new Error("transition not found");return n}function so(t,e,n){var r=t.__transition,i;r[e]=n,n.timer=ne(o,0,n.time);function o(u){n.state=Qe,n.timer.restart(a,n.delay,n.time),n.delay<=u&&a(u-n.delay)}function a(u){var l,y,f,h;if(n.state!==Qe)return c();for(l in r)if(h=r[l],h.name===n.name){if(h.state===qt)return Ke(a);h.state===We?(h.state=Vt,h.timer.stop(),h.on.call("interrupt",t,t.__data__,h.index,h.group),delete r[l]):+l<e&&(h.state=Vt,h.timer.stop(),h.on.call("cancel",t,t.__data__,h.index,h.group),delete r[l])}if(Ke(function(){n.state===qt&&(n.state=We,n.timer.restart(s,n.delay,n.time),s(u))}),n.state=me,n.on.call("start",t,t.__data__,n.index,n.group),n.state===me){for(n.state=qt,i=new Array(f=n.tween.length),l=0,y=-1;l<f;++l)(h=n.tween[l].value.call(t,t.__data__,n.index,n.group))&&(i[++y]=h);i.length=y+1}}function s(u){for(var l=u<n.duration?n.ease.call(null,u/n.duration):(n.timer.restart(c),n.state=ve,1),y=-1,f=i.length;++y<f;)i[y].call(t,l);n.state===ve&&(n.on.call("end",t,t.__data__,n.index,n.group),c())}function c(){n.state=Vt,n.timer.stop(),delete r[e];for(var u in r)return;delete t.__transition}}function Ut(t,e){var n=t.__transition,r,i,o=!0,a;if(n){e=e==null?null:e+"";for(a in n){if((r=n[a]).name!==e){o=!1;continue}i=r.state>me&&r.state<ve,r.state=Vt,r.timer.stop(),r.on.call(i?"interrupt":"cancel",t,t.__data__,r.index,r.group),delete n[a]}o&&delete t.__transition}}function uo(t){return this.each(function(){Ut(this,t)})}function co(t,e){var n,r;return function(){var i=et(this,t),o=i.tween;if(o!==n){r=n=o;for(var a=0,s=r.length;a<s;++a)if(r[a].name===e){r=r.slice(),r.splice(a,1);break}}i.tween=r}}function lo(t,e,n){var r,i;if(typeof n!="function")throw new Error;return function(){var o=et(this,t),a=o.tween;if(a!==r){i=(r=a).slice();for(var s={name:e,value:n},c=0,u=i.length;c<u;++c)if(i[c].name===e){i[c]=s;break}c===u&&i.push(s)}o.tween=i}}function fo(t,e){var n=this._id;if(t+="",arguments.length<2){for(var r=W(this.node(),n).tween,i=0,o=r.length,a;i<o;++i)if((a=r[i]).name===t)return a.value;return null}return this.each((e==null?co:lo)(n,t,e))}function Ae(t,e,n){var r=t._id;return t.each(function(){var i=et(this,r);(i.value||(i.value={}))[e]=n.apply(this,arguments)}),function(i){return W(i,r).value[e]}}function zn(t,e){var n;return(typeof e=="number"?ut:e instanceof Ct?qe:(n=Ct(e))?(e=n,qe):Ui)(t,e)}function ho(t){return function(){this.removeAttribute(t)}}function go(t){return function(){this.removeAttributeNS(t.space,t.local)}}function po(t,e,n){var r,i=n+"",o;return function(){var a=this.getAttribute(t);return a===i?null:a===r?o:o=e(r=a,n)}}function yo(t,e,n){var r,i=n+"",o;return function(){var a=this.getAttributeNS(t.space,t.local);return a===i?null:a===r?o:o=e(r=a,n)}}function mo(t,e,n){var r,i,o;return function(){var a,s=n(this),c;return s==null?void this.removeAttribute(t):(a=this.getAttribute(t),c=s+"",a===c?null:a===r&&c===i?o:(i=c,o=e(r=a,s)))}}function vo(t,e,n){var r,i,o;return function(){var a,s=n(this),c;return s==null?void this.removeAttributeNS(t.space,t.local):(a=this.getAttributeNS(t.space,t.local),c=s+"",a===c?null:a===r&&c===i?o:(i=c,o=e(r=a,s)))}}function xo(t,e){var n=te(t),r=n==="transform"?Wi:zn;return this.attrTween(t,typeof e=="function"?(n.local?vo:mo)(n,r,Ae(this,"attr."+t,e)):e==null?(n.local?go:ho)(n):(n.local?yo:po)(n,r,e))}function _o(t,e){return function(n){this.setAttribute(t,e.call(this,n))}}function wo(t,e){return function(n){this.setAttributeNS(t.space,t.local,e.call(this,n))}}function bo(t,e){var n,r;function i(){var o=e.apply(this,arguments);return o!==r&&(n=(r=o)&&wo(t,o)),n}return i._value=e,i}function Eo(t,e){var n,r;function i(){var o=e.apply(this,arguments);return o!==r&&(n=(r=o)&&_o(t,o)),n}return i._value=e,i}function No(t,e){var n="attr."+t;if(arguments.length<2)return(n=this.tween(n))&&n._value;if(e==null)return this.tween(n,null);if(typeof e!="function")throw new Error;var r=te(t);return this.tween(n,(r.local?bo:Eo)(r,e))}function ko(t,e){return function(){$e(this,t).delay=+e.apply(this,arguments)}}function Mo(t,e){return e=+e,function(){$e(this,t).delay=e}}function $o(t){var e=this._id;return arguments.length?this.each((typeof t=="function"?ko:Mo)(e,t)):W(this.node(),e).delay}function Ao(t,e){return function(){et(this,t).duration=+e.apply(this,arguments)}}function Co(t,e){return e=+e,function(){et(this,t).duration=e}}function To(t){var e=this._id;return arguments.length?this.each((typeof t=="function"?Ao:Co)(e,t)):W(this.node(),e).duration}function So(t,e){if(typeof e!="function")throw new Error;return function(){et(this,t).ease=e}}function zo(t){var e=this._id;return arguments.length?this.each(So(e,t)):W(this.node(),e).ease}function Io(t,e){return function(){var n=e.apply(this,arguments);if(typeof n!="function")throw new Error;et(this,t).ease=n}}function Do(t){if(typeof t!="function")throw new Error;return this.each(Io(this._id,t))}function Ro(t){typeof t!="function"&&(t=fn(t));for(var e=this._groups,n=e.length,r=new Array(n),i=0;i<n;++i)for(var o=e[i],a=o.length,s=r[i]=[],c,u=0;u<a;++u)(c=o[u])&&t.call(c,c.__data__,u,o)&&s.push(c);return new st(r,this._parents,this._name,this._id)}function Lo(t){if(t._id!==this._id)throw new Error;for(var e=this._groups,n=t._groups,r=e.length,i=n.length,o=Math.min(r,i),a=new Array(r),s=0;s<o;++s)for(var c=e[s],u=n[s],l=c.length,y=a[s]=new Array(l),f,h=0;h<l;++h)(f=c[h]||u[h])&&(y[h]=f);for(;s<r;++s)a[s]=e[s];return new st(a,this._parents,this._name,this._id)}function Bo(t){return(t+"").trim().split(/^|\s+/).every(function(e){var n=e.indexOf(".");return n>=0&&(e=e.slice(0,n)),!e||e==="start"})}function Po(t,e,n){var r,i,o=Bo(e)?$e:et;return function(){var a=o(this,t),s=a.on;s!==r&&(i=(r=s).copy()).on(e,n),a.on=i}}function Xo(t,e){var n=this._id;return arguments.length<2?W(this.node(),n).on.on(t):this.each(Po(n,t,e))}function Ho(t){return function(){var e=this.parentNode;for(var n in this.__transition)if(+n!==t)return;e&&e.removeChild(this)}}function Fo(){return this.on("end.remove",Ho(this._id))}function Oo(t){var e=this._name,n=this._id;typeof t!="function"&&(t=Ee(t));for(var r=this._groups,i=r.length,o=new Array(i),a=0;a<i;++a)for(var s=r[a],c=s.length,u=o[a]=new Array(c),l,y,f=0;f<c;++f)(l=s[f])&&(y=t.call(l,l.__data__,f,s))&&("__data__"in l&&(y.__data__=l.__data__),u[f]=y,re(u[f],e,n,f,u,W(l,n)));return new st(o,this._parents,e,n)}function Yo(t){var e=this._name,n=this._id;typeof t!="function"&&(t=ln(t));for(var r=this._groups,i=r.length,o=[],a=[],s=0;s<i;++s)for(var c=r[s],u=c.length,l,y=0;y<u;++y)if(l=c[y]){for(var f=t.call(l,l.__data__,y,c),h,_=W(l,n),v=0,m=f.length;v<m;++v)(h=f[v])&&re(h,e,n,v,f,_);o.push(f),a.push(l)}return new st(o,a,e,n)}var qo=It.prototype.constructor;function Vo(){return new qo(this._groups,this._parents)}function Uo(t,e){var n,r,i;return function(){var o=xt(this,t),a=(this.style.removeProperty(t),xt(this,t));return o===a?null:o===n&&a===r?i:i=e(n=o,r=a)}}function In(t){return function(){this.style.removeProperty(t)}}function Go(t,e,n){var r,i=n+"",o;return function(){var a=xt(this,t);return a===i?null:a===r?o:o=e(r=a,n)}}function Ko(t,e,n){var r,i,o;return function(){var a=xt(this,t),s=n(this),c=s+"";return s==null&&(c=s=(this.style.removeProperty(t),xt(this,t))),a===c?null:a===r&&c===i?o:(i=c,o=e(r=a,s))}}function Qo(t,e){var n,r,i,o="style."+e,a="end."+o,s;return function(){var c=et(this,t),u=c.on,l=c.value[o]==null?s||(s=In(e)):void 0;(u!==n||i!==l)&&(r=(n=u).copy()).on(a,i=l),c.on=r}}function Wo(t,e,n){var r=(t+="")=="transform"?Qi:zn;return e==null?this.styleTween(t,Uo(t,r)).on("end.style."+t,In(t)):typeof e=="function"?this.styleTween(t,Ko(t,r,Ae(this,"style."+t,e))).each(Qo(this._id,t)):this.styleTween(t,Go(t,r,e),n).on("end.style."+t,null)}function Zo(t,e,n){return function(r){this.style.setProperty(t,e.call(this,r),n)}}function Jo(t,e,n){var r,i;function o(){var a=e.apply(this,arguments);return a!==i&&(r=(i=a)&&Zo(t,a,n)),r}return o._value=e,o}function jo(t,e,n){var r="style."+(t+="");if(arguments.length<2)return(r=this.tween(r))&&r._value;if(e==null)return this.tween(r,null);if(typeof e!="function")throw new Error;return this.tween(r,Jo(t,e,n??""))}function ta(t){return function(){this.textContent=t}}function ea(t){return function(){var e=t(this);this.textContent=e??""}}function na(t){return this.tween("text",typeof t=="function"?ea(Ae(this,"text",t)):ta(t==null?"":t+""))}function ra(t){return function(e){this.textContent=t.call(this,e)}}function ia(t){var e,n;function r(){var i=t.apply(this,arguments);return i!==n&&(e=(n=i)&&ra(i)),e}return r._value=t,r}function oa(t){var e="text";if(arguments.length<1)return(e=this.tween(e))&&e._value;if(t==null)return this.tween(e,null);if(typeof t!="function")throw new Error;return this.tween(e,ia(t))}function aa(){for(var t=this._name,e=this._id,n=Dn(),r=this._groups,i=r.length,o=0;o<i;++o)for(var a=r[o],s=a.length,c,u=0;u<s;++u)if(c=a[u]){var l=W(c,e);re(c,t,n,u,a,{time:l.time+l.delay+l.duration,delay:0,duration:l.duration,ease:l.ease})}return new st(r,this._parents,t,n)}function sa(){var t,e,n=this,r=n._id,i=n.size();return new Promise(function(o,a){var s={value:a},c={value:function(){--i===0&&o()}};n.each(function(){var u=et(this,r),l=u.on;l!==t&&(e=(t=l).copy(),e._.cancel.push(s),e._.interrupt.push(s),e._.end.push(c)),u.on=e}),i===0&&o()})}var ua=0;function st(t,e,n,r){this._groups=t,this._parents=e,this._name=n,this._id=r}function Dn(){return++ua}var it=It.prototype;st.prototype={constructor:st,select:Oo,selectAll:Yo,selectChild:it.selectChild,selectChildren:it.selectChildren,filter:Ro,merge:Lo,selection:Vo,transition:aa,call:it.call,nodes:it.nodes,node:it.node,size:it.size,empty:it.empty,each:it.each,on:Xo,attr:xo,attrTween:No,style:Wo,styleTween:jo,text:na,textTween:oa,remove:Fo,tween:fo,delay:$o,duration:To,ease:zo,easeVarying:Do,end:sa,[Symbol.iterator]:it[Symbol.iterator]};function ca(t){return((t*=2)<=1?t*t*t:(t-=2)*t*t+2)/2}var la={time:null,delay:0,duration:250,ease:ca};function fa(t,e){for(var n;!(n=t.__transition)||!(n=n[e]);)if(!(t=t.parentNode))throw new Error(`transition ${e} not found`);return n}function ha(t){var e,n;t instanceof st?(e=t._id,t=t._name):(e=Dn(),(n=la).time=Me(),t=t==null?null:t+"");for(var r=this._groups,i=r.length,o=0;o<i;++o)for(var a=r[o],s=a.length,c,u=0;u<s;++u)(c=a[u])&&re(c,t,e,u,a,n||fa(c,e));return new st(r,this._parents,t,e)}It.prototype.interrupt=uo;It.prototype.transition=ha;function Ze(t,e){var n,r=1;t==null&&(t=0),e==null&&(e=0);function i(){var o,a=n.length,s,c=0,u=0;for(o=0;o<a;++o)s=n[o],c+=s.x,u+=s.y;for(c=(c/a-t)*r,u=(u/a-e)*r,o=0;o<a;++o)s=n[o],s.x-=c,s.y-=u}return i.initialize=function(o){n=o},i.x=function(o){return arguments.length?(t=+o,i):t},i.y=function(o){return arguments.length?(e=+o,i):e},i.strength=function(o){return arguments.length?(r=+o,i):r},i}function da(t){const e=+this._x.call(null,t),n=+this._y.call(null,t);return Rn(this.cover(e,n),e,n,t)}function Rn(t,e,n,r){if(isNaN(e)||isNaN(n))return t;var i,o=t._root,a={data:r},s=t._x0,c=t._y0,u=t._x1,l=t._y1,y,f,h,_,v,m,p,w;if(!o)return t._root=a,t;for(;o.length;)if((v=e>=(y=(s+u)/2))?s=y:u=y,(m=n>=(f=(c+l)/2))?c=f:l=f,i=o,!(o=o[p=m<<1|v]))return i[p]=a,t;if(h=+t._x.call(null,o.data),_=+t._y.call(null,o.data),e===h&&n===_)return a.next=o,i?i[p]=a:t._root=a,t;do i=i?i[p]=new Array(4):t._root=new Array(4),(v=e>=(y=(s+u)/2))?s=y:u=y,(m=n>=(f=(c+l)/2))?c=f:l=f;while((p=m<<1|v)===(w=(_>=f)<<1|h>=y));return i[w]=o,i[p]=a,t}function ga(t){var e,n,r=t.length,i,o,a=new Array(r),s=new Array(r),c=1/0,u=1/0,l=-1/0,y=-1/0;for(n=0;n<r;++n)isNaN(i=+this._x.call(null,e=t[n]))||isNaN(o=+this._y.call(null,e))||(a[n]=i,s[n]=o,i<c&&(c=i),i>l&&(l=i),o<u&&(u=o),o>y&&(y=o));if(c>l||u>y)return this;for(this.cover(c,u).cover(l,y),n=0;n<r;++n)Rn(this,a[n],s[n],t[n]);return this}function pa(t,e){if(isNaN(t=+t)||isNaN(e=+e))return this;var n=this._x0,r=this._y0,i=this._x1,o=this._y1;if(isNaN(n))i=(n=Math.floor(t))+1,o=(r=Math.floor(e))+1;else{for(var a=i-n||1,s=this._root,c,u;n>t||t>=i||r>e||e>=o;)switch(u=(e<r)<<1|t<n,c=new Array(4),c[u]=s,s=c,a*=2,u){case 0:i=n+a,o=r+a;break;case 1:n=i-a,o=r+a;break;case 2:i=n+a,r=o-a;break;case 3:n=i-a,r=o-a;break}this._root&&this._root.length&&(this._root=s)}return this._x0=n,this._y0=r,this._x1=i,this._y1=o,this}function ya(){var t=[];return this.visit(function(e){if(!e.length)do t.push(e.data);while(e=e.next)}),t}function ma(t){return arguments.length?this.cover(+t[0][0],+t[0][1]).cover(+t[1][0],+t[1][1]):isNaN(this._x0)?void 0:[[this._x0,this._y0],[this._x1,this._y1]]}function X(t,e,n,r,i){this.node=t,this.x0=e,this.y0=n,this.x1=r,this.y1=i}function va(t,e,n){var r,i=this._x0,o=this._y0,a,s,c,u,l=this._x1,y=this._y1,f=[],h=this._root,_,v;for(h&&f.push(new X(h,i,o,l,y)),n==null?n=1/0:(i=t-n,o=e-n,l=t+n,y=e+n,n*=n);_=f.pop();)if(!(!(h=_.node)||(a=_.x0)>l||(s=_.y0)>y||(c=_.x1)<i||(u=_.y1)<o))if(h.length){var m=(a+c)/2,p=(s+u)/2;f.push(new X(h[3],m,p,c,u),new X(h[2],a,p,m,u),new X(h[1],m,s,c,p),new X(h[0],a,s,m,p)),(v=(e>=p)<<1|t>=m)&&(_=f[f.length-1],f[f.length-1]=f[f.length-1-v],f[f.length-1-v]=_)}else{var w=t-+this._x.call(null,h.data),k=e-+this._y.call(null,h.data),g=w*w+k*k;if(g<n){var N=Math.sqrt(n=g);i=t-N,o=e-N,l=t+N,y=e+N,r=h.data}}return r}function xa(t){if(isNaN(l=+this._x.call(null,t))||isNaN(y=+this._y.call(null,t)))return this;var e,n=this._root,r,i,o,a=this._x0,s=this._y0,c=this._x1,u=this._y1,l,y,f,h,_,v,m,p;if(!n)return this;if(n.length)for(;;){if((_=l>=(f=(a+c)/2))?a=f:c=f,(v=y>=(h=(s+u)/2))?s=h:u=h,e=n,!(n=n[m=v<<1|_]))return this;if(!n.length)break;(e[m+1&3]||e[m+2&3]||e[m+3&3])&&(r=e,p=m)}for(;n.data!==t;)if(i=n,!(n=n.next))return this;return(o=n.next)&&delete n.next,i?(o?i.next=o:delete i.next,this):e?(o?e[m]=o:delete e[m],(n=e[0]||e[1]||e[2]||e[3])&&n===(e[3]||e[2]||e[1]||e[0])&&!n.length&&(r?r[p]=n:this._root=n),this):(this._root=o,this)}function _a(t){for(var e=0,n=t.length;e<n;++e)this.remove(t[e]);return this}function wa(){return this._root}function ba(){var t=0;return this.visit(function(e){if(!e.length)do++t;while(e=e.next)}),t}function Ea(t){var e=[],n,r=this._root,i,o,a,s,c;for(r&&e.push(new X(r,this._x0,this._y0,this._x1,this._y1));n=e.pop();)if(!t(r=n.node,o=n.x0,a=n.y0,s=n.x1,c=n.y1)&&r.length){var u=(o+s)/2,l=(a+c)/2;(i=r[3])&&e.push(new X(i,u,l,s,c)),(i=r[2])&&e.push(new X(i,o,l,u,c)),(i=r[1])&&e.push(new X(i,u,a,s,l)),(i=r[0])&&e.push(new X(i,o,a,u,l))}return this}function Na(t){var e=[],n=[],r;for(this._root&&e.push(new X(this._root,this._x0,this._y0,this._x1,this._y1));r=e.pop();){var i=r.node;if(i.length){var o,a=r.x0,s=r.y0,c=r.x1,u=r.y1,l=(a+c)/2,y=(s+u)/2;(o=i[0])&&e.push(new X(o,a,s,l,y)),(o=i[1])&&e.push(new X(o,l,s,c,y)),(o=i[2])&&e.push(new X(o,a,y,l,u)),(o=i[3])&&e.push(new X(o,l,y,c,u))}n.push(r)}for(;r=n.pop();)t(r.node,r.x0,r.y0,r.x1,r.y1);return this}function ka(t){return t[0]}function Ma(t){return arguments.length?(this._x=t,this):this._x}function $a(t){return t[1]}function Aa(t){return arguments.length?(this._y=t,this):this._y}function Ce(t,e,n){var r=new Te(e??ka,n??$a,NaN,NaN,NaN,NaN);return t==null?r:r.addAll(t)}function Te(t,e,n,r,i,o){this._x=t,this._y=e,this._x0=n,this._y0=r,this._x1=i,this._y1=o,this._root=void 0}function Je(t){for(var e={data:t.data},n=e;t=t.next;)n=n.next={data:t.data};return e}var H=Ce.prototype=Te.prototype;H.copy=function(){var t=new Te(this._x,this._y,this._x0,this._y0,this._x1,this._y1),e=this._root,n,r;if(!e)return t;if(!e.length)return t._root=Je(e),t;for(n=[{source:e,target:t._root=new Array(4)}];e=n.pop();)for(var i=0;i<4;++i)(r=e.source[i])&&(r.length?n.push({source:r,target:e.target[i]=new Array(4)}):e.target[i]=Je(r));return t};H.add=da;H.addAll=ga;H.cover=pa;H.data=ya;H.extent=ma;H.find=va;H.remove=xa;H.removeAll=_a;H.root=wa;H.size=ba;H.visit=Ea;H.visitAfter=Na;H.x=Ma;H.y=Aa;function gt(t){return function(){return t}}function ct(t){return(t()-.5)*1e-6}function Ca(t){return t.x+t.vx}function Ta(t){return t.y+t.vy}function Sa(t){var e,n,r,i=1,o=1;typeof t!="function"&&(t=gt(t==null?1:+t));function a(){for(var u,l=e.length,y,f,h,_,v,m,p=0;p<o;++p)for(y=Ce(e,Ca,Ta).visitAfter(s),u=0;u<l;++u)f=e[u],v=n[f.index],m=v*v,h=f.x+f.vx,_=f.y+f.vy,y.visit(w);function w(k,g,N,M,A){var S=k.data,D=k.r,z=v+D;if(S){if(S.index>f.index){var R=h-S.x-S.vx,q=_-S.y-S.vy,F=R*R+q*q;F<z*z&&(R===0&&(R=ct(r),F+=R*R),q===0&&(q=ct(r),F+=q*q),F=(z-(F=Math.sqrt(F)))/F*i,f.vx+=(R*=F)*(z=(D*=D)/(m+D)),f.vy+=(q*=F)*z,S.vx-=R*(z=1-z),S.vy-=q*z)}return}return g>h+z||M<h-z||N>_+z||A<_-z}}function s(u){if(u.data)return u.r=n[u.data.index];for(var l=u.r=0;l<4;++l)u[l]&&u[l].r>u.r&&(u.r=u[l].r)}function c(){if(e){var u,l=e.length,y;for(n=new Array(l),u=0;u<l;++u)y=e[u],n[y.index]=+t(y,u,e)}}return a.initialize=function(u,l){e=u,r=l,c()},a.iterations=function(u){return arguments.length?(o=+u,a):o},a.strength=function(u){return arguments.length?(i=+u,a):i},a.radius=function(u){return arguments.length?(t=typeof u=="function"?u:gt(+u),c(),a):t},a}function za(t){return t.index}function je(t,e){var n=t.get(e);if(!n)throw new Error("node not found: "+e);return n}function Ia(t){var e=za,n=y,r,i=gt(30),o,a,s,c,u,l=1;t==null&&(t=[]);function y(m){return 1/Math.min(s[m.source.index],s[m.target.index])}function f(m){for(var p=0,w=t.length;p<l;++p)for(var k=0,g,N,M,A,S,D,z;k<w;++k)g=t[k],N=g.source,M=g.target,A=M.x+M.vx-N.x-N.vx||ct(u),S=M.y+M.vy-N.y-N.vy||ct(u),D=Math.sqrt(A*A+S*S),D=(D-o[k])/D*m*r[k],A*=D,S*=D,M.vx-=A*(z=c[k]),M.vy-=S*z,N.vx+=A*(z=1-z),N.vy+=S*z}function h(){if(a){var m,p=a.length,w=t.length,k=new Map(a.map((N,M)=>[e(N,M,a),N])),g;for(m=0,s=new Array(p);m<w;++m)g=t[m],g.index=m,typeof g.source!="object"&&(g.source=je(k,g.source)),typeof g.target!="object"&&(g.target=je(k,g.target)),s[g.source.index]=(s[g.source.index]||0)+1,s[g.target.index]=(s[g.target.index]||0)+1;for(m=0,c=new Array(w);m<w;++m)g=t[m],c[m]=s[g.source.index]/(s[g.source.index]+s[g.target.index]);r=new Array(w),_(),o=new Array(w),v()}}function _(){if(a)for(var m=0,p=t.length;m<p;++m)r[m]=+n(t[m],m,t)}function v(){if(a)for(var m=0,p=t.length;m<p;++m)o[m]=+i(t[m],m,t)}return f.initialize=function(m,p){a=m,u=p,h()},f.links=function(m){return arguments.length?(t=m,h(),f):t},f.id=function(m){return arguments.length?(e=m,f):e},f.iterations=function(m){return arguments.length?(l=+m,f):l},f.strength=function(m){return arguments.length?(n=typeof m=="function"?m:gt(+m),_(),f):n},f.distance=function(m){return arguments.length?(i=typeof m=="function"?m:gt(+m),v(),f):i},f}const Da=1664525,Ra=1013904223,tn=4294967296;function La(){let t=1;return()=>(t=(Da*t+Ra)%tn)/tn}function Ba(t){return t.x}function Pa(t){return t.y}var Xa=10,Ha=Math.PI*(3-Math.sqrt(5));function Fa(t){var e,n=1,r=.001,i=1-Math.pow(r,1/300),o=0,a=.6,s=new Map,c=ne(y),u=zt("tick","end"),l=La();t==null&&(t=[]);function y(){f(),u.call("tick",e),n<r&&(c.stop(),u.call("end",e))}function f(v){var m,p=t.length,w;v===void 0&&(v=1);for(var k=0;k<v;++k)for(n+=(o-n)*i,s.forEach(function(g){g(n)}),m=0;m<p;++m)w=t[m],w.fx==null?w.x+=w.vx*=a:(w.x=w.fx,w.vx=0),w.fy==null?w.y+=w.vy*=a:(w.y=w.fy,w.vy=0);return e}function h(){for(var v=0,m=t.length,p;v<m;++v){if(p=t[v],p.index=v,p.fx!=null&&(p.x=p.fx),p.fy!=null&&(p.y=p.fy),isNaN(p.x)||isNaN(p.y)){var w=Xa*Math.sqrt(.5+v),k=v*Ha;p.x=w*Math.cos(k),p.y=w*Math.sin(k)}(isNaN(p.vx)||isNaN(p.vy))&&(p.vx=p.vy=0)}}function _(v){return v.initialize&&v.initialize(t,l),v}return h(),e={tick:f,restart:function(){return c.restart(y),e},stop:function(){return c.stop(),e},nodes:function(v){return arguments.length?(t=v,h(),s.forEach(_),e):t},alpha:function(v){return arguments.length?(n=+v,e):n},alphaMin:function(v){return arguments.length?(r=+v,e):r},alphaDecay:function(v){return arguments.length?(i=+v,e):+i},alphaTarget:function(v){return arguments.length?(o=+v,e):o},velocityDecay:function(v){return arguments.length?(a=1-v,e):1-a},randomSource:function(v){return arguments.length?(l=v,s.forEach(_),e):l},force:function(v,m){return arguments.length>1?(m==null?s.delete(v):s.set(v,_(m)),e):s.get(v)},find:function(v,m,p){var w=0,k=t.length,g,N,M,A,S;for(p==null?p=1/0:p*=p,w=0;w<k;++w)A=t[w],g=v-A.x,N=m-A.y,M=g*g+N*N,M<p&&(S=A,p=M);return S},on:function(v,m){return arguments.length>1?(u.on(v,m),e):u.on(v)}}}function Oa(){var t,e,n,r,i=gt(-30),o,a=1,s=1/0,c=.81;function u(h){var _,v=t.length,m=Ce(t,Ba,Pa).visitAfter(y);for(r=h,_=0;_<v;++_)e=t[_],m.visit(f)}function l(){if(t){var h,_=t.length,v;for(o=new Array(_),h=0;h<_;++h)v=t[h],o[v.index]=+i(v,h,t)}}function y(h){var _=0,v,m,p=0,w,k,g;if(h.length){for(w=k=g=0;g<4;++g)(v=h[g])&&(m=Math.abs(v.value))&&(_+=v.value,p+=m,w+=m*v.x,k+=m*v.y);h.x=w/p,h.y=k/p}else{v=h,v.x=v.data.x,v.y=v.data.y;do _+=o[v.data.index];while(v=v.next)}h.value=_}function f(h,_,v,m){if(!h.value)return!0;var p=h.x-e.x,w=h.y-e.y,k=m-_,g=p*p+w*w;if(k*k/c<g)return g<s&&(p===0&&(p=ct(n),g+=p*p),w===0&&(w=ct(n),g+=w*w),g<a&&(g=Math.sqrt(a*g)),e.vx+=p*h.value*r/g,e.vy+=w*h.value*r/g),!0;if(h.length||g>=s)return;(h.data!==e||h.next)&&(p===0&&(p=ct(n),g+=p*p),w===0&&(w=ct(n),g+=w*w),g<a&&(g=Math.sqrt(a*g)));do h.data!==e&&(k=o[h.data.index]*r/g,e.vx+=p*k,e.vy+=w*k);while(h=h.next)}return u.initialize=function(h,_){t=h,n=_,l()},u.strength=function(h){return arguments.length?(i=typeof h=="function"?h:gt(+h),l(),u):i},u.distanceMin=function(h){return arguments.length?(a=h*h,u):Math.sqrt(a)},u.distanceMax=function(h){return arguments.length?(s=h*h,u):Math.sqrt(s)},u.theta=function(h){return arguments.length?(c=h*h,u):Math.sqrt(c)},u}const Xt=t=>()=>t;function Ya(t,{sourceEvent:e,target:n,transform:r,dispatch:i}){Object.defineProperties(this,{type:{value:t,enumerable:!0,configurable:!0},sourceEvent:{value:e,enumerable:!0,configurable:!0},target:{value:n,enumerable:!0,configurable:!0},transform:{value:r,enumerable:!0,configurable:!0},_:{value:i}})}function at(t,e,n){this.k=t,this.x=e,this.y=n}at.prototype={constructor:at,scale:function(t){return t===1?this:new at(this.k*t,this.x,this.y)},translate:function(t,e){return t===0&e===0?this:new at(this.k,this.x+this.k*t,this.y+this.k*e)},apply:function(t){return[t[0]*this.k+this.x,t[1]*this.k+this.y]},applyX:function(t){return t*this.k+this.x},applyY:function(t){return t*this.k+this.y},invert:function(t){return[(t[0]-this.x)/this.k,(t[1]-this.y)/this.k]},invertX:function(t){return(t-this.x)/this.k},invertY:function(t){return(t-this.y)/this.k},rescaleX:function(t){return t.copy().domain(t.range().map(this.invertX,this).map(t.invert,t))},rescaleY:function(t){return t.copy().domain(t.range().map(this.invertY,this).map(t.invert,t))},toString:function(){return"translate("+this.x+","+this.y+") scale("+this.k+")"}};var ie=new at(1,0,0);at.prototype;function ce(t){t.stopImmediatePropagation()}function bt(t){t.preventDefault(),t.stopImmediatePropagation()}function qa(t){return(!t.ctrlKey||t.type==="wheel")&&!t.button}function Va(){var t=this;return t instanceof SVGElement?(t=t.ownerSVGElement||t,t.hasAttribute("viewBox")?(t=t.viewBox.baseVal,[[t.x,t.y],[t.x+t.width,t.y+t.height]]):[[0,0],[t.width.baseVal.value,t.height.baseVal.value]]):[[0,0],[t.clientWidth,t.clientHeight]]}function en(){return this.__zoom||ie}function Ua(t){return-t.deltaY*(t.deltaMode===1?.05:t.deltaMode?1:.002)*(t.ctrlKey?10:1)}function Ga(){return navigator.maxTouchPoints||"ontouchstart"in this}function Ka(t,e,n){var r=t.invertX(e[0][0])-n[0][0],i=t.invertX(e[1][0])-n[1][0],o=t.invertY(e[0][1])-n[0][1],a=t.invertY(e[1][1])-n[1][1];return t.translate(i>r?(r+i)/2:Math.min(0,r)||Math.max(0,i),a>o?(o+a)/2:Math.min(0,o)||Math.max(0,a))}function Qa(){var t=qa,e=Va,n=Ka,r=Ua,i=Ga,o=[0,1/0],a=[[-1/0,-1/0],[1/0,1/0]],s=250,c=to,u=zt("start","zoom","end"),l,y,f,h=500,_=150,v=0,m=10;function p(d){d.property("__zoom",en).on("wheel.zoom",S,{passive:!1}).on("mousedown.zoom",D).on("dblclick.zoom",z).filter(i).on("touchstart.zoom",R).on("touchmove.zoom",q).on("touchend.zoom touchcancel.zoom",F).style("-webkit-tap-highlight-color","rgba(0,0,0,0)")}p.transform=function(d,b,x,E){var $=d.selection?d.selection():d;$.property("__zoom",en),d!==$?N(d,b,x,E):$.interrupt().each(function(){M(this,arguments).event(E).start().zoom(null,typeof b=="function"?b.apply(this,arguments):b).end()})},p.scaleBy=function(d,b,x,E){p.scaleTo(d,function(){var $=this.__zoom.k,C=typeof b=="function"?b.apply(this,arguments):b;return $*C},x,E)},p.scaleTo=function(d,b,x,E){p.transform(d,function(){var $=e.apply(this,arguments),C=this.__zoom,T=x==null?g($):typeof x=="function"?x.apply(this,arguments):x,I=C.invert(T),L=typeof b=="function"?b.apply(this,arguments):b;return n(k(w(C,L),T,I),$,a)},x,E)},p.translateBy=function(d,b,x,E){p.transform(d,function(){return n(this.__zoom.translate(typeof b=="function"?b.apply(this,arguments):b,typeof x=="function"?x.apply(this,arguments):x),e.apply(this,arguments),a)},null,E)},p.translateTo=function(d,b,x,E,$){p.transform(d,function(){var C=e.apply(this,arguments),T=this.__zoom,I=E==null?g(C):typeof E=="function"?E.apply(this,arguments):E;return n(ie.translate(I[0],I[1]).scale(T.k).translate(typeof b=="function"?-b.apply(this,arguments):-b,typeof x=="function"?-x.apply(this,arguments):-x),C,a)},E,$)};function w(d,b){return b=Math.max(o[0],Math.min(o[1],b)),b===d.k?d:new at(b,d.x,d.y)}function k(d,b,x){var E=b[0]-x[0]*d.k,$=b[1]-x[1]*d.k;return E===d.x&&$===d.y?d:new at(d.k,E,$)}function g(d){return[(+d[0][0]+ +d[1][0])/2,(+d[0][1]+ +d[1][1])/2]}function N(d,b,x,E){d.on("start.zoom",function(){M(this,arguments).event(E).start()}).on("interrupt.zoom end.zoom",function(){M(this,arguments).event(E).end()}).tween("zoom",function(){var $=this,C=arguments,T=M($,C).event(E),I=e.apply($,C),L=x==null?g(I):typeof x=="function"?x.apply($,C):x,Z=Math.max(I[1][0]-I[0][0],I[1][1]-I[0][1]),B=$.__zoom,U=typeof b=="function"?b.apply($,C):b,nt=c(B.invert(L).concat(Z/B.k),U.invert(L).concat(Z/U.k));return function(G){if(G===1)G=U;else{var rt=nt(G),oe=Z/rt[2];G=new at(oe,L[0]-rt[0]*oe,L[1]-rt[1]*oe)}T.zoom(null,G)}})}function M(d,b,x){return!x&&d.__zooming||new A(d,b)}function A(d,b){this.that=d,this.args=b,this.active=0,this.sourceEvent=null,this.extent=e.apply(d,b),this.taps=0}A.prototype={event:function(d){return d&&(this.sourceEvent=d),this},start:function(){return++this.active===1&&(this.that.__zooming=this,this.emit("start")),this},zoom:function(d,b){return this.mouse&&d!=="mouse"&&(this.mouse[1]=b.invert(this.mouse[0])),this.touch0&&d!=="touch"&&(this.touch0[1]=b.invert(this.touch0[0])),this.touch1&&d!=="touch"&&(this.touch1[1]=b.invert(this.touch1[0])),this.that.__zoom=b,this.emit("zoom"),this},end:function(){return--this.active===0&&(delete this.that.__zooming,this.emit("end")),this},emit:function(d){var b=O(this.that).datum();u.call(d,this.that,new Ya(d,{sourceEvent:this.sourceEvent,target:p,transform:this.that.__zoom,dispatch:u}),b)}};function S(d,...b){if(!t.apply(this,arguments))return;var x=M(this,b).event(d),E=this.__zoom,$=Math.max(o[0],Math.min(o[1],E.k*Math.pow(2,r.apply(this,arguments)))),C=ot(d);if(x.wheel)(x.mouse[0][0]!==C[0]||x.mouse[0][1]!==C[1])&&(x.mouse[1]=E.invert(x.mouse[0]=C)),clearTimeout(x.wheel);else{if(E.k===$)return;x.mouse=[C,E.invert(C)],Ut(this),x.start()}bt(d),x.wheel=setTimeout(T,_),x.zoom("mouse",n(k(w(E,$),x.mouse[0],x.mouse[1]),x.extent,a));function T(){x.wheel=null,x.end()}}function D(d,...b){if(f||!t.apply(this,arguments))return;var x=d.currentTarget,E=M(this,b,!0).event(d),$=O(d.view).on("mousemove.zoom",L,!0).on("mouseup.zoom",Z,!0),C=ot(d,x),T=d.clientX,I=d.clientY;wn(d.view),ce(d),E.mouse=[C,this.__zoom.invert(C)],Ut(this),E.start();function L(B){if(bt(B),!E.moved){var U=B.clientX-T,nt=B.clientY-I;E.moved=U*U+nt*nt>v}E.event(B).zoom("mouse",n(k(E.that.__zoom,E.mouse[0]=ot(B,x),E.mouse[1]),E.extent,a))}function Z(B){$.on("mousemove.zoom mouseup.zoom",null),bn(B.view,E.moved),bt(B),E.event(B).end()}}function z(d,...b){if(t.apply(this,arguments)){var x=this.__zoom,E=ot(d.changedTouches?d.changedTouches[0]:d,this),$=x.invert(E),C=x.k*(d.shiftKey?.5:2),T=n(k(w(x,C),E,$),e.apply(this,b),a);bt(d),s>0?O(this).transition().duration(s).call(N,T,E,d):O(this).call(p.transform,T,E,d)}}function R(d,...b){if(t.apply(this,arguments)){var x=d.touches,E=x.length,$=M(this,b,d.changedTouches.length===E).event(d),C,T,I,L;for(ce(d),T=0;T<E;++T)I=x[T],L=ot(I,this),L=[L,this.__zoom.invert(L),I.identifier],$.touch0?!$.touch1&&$.touch0[2]!==L[2]&&($.touch1=L,$.taps=0):($.touch0=L,C=!0,$.taps=1+!!l);l&&(l=clearTimeout(l)),C&&($.taps<2&&(y=L[0],l=setTimeout(function(){l=null},h)),Ut(this),$.start())}}function q(d,...b){if(this.__zooming){var x=M(this,b).event(d),E=d.changedTouches,$=E.length,C,T,I,L;for(bt(d),C=0;C<$;++C)T=E[C],I=ot(T,this),x.touch0&&x.touch0[2]===T.identifier?x.touch0[0]=I:x.touch1&&x.touch1[2]===T.identifier&&(x.touch1[0]=I);if(T=x.that.__zoom,x.touch1){var Z=x.touch0[0],B=x.touch0[1],U=x.touch1[0],nt=x.touch1[1],G=(G=U[0]-Z[0])*G+(G=U[1]-Z[1])*G,rt=(rt=nt[0]-B[0])*rt+(rt=nt[1]-B[1])*rt;T=w(T,Math.sqrt(G/rt)),I=[(Z[0]+U[0])/2,(Z[1]+U[1])/2],L=[(B[0]+nt[0])/2,(B[1]+nt[1])/2]}else if(x.touch0)I=x.touch0[0],L=x.touch0[1];else return;x.zoom("touch",n(k(T,I,L),x.extent,a))}}function F(d,...b){if(this.__zooming){var x=M(this,b).event(d),E=d.changedTouches,$=E.length,C,T;for(ce(d),f&&clearTimeout(f),f=setTimeout(function(){f=null},h),C=0;C<$;++C)T=E[C],x.touch0&&x.touch0[2]===T.identifier?delete x.touch0:x.touch1&&x.touch1[2]===T.identifier&&delete x.touch1;if(x.touch1&&!x.touch0&&(x.touch0=x.touch1,delete x.touch1),x.touch0)x.touch0[1]=this.__zoom.invert(x.touch0[0]);else if(x.end(),x.taps===2&&(T=ot(T,this),Math.hypot(y[0]-T[0],y[1]-T[1])<m)){var I=O(this).on("dblclick.zoom");I&&I.apply(this,arguments)}}}return p.wheelDelta=function(d){return arguments.length?(r=typeof d=="function"?d:Xt(+d),p):r},p.filter=function(d){return arguments.length?(t=typeof d=="function"?d:Xt(!!d),p):t},p.touchable=function(d){return arguments.length?(i=typeof d=="function"?d:Xt(!!d),p):i},p.extent=function(d){return arguments.length?(e=typeof d=="function"?d:Xt([[+d[0][0],+d[0][1]],[+d[1][0],+d[1][1]]]),p):e},p.scaleExtent=function(d){return arguments.length?(o[0]=+d[0],o[1]=+d[1],p):[o[0],o[1]]},p.translateExtent=function(d){return arguments.length?(a[0][0]=+d[0][0],a[1][0]=+d[1][0],a[0][1]=+d[0][1],a[1][1]=+d[1][1],p):[[a[0][0],a[0][1]],[a[1][0],a[1][1]]]},p.constrain=function(d){return arguments.length?(n=d,p):n},p.duration=function(d){return arguments.length?(s=+d,p):s},p.interpolate=function(d){return arguments.length?(c=d,p):c},p.on=function(){var d=u.on.apply(u,arguments);return d===u?p:d},p.clickDistance=function(d){return arguments.length?(v=(d=+d)*d,p):Math.sqrt(v)},p.tapDistance=function(d){return arguments.length?(m=+d,p):m},p}const Graph=(function(d3){
+// graph.js — D3 force-directed graph for Gas Town visualization
+
+
+let svg, g, simulation, zoomBehavior;
+let nodeElements, edgeElements, rigElements;
+let currentNodes = [];
+let currentEdges = [];
+let tooltip;
+let onNodeClick = () => {};
+let onNodeContext = () => {};
+
+const nodeSize = {
+  mayor: 48, deacon: 36, overseer: 40, witness: 32,
+  refinery: 36, polecat: 24, crew: 24, bead: 8, convoy: 32,
+};
+
+// Track previous node IDs for spawn detection.
+let previousNodeIds = new Set();
+
+function init(container, clickHandler, contextHandler) {
+  onNodeClick = clickHandler || onNodeClick;
+  onNodeContext = contextHandler || onNodeContext;
+
+  svg = d3.select(container);
+  const rect = svg.node().getBoundingClientRect();
+  svg.attr('width', rect.width).attr('height', rect.height);
+
+  // Arrow markers.
+  const defs = svg.append('defs');
+  defs.append('marker')
+    .attr('id', 'arrow-orange')
+    .attr('viewBox', '0 -5 10 10')
+    .attr('refX', 20).attr('refY', 0)
+    .attr('markerWidth', 6).attr('markerHeight', 6)
+    .attr('orient', 'auto')
+    .append('path')
+    .attr('d', 'M0,-5L10,0L0,5')
+    .attr('fill', '#e85d26');
+
+  // Main group for zoom/pan.
+  g = svg.append('g');
+
+  // Zoom behavior.
+  zoomBehavior = d3.zoom()
+    .scaleExtent([0.2, 4])
+    .on('zoom', (event) => g.attr('transform', event.transform));
+  svg.call(zoomBehavior);
+
+  // Double-click background to reset zoom.
+  svg.on('dblclick.zoom', null); // Remove default d3 zoom dblclick.
+  svg.on('dblclick', (event) => {
+    if (event.target === svg.node()) {
+      svg.transition().duration(500)
+        .call(zoomBehavior.transform, d3.zoomIdentity);
+    }
+  });
+
+  // Layers.
+  g.append('g').attr('class', 'rig-layer');
+  g.append('g').attr('class', 'edge-layer');
+  g.append('g').attr('class', 'particle-layer');
+  g.append('g').attr('class', 'node-layer');
+
+  // Tooltip.
+  tooltip = d3.select('body').append('div')
+    .attr('class', 'tooltip')
+    .style('display', 'none');
+
+  // Force simulation.
+  simulation = d3.forceSimulation()
+    .force('charge', d3.forceManyBody().strength(-200))
+    .force('center', d3.forceCenter(rect.width / 2, rect.height / 2))
+    .force('collision', d3.forceCollide().radius(d => (nodeSize[d.type] || 24) + 10))
+    .force('link', d3.forceLink().id(d => d.id).distance(120).strength(0.3))
+    .on('tick', ticked);
+
+  // Idle drift: keep simulation warm with tiny random forces.
+  d3.timer(() => {
+    if (simulation.alpha() < 0.01) {
+      currentNodes.forEach(n => {
+        if (!n.fx && !n.fy) {
+          n.vx += (Math.random() - 0.5) * 0.3;
+          n.vy += (Math.random() - 0.5) * 0.3;
+        }
+      });
+      simulation.alpha(0.005).restart();
+    }
+  });
+
+  // Handle resize.
+  window.addEventListener('resize', () => {
+    const r = svg.node().getBoundingClientRect();
+    svg.attr('width', r.width).attr('height', r.height);
+    simulation.force('center', d3.forceCenter(r.width / 2, r.height / 2));
+    simulation.alpha(0.1).restart();
+  });
+
+  // Close context menu on click elsewhere.
+  document.addEventListener('click', () => {
+    const menu = document.getElementById('context-menu');
+    if (menu) menu.classList.add('hidden');
+  });
+}
+
+function update(snapshot) {
+  if (!svg) return;
+
+  const nodes = snapshot.nodes || [];
+  const edges = snapshot.edges || [];
+
+  // Detect new and removed nodes for animations.
+  const newNodeIds = new Set(nodes.map(n => n.id));
+  const spawnedIds = new Set();
+  const nukedIds = new Set();
+
+  if (previousNodeIds.size > 0) {
+    for (const id of newNodeIds) {
+      if (!previousNodeIds.has(id)) spawnedIds.add(id);
+    }
+    for (const id of previousNodeIds) {
+      if (!newNodeIds.has(id)) nukedIds.add(id);
+    }
+  }
+  previousNodeIds = newNodeIds;
+
+  // Merge positions from existing nodes.
+  const oldMap = {};
+  currentNodes.forEach(n => { oldMap[n.id] = n; });
+  nodes.forEach(n => {
+    if (oldMap[n.id]) {
+      n.x = oldMap[n.id].x;
+      n.y = oldMap[n.id].y;
+      n.vx = oldMap[n.id].vx;
+      n.vy = oldMap[n.id].vy;
+    }
+  });
+
+  currentNodes = nodes;
+  currentEdges = edges;
+
+  // Build edge objects referencing node data.
+  const nodeMap = {};
+  nodes.forEach(n => { nodeMap[n.id] = n; });
+  const links = edges
+    .filter(e => nodeMap[typeof e.source === 'string' ? e.source : e.source?.id])
+    .map(e => ({
+      ...e,
+      source: typeof e.source === 'string' ? e.source : e.source.id,
+      target: typeof e.target === 'string' ? e.target : e.target.id,
+    }));
+
+  // Detect rigs.
+  const rigs = {};
+  nodes.forEach(n => {
+    if (n.rig) {
+      if (!rigs[n.rig]) rigs[n.rig] = [];
+      rigs[n.rig].push(n);
+    }
+  });
+
+  renderRigs(rigs);
+  renderEdges(links);
+  renderNodes(nodes, spawnedIds);
+
+  // Update simulation.
+  simulation.nodes(nodes);
+  simulation.force('link').links(links);
+  simulation.alpha(0.3).restart();
+
+  // Apply cluster forces for rigs.
+  applyClusterForces(rigs);
+}
+
+function renderRigs(rigs) {
+  const rigLayer = g.select('.rig-layer');
+  const rigData = Object.entries(rigs).map(([name, nodes]) => ({ name, nodes }));
+
+  const rigSel = rigLayer.selectAll('.rig-group')
+    .data(rigData, d => d.name);
+
+  const rigEnter = rigSel.enter().append('g').attr('class', 'rig-group');
+  rigEnter.append('rect').attr('class', 'rig-container');
+  rigEnter.append('text').attr('class', 'rig-label');
+
+  // Double-click rig to zoom in.
+  rigEnter.on('dblclick', (event, d) => {
+    event.stopPropagation();
+    zoomToRig(d);
+  });
+
+  rigSel.exit().remove();
+  rigElements = rigSel.merge(rigEnter);
+  rigElements.classed('degraded', d => d.nodes.some(n => n.metadata?.rig_health === 'degraded'));
+}
+
+function zoomToRig(rig) {
+  if (!rig.nodes.length) return;
+  const padding = 80;
+  let minX = Infinity, minY = Infinity, maxX = -Infinity, maxY = -Infinity;
+  rig.nodes.forEach(n => {
+    if (n.x !== undefined) {
+      minX = Math.min(minX, n.x);
+      minY = Math.min(minY, n.y);
+      maxX = Math.max(maxX, n.x);
+      maxY = Math.max(maxY, n.y);
+    }
+  });
+  if (minX === Infinity) return;
+
+  const svgRect = svg.node().getBoundingClientRect();
+  const dx = maxX - minX + padding * 2;
+  const dy = maxY - minY + padding * 2;
+  const cx = (minX + maxX) / 2;
+  const cy = (minY + maxY) / 2;
+  const scale = Math.min(svgRect.width / dx, svgRect.height / dy, 2.5);
+  const tx = svgRect.width / 2 - cx * scale;
+  const ty = svgRect.height / 2 - cy * scale;
+
+  svg.transition().duration(500)
+    .call(zoomBehavior.transform, d3.zoomIdentity.translate(tx, ty).scale(scale));
+}
+
+function renderEdges(links) {
+  const edgeLayer = g.select('.edge-layer');
+  const edgeSel = edgeLayer.selectAll('.edge')
+    .data(links, d => d.type + ':' + (d.source.id || d.source) + ':' + (d.target.id || d.target));
+
+  const edgeEnter = edgeSel.enter().append('line')
+    .attr('class', d => 'edge edge-' + d.type);
+
+  edgeSel.exit().remove();
+  edgeElements = edgeSel.merge(edgeEnter);
+
+  // Apply heartbeat animation to monitoring edges.
+  edgeElements.filter(d => d.type === 'monitoring').classed('heartbeat', true);
+}
+
+function renderNodes(nodes, spawnedIds) {
+  const nodeLayer = g.select('.node-layer');
+  const nodeSel = nodeLayer.selectAll('.node')
+    .data(nodes, d => d.id);
+
+  const nodeEnter = nodeSel.enter().append('g')
+    .attr('class', d => 'node node-' + d.type + ' state-' + d.state)
+    .call(d3.drag()
+      .on('start', dragStarted)
+      .on('drag', dragged)
+      .on('end', dragEnded))
+    .on('click', (event, d) => {
+      event.stopPropagation();
+      onNodeClick(d);
+    })
+    .on('contextmenu', (event, d) => {
+      event.preventDefault();
+      event.stopPropagation();
+      onNodeContext(event, d);
+    })
+    .on('mouseenter', showTooltip)
+    .on('mouseleave', hideTooltip);
+
+  // Draw shape based on type.
+  nodeEnter.each(function(d) {
+    const el = d3.select(this);
+    const size = nodeSize[d.type] || 24;
+    switch (d.type) {
+      case 'mayor':
+      case 'deacon':
+        drawHexagon(el, size);
+        break;
+      case 'overseer':
+        drawDiamond(el, size);
+        break;
+      case 'witness':
+      case 'polecat':
+        el.append('circle').attr('class', 'shape').attr('r', size / 2);
+        break;
+      case 'refinery':
+      case 'crew':
+        el.append('rect').attr('class', 'shape')
+          .attr('x', -size / 2).attr('y', -size * 0.4)
+          .attr('width', size).attr('height', size * 0.8)
+          .attr('rx', 4);
+        break;
+      case 'bead':
+        el.append('circle').attr('class', 'shape').attr('r', size / 2);
+        break;
+      case 'convoy':
+        el.append('rect').attr('class', 'shape')
+          .attr('x', -size / 2).attr('y', -7)
+          .attr('width', size).attr('height', 14)
+          .attr('rx', 7);
+        break;
+      default:
+        el.append('circle').attr('class', 'shape').attr('r', size / 2);
+    }
+
+    // Label (skip for beads — too small).
+    if (d.type !== 'bead') {
+      el.append('text')
+        .attr('dy', size / 2 + 14)
+        .text(d.label);
+    }
+  });
+
+  // Spawn animation: fade in with burst ring.
+  nodeEnter.filter(d => spawnedIds.has(d.id))
+    .style('opacity', 0)
+    .transition().duration(600)
+    .style('opacity', 1);
+
+  // Spawn burst ring for polecats.
+  nodeEnter.filter(d => spawnedIds.has(d.id) && d.type === 'polecat')
+    .each(function() {
+      d3.select(this).append('circle')
+        .attr('class', 'spawn-ring')
+        .attr('r', 0)
+        .attr('fill', 'none')
+        .attr('stroke', '#39ff14')
+        .attr('stroke-width', 3)
+        .attr('opacity', 0.8)
+        .transition().duration(600)
+        .attr('r', 30)
+        .attr('opacity', 0)
+        .attr('stroke-width', 0)
+        .remove();
+    });
+
+  // Update classes on existing nodes for state changes.
+  nodeSel.attr('class', d => 'node node-' + d.type + ' state-' + d.state);
+
+  const merged = nodeSel.merge(nodeEnter);
+
+  // Apply breathing to working polecats and running agents.
+  merged.classed('breathing', d =>
+    (d.type === 'polecat' && d.state === 'working') ||
+    (['witness', 'refinery', 'mayor', 'deacon'].includes(d.type) && d.state === 'running')
+  );
+
+  // Apply pulse to hooked beads.
+  merged.classed('pulse-yellow', d => d.type === 'bead' && d.state === 'hooked');
+
+  // Dim nodes whose source is failing; they show the last known state.
+  merged.classed('stale', d => !!d.stale);
+
+  // Nuke animation: dissolve.
+  nodeSel.exit()
+    .transition().duration(800)
+    .style('opacity', 0)
+    .attr('transform', d => `translate(${d.x},${d.y}) scale(0.3)`)
+    .remove();
+
+  nodeElements = merged;
+}
+
+function drawHexagon(el, size) {
+  const r = size / 2;
+  const points = [];
+  for (let i = 0; i < 6; i++) {
+    const angle = (Math.PI / 3) * i - Math.PI / 2;
+    points.push([r * Math.cos(angle), r * Math.sin(angle)]);
+  }
+  el.append('polygon')
+    .attr('class', 'shape')
+    .attr('points', points.map(p => p.join(',')).join(' '));
+}
+
+function drawDiamond(el, size) {
+  const r = size / 2;
+  const points = [[0, -r], [r, 0], [0, r], [-r, 0]];
+  el.append('polygon')
+    .attr('class', 'shape')
+    .attr('points', points.map(p => p.join(',')).join(' '));
+}
+
+function ticked() {
+  if (edgeElements) {
+    edgeElements
+      .attr('x1', d => d.source.x)
+      .attr('y1', d => d.source.y)
+      .attr('x2', d => d.target.x)
+      .attr('y2', d => d.target.y);
+  }
+
+  if (nodeElements) {
+    nodeElements.attr('transform', d => `translate(${d.x},${d.y})`);
+  }
+
+  if (rigElements) {
+    rigElements.each(function(d) {
+      const group = d3.select(this);
+      const padding = 40;
+      let minX = Infinity, minY = Infinity, maxX = -Infinity, maxY = -Infinity;
+      d.nodes.forEach(n => {
+        if (n.x !== undefined) {
+          minX = Math.min(minX, n.x);
+          minY = Math.min(minY, n.y);
+          maxX = Math.max(maxX, n.x);
+          maxY = Math.max(maxY, n.y);
+        }
+      });
+      if (minX === Infinity) return;
+      group.select('.rig-container')
+        .attr('x', minX - padding)
+        .attr('y', minY - padding)
+        .attr('width', maxX - minX + padding * 2)
+        .attr('height', maxY - minY + padding * 2);
+      group.select('.rig-label')
+        .attr('x', minX - padding + 8)
+        .attr('y', minY - padding + 16)
+        .text(d.name);
+    });
+  }
+}
+
+function applyClusterForces(rigs) {
+  const svgRect = svg.node().getBoundingClientRect();
+  const rigNames = Object.keys(rigs);
+  const clusterCenters = {};
+
+  rigNames.forEach((name, i) => {
+    const angle = (2 * Math.PI * i) / rigNames.length;
+    const cx = svgRect.width / 2 + Math.cos(angle) * Math.min(svgRect.width, svgRect.height) * 0.25;
+    const cy = svgRect.height / 2 + Math.sin(angle) * Math.min(svgRect.width, svgRect.height) * 0.25;
+    clusterCenters[name] = { x: cx, y: cy };
+  });
+
+  simulation.force('cluster', (alpha) => {
+    currentNodes.forEach(node => {
+      if (node.rig && clusterCenters[node.rig]) {
+        const c = clusterCenters[node.rig];
+        node.vx += (c.x - node.x) * alpha * 0.15;
+        node.vy += (c.y - node.y) * alpha * 0.15;
+      }
+    });
+  });
+}
+
+function showTooltip(event, d) {
+  const stateColors = {
+    working: '#4a8db7', idle: '#444455', running: '#39ff14',
+    nuked: '#2a2a3e', spawning: '#39ff14', stopped: '#ff3344',
+    unassigned: '#444455', hooked: '#f0c040', in_progress: '#4a8db7',
+    in_refinery: '#e85d26', merged: '#39ff14', closed: '#39ff14',
+    rejected: '#ff3344', escalated: '#cc44ff',
+  };
+  const stateColor = stateColors[d.state] || '#8888a0';
+
+  tooltip
+    .style('display', 'block')
+    .style('left', (event.pageX + 12) + 'px')
+    .style('top', (event.pageY - 8) + 'px')
+    .html(`
+      <div class="tt-label">${d.label}</div>
+      <div class="tt-type">${d.type}${d.rig ? ' \u00B7 ' + d.rig : ''}</div>
+      <div class="tt-state" style="color: ${stateColor}">${d.state}</div>
+      ${d.metadata?.hooked_bead ? `<div class="tt-type">hook: ${d.metadata.hooked_bead}</div>` : ''}
+      ${d.metadata?.title ? `<div class="tt-type">${d.metadata.title}</div>` : ''}
+    `);
+
+  // Highlight connected edges.
+  if (edgeElements) {
+    edgeElements.style('opacity', e => {
+      const sid = e.source.id || e.source;
+      const tid = e.target.id || e.target;
+      return (sid === d.id || tid === d.id) ? 1 : 0.15;
+    });
+  }
+}
+
+function hideTooltip() {
+  tooltip.style('display', 'none');
+  if (edgeElements) {
+    edgeElements.style('opacity', null);
+  }
+}
+
+function dragStarted(event, d) {
+  if (!event.active) simulation.alphaTarget(0.3).restart();
+  d.fx = d.x;
+  d.fy = d.y;
+}
+
+function dragged(event, d) {
+  d.fx = event.x;
+  d.fy = event.y;
+}
+
+function dragEnded(event, d) {
+  if (!event.active) simulation.alphaTarget(0);
+  d.fx = null;
+  d.fy = null;
+}
+
+// Animate a mail particle along an edge path.
+function animateMailParticle(sourceId, targetId) {
+  const layer = g.select('.particle-layer');
+  const sourceNode = currentNodes.find(n => n.id === sourceId);
+  const targetNode = currentNodes.find(n => n.id === targetId);
+  if (!sourceNode || !targetNode) return;
+
+  const particle = layer.append('circle')
+    .attr('r', 4)
+    .attr('fill', '#e0e0e8')
+    .attr('opacity', 0.9)
+    .attr('cx', sourceNode.x)
+    .attr('cy', sourceNode.y);
+
+  particle.transition()
+    .duration(800)
+    .attr('cx', targetNode.x)
+    .attr('cy', targetNode.y)
+    .attr('opacity', 0)
+    .remove();
+}
+
+// Animate a green merge pulse on a refinery node.
+function animateMergePulse(refineryId) {
+  const node = currentNodes.find(n => n.id === refineryId);
+  if (!node) return;
+
+  const layer = g.select('.particle-layer');
+  layer.append('circle')
+    .attr('cx', node.x)
+    .attr('cy', node.y)
+    .attr('r', 18)
+    .attr('fill', 'none')
+    .attr('stroke', '#39ff14')
+    .attr('stroke-width', 3)
+    .attr('opacity', 0.8)
+    .transition().duration(500)
+    .attr('r', 60)
+    .attr('opacity', 0)
+    .attr('stroke-width', 0)
+    .remove();
+}
+return {init,update,animateMailParticle,animateMergePulse};
+})({select:O,zoom:Qa,zoomIdentity:ie,forceSimulation:Fa,forceManyBody:Oa,forceCenter:Ze,forceCollide:Sa,forceLink:Ia,timer:ne,drag:Ai});
+const Panel=(function(){
+// panel.js — Side panel for node drill-down details
+
+let panelEl, titleEl, bodyEl, closeBtn;
+
+function escapeHtml(text) {
+  const div = document.createElement('div');
+  div.textContent = String(text);
+  return div.innerHTML;
+}
+
+function field(label, value) {
+  return `<div class="field-label">${escapeHtml(label)}</div><div class="field-value">${value}</div>`;
+}
+
+function stateBadge(state) {
+  const colors = {
+    working: 'var(--accent-blue)', idle: 'var(--node-idle)',
+    running: 'var(--accent-green)', stopped: 'var(--accent-red)',
+    nuked: 'var(--text-muted)', spawning: 'var(--accent-green)',
+    unassigned: 'var(--node-idle)', hooked: 'var(--accent-yellow)',
+    in_progress: 'var(--accent-blue)', in_refinery: 'var(--accent-orange)',
+    merged: 'var(--accent-green)', closed: 'var(--accent-green)',
+    rejected: 'var(--accent-red)', escalated: 'var(--accent-magenta)',
+  };
+  const color = colors[state] || 'var(--text-secondary)';
+  return `<span class="state-badge" style="background: ${color}; color: #0a0a0f;">${state}</span>`;
+}
+
+function getCopyCommand(node) {
+  switch (node.type) {
+    case 'bead':
+      return `bd show ${node.label}`;
+    case 'polecat':
+      return `gt peek ${node.rig}/polecats/${node.label}`;
+    case 'witness':
+      return `gt peek ${node.rig}/witness`;
+    case 'refinery':
+      return `gt peek ${node.rig}/refinery`;
+    default:
+      return null;
+  }
+}
+
+function buildContent(node) {
+  let html = '';
+  html += field('ID', escapeHtml(node.id));
+  html += field('Type', escapeHtml(node.type));
+  html += field('State', stateBadge(node.state, node.type));
+
+  if (node.rig) {
+    html += field('Rig', escapeHtml(node.rig));
+  }
+
+  if (node.metadata) {
+    Object.entries(node.metadata).forEach(([key, value]) => {
+      if (value) {
+        html += field(key.replace(/_/g, ' '), escapeHtml(value));
+      }
+    });
+  }
+
+  const cmd = getCopyCommand(node);
+  if (cmd) {
+    html += `
       <div class="field-label" style="margin-top: 16px;">Terminal</div>
       <div class="field-value copy-cmd" style="cursor: pointer; color: var(--accent-blue);"
            title="Click to copy">
-        ${yt(n)}
+        ${escapeHtml(cmd)}
       </div>
-    `),e}function gs(){Ie.querySelectorAll(".copy-cmd").forEach(t=>{t.addEventListener("click",()=>{const e=t.textContent.trim();navigator.clipboard.writeText(e).then(()=>{const n=t.textContent;t.textContent="Copied!",setTimeout(()=>{t.textContent=n},1500)})})})}function ps(){ze=document.getElementById("panel"),Bn=document.getElementById("panel-title"),Ie=document.getElementById("panel-body"),rn=document.getElementById("panel-close"),rn.addEventListener("click",on),document.getElementById("graph").addEventListener("click",t=>{(t.target.id==="graph"||t.target.tagName==="svg")&&on()})}function ys(t){Bn.textContent=t.label,Ie.innerHTML=ds(t),gs(),ze.classList.remove("hidden")}function on(){ze.classList.add("hidden")}let j;const Pn=50,ms={bead_closed:"✓",bead_opened:"○",bead_hooked:"⚓",polecat_spawned:"🐱",polecat_nuked:"💀",mail_sent:"✉",merge_complete:"🔀",escalation:"🚨",state_change:"↻"};function vs(t){if(!t)return"";try{return new Date(t).toLocaleTimeString("en",{hour:"2-digit",minute:"2-digit",second:"2-digit",hour12:!1})}catch{return""}}function Xn(t){const e=vs(t.timestamp),n=ms[t.event]||"·";return`
-    <span class="act-time">${e}</span>
-    <span class="act-event">${n} ${t.event||""}</span>
-    <span class="act-agent">${t.agent||""}</span>
-    <span class="act-detail">${t.detail||""}</span>
-  `}function xs(){j=document.getElementById("activity-list")}function _s(t){if(!t||t.length===0)return;const e=t.slice(-Pn);j.innerHTML=e.map(n=>`<div class="activity-entry">${Xn(n)}</div>`).join(""),j.scrollTop=j.scrollHeight}function ws(t){const e=document.createElement("div");for(e.className="activity-entry",e.innerHTML=Xn(t),j.appendChild(e);j.children.length>Pn;)j.removeChild(j.firstChild);j.scrollTop=j.scrollHeight}const Ft=document.getElementById("connection-status"),bs=document.getElementById("status-rigs"),Es=document.getElementById("status-polecats"),Ns=document.getElementById("status-beads");let ft=null,an=null,Ot=null;function sn(){Wa("#graph",ks,Ms),ps(),xs(),Hn()}function ks(t){ys(t)}function Ms(t,e){const n=document.getElementById("context-menu"),r=document.getElementById("context-menu-items"),i=$s(e);i.length!==0&&(r.innerHTML="",i.forEach(o=>{const a=document.createElement("div");a.className="ctx-item";const s=document.createElement("span");s.textContent=o.label;const c=document.createElement("span");c.className="ctx-label",c.textContent=o.command,a.appendChild(s),a.appendChild(c),a.addEventListener("click",()=>{navigator.clipboard.writeText(o.command),n.classList.add("hidden")}),r.appendChild(a)}),n.style.left=t.pageX+"px",n.style.top=t.pageY+"px",n.classList.remove("hidden"))}function $s(t){const e=[];switch(t.type){case"bead":e.push({label:"Show bead",command:`bd show ${t.label}`}),e.push({label:"Close bead",command:`bd close ${t.label}`});break;case"polecat":e.push({label:"Peek at polecat",command:`gt peek ${t.rig}/polecats/${t.label}`}),e.push({label:"Nudge polecat",command:`gt nudge ${t.rig}/polecats/${t.label} ""`});break;case"witness":e.push({label:"Peek at witness",command:`gt peek ${t.rig}/witness`});break;case"refinery":e.push({label:"Peek at refinery",command:`gt peek ${t.rig}/refinery`});break;case"mayor":e.push({label:"Send mail",command:'gt mail send mayor/ -s "" -m ""'});break}return e}function Hn(){le("connecting"),ft&&ft.close(),ft=new EventSource("/api/events"),ft.addEventListener("connected",()=>{le("connected"),clearTimeout(an)}),ft.onmessage=t=>{try{const e=JSON.parse(t.data);As(e)}catch(e){console.error("Failed to parse SSE message:",e)}},ft.onerror=()=>{le("disconnected"),ft.close(),an=setTimeout(Hn,3e3)}}function As(t){switch(t.type){case"snapshot":Ot=t,nn(t),_s(t.activity),un(t.summary);break;case"diff":Ot&&(Ts(Ot,t),nn(Ot),t.activity_append&&t.activity_append.forEach(e=>{ws(e),Cs(e)}),t.summary&&un(t.summary));break}}function Cs(t){if(t&&(t.event==="mail_sent"&&t.agent,t.event==="merge_complete"&&t.agent)){const e=t.agent.split("/");if(e.length>=1){const n=e[0];ls(n+"/refinery")}}}function Ts(t,e){e.nodes_removed&&(t.nodes=t.nodes.filter(n=>!e.nodes_removed.includes(n.id))),e.nodes_added&&t.nodes.push(...e.nodes_added),e.nodes_updated&&e.nodes_updated.forEach(n=>{const r=t.nodes.findIndex(i=>i.id===n.id);r!==-1&&Object.assign(t.nodes[r],n)}),e.edges_removed&&(t.edges=t.edges.filter(n=>{const r=n.type+":"+n.source+":"+n.target;return!e.edges_removed.includes(r)})),e.edges_added&&t.edges.push(...e.edges_added),e.activity_append&&(t.activity.push(...e.activity_append),t.activity.length>100&&(t.activity=t.activity.slice(-100))),e.summary&&(t.summary=e.summary),t.timestamp=e.timestamp}function un(t){t&&(bs.textContent=t.rig_count+" rig"+(t.rig_count!==1?"s":""),Es.textContent=t.active_polecats+" polecat"+(t.active_polecats!==1?"s":""),Ns.textContent=t.open_beads+" bead"+(t.open_beads!==1?"s":""))}function le(t){switch(Ft.className=t,t){case"connected":Ft.textContent="● connected";break;case"disconnected":Ft.textContent="○ disconnected";break;case"connecting":Ft.textContent="◌ connecting...";break}}document.readyState==="loading"?document.addEventListener("DOMContentLoaded",sn):sn();
+    `;
+  }
+
+  return html;
+}
+
+function bindCopyHandlers() {
+  bodyEl.querySelectorAll('.copy-cmd').forEach(el => {
+    el.addEventListener('click', () => {
+      const text = el.textContent.trim();
+      navigator.clipboard.writeText(text).then(() => {
+        const original = el.textContent;
+        el.textContent = 'Copied!';
+        setTimeout(() => { el.textContent = original; }, 1500);
+      });
+    });
+  });
+}
+
+function init() {
+  panelEl = document.getElementById('panel');
+  titleEl = document.getElementById('panel-title');
+  bodyEl = document.getElementById('panel-body');
+  closeBtn = document.getElementById('panel-close');
+  closeBtn.addEventListener('click', close);
+
+  document.getElementById('graph').addEventListener('click', (e) => {
+    if (e.target.id === 'graph' || e.target.tagName === 'svg') {
+      close();
+    }
+  });
+}
+
+function show(node) {
+  titleEl.textContent = node.label;
+  bodyEl.innerHTML = buildContent(node);
+  bindCopyHandlers();
+  panelEl.classList.remove('hidden');
+}
+
+function close() {
+  panelEl.classList.add('hidden');
+}
+return {init,show,close};
+})();
+const ActivityFeed=(function(){
+// activity.js — Bottom activity feed
+
+let listEl;
+const maxEntries = 50;
+
+const eventIcons = {
+  bead_closed: '\u2713',
+  bead_opened: '\u25CB',
+  bead_hooked: '\u2693',
+  polecat_spawned: '\uD83D\uDC31',
+  polecat_nuked: '\uD83D\uDC80',
+  mail_sent: '\u2709',
+  merge_complete: '\uD83D\uDD00',
+  merge_failed: '\u274C',
+  escalation: '\uD83D\uDEA8',
+  state_change: '\u21BB',
+  commit: '\u25CF',
+};
+
+function formatTime(ts) {
+  if (!ts) return '';
+  try {
+    const d = new Date(ts);
+    return d.toLocaleTimeString('en', { hour: '2-digit', minute: '2-digit', second: '2-digit', hour12: false });
+  } catch {
+    return '';
+  }
+}
+
+function formatEntryInner(a) {
+  const time = formatTime(a.timestamp);
+  const icon = eventIcons[a.event] || '\u00B7';
+  return `
+    <span class="act-time">${time}</span>
+    <span class="act-event">${icon} ${a.event || ''}</span>
+    <span class="act-agent">${a.agent || ''}</span>
+    <span class="act-detail">${a.detail || ''}</span>
+  `;
+}
+
+function init() {
+  listEl = document.getElementById('activity-list');
+}
+
+function update(activities) {
+  if (!activities || activities.length === 0) return;
+  const recent = activities.slice(-maxEntries);
+  listEl.innerHTML = recent.map(a => `<div class="activity-entry">${formatEntryInner(a)}</div>`).join('');
+  listEl.scrollTop = listEl.scrollHeight;
+}
+
+function append(activity) {
+  const entry = document.createElement('div');
+  entry.className = 'activity-entry';
+  entry.innerHTML = formatEntryInner(activity);
+  listEl.appendChild(entry);
+
+  while (listEl.children.length > maxEntries) {
+    listEl.removeChild(listEl.firstChild);
+  }
+
+  listEl.scrollTop = listEl.scrollHeight;
+}
+return {init,update,append};
+})();
+(function(){
+// main.js — SSE connection and application entry point
+
+
+const statusEl = document.getElementById('connection-status');
+const rigsEl = document.getElementById('status-rigs');
+const polecatsEl = document.getElementById('status-polecats');
+const beadsEl = document.getElementById('status-beads');
+const convoysEl = document.getElementById('status-convoys');
+const sourcesEl = document.getElementById('status-sources');
+
+// ?town=<name> follows one town; otherwise the server's default stream,
+// which covers every town it serves.
+const params = new URLSearchParams(location.search);
+const town = params.get('town');
+const apiBase = town ? '/api/towns/' + encodeURIComponent(town) : '/api';
+// ?token=<token> is passed on to the API when the server requires one.
+const token = params.get('token');
+
+function apiURL(path, query = {}) {
+  const q = new URLSearchParams(query);
+  if (token) q.set('token', token);
+  const s = q.toString();
+  return apiBase + path + (s ? '?' + s : '');
+}
+
+let eventSource = null;
+let reconnectTimer = null;
+let lastSnapshot = null;
+// Id of the last event received. Reconnecting with it replays the diffs
+// missed meanwhile instead of sending a full snapshot.
+let lastEventId = '';
+
+function init() {
+  Graph.init('#graph', handleNodeClick, handleNodeContext);
+  Panel.init();
+  ActivityFeed.init();
+  connect();
+}
+
+function handleNodeClick(node) {
+  Panel.show(node);
+}
+
+function handleNodeContext(event, node) {
+  const menu = document.getElementById('context-menu');
+  const items = document.getElementById('context-menu-items');
+
+  const commands = getContextCommands(node);
+  if (commands.length === 0) return;
+
+  items.innerHTML = '';
+  commands.forEach(cmd => {
+    const el = document.createElement('div');
+    el.className = 'ctx-item';
+    const labelSpan = document.createElement('span');
+    labelSpan.textContent = cmd.label;
+    const cmdSpan = document.createElement('span');
+    cmdSpan.className = 'ctx-label';
+    cmdSpan.textContent = cmd.command;
+    el.appendChild(labelSpan);
+    el.appendChild(cmdSpan);
+    el.addEventListener('click', () => {
+      navigator.clipboard.writeText(cmd.command);
+      menu.classList.add('hidden');
+    });
+    items.appendChild(el);
+  });
+
+  menu.style.left = event.pageX + 'px';
+  menu.style.top = event.pageY + 'px';
+  menu.classList.remove('hidden');
+}
+
+function getContextCommands(node) {
+  const cmds = [];
+  switch (node.type) {
+    case 'bead':
+      cmds.push({ label: 'Show bead', command: `bd show ${node.label}` });
+      cmds.push({ label: 'Close bead', command: `bd close ${node.label}` });
+      break;
+    case 'polecat':
+      cmds.push({ label: 'Peek at polecat', command: `gt peek ${node.rig}/polecats/${node.label}` });
+      cmds.push({ label: 'Nudge polecat', command: `gt nudge ${node.rig}/polecats/${node.label} ""` });
+      break;
+    case 'witness':
+      cmds.push({ label: 'Peek at witness', command: `gt peek ${node.rig}/witness` });
+      break;
+    case 'refinery':
+      cmds.push({ label: 'Peek at refinery', command: `gt peek ${node.rig}/refinery` });
+      break;
+    case 'mayor':
+      cmds.push({ label: 'Send mail', command: `gt mail send mayor/ -s "" -m ""` });
+      break;
+  }
+  return cmds;
+}
+
+function connect() {
+  setStatus('connecting');
+
+  if (eventSource) {
+    eventSource.close();
+  }
+
+  eventSource = new EventSource(apiURL('/events', lastEventId ? { last_event_id: lastEventId } : {}));
+
+  eventSource.addEventListener('connected', () => {
+    setStatus('connected');
+    clearTimeout(reconnectTimer);
+    fetch(apiURL('/sources'))
+      .then(res => res.json())
+      .then(data => updateSources(data.sources))
+      .catch(() => {});
+  });
+
+  eventSource.onmessage = (event) => {
+    if (event.lastEventId) lastEventId = event.lastEventId;
+    try {
+      const data = JSON.parse(event.data);
+      handleMessage(data);
+    } catch (err) {
+      console.error('Failed to parse SSE message:', err);
+    }
+  };
+
+  eventSource.onerror = () => {
+    setStatus('disconnected');
+    eventSource.close();
+    reconnectTimer = setTimeout(connect, 3000);
+  };
+}
+
+function handleMessage(data) {
+  switch (data.type) {
+    case 'snapshot':
+      lastSnapshot = data;
+      Graph.update(data);
+      ActivityFeed.update(data.activity);
+      updateSummary(data.summary);
+      break;
+
+    case 'diff':
+      if (lastSnapshot) {
+        applyDiff(lastSnapshot, data);
+        Graph.update(lastSnapshot);
+        if (data.activity_append) {
+          data.activity_append.forEach(a => {
+            ActivityFeed.append(a);
+            handleActivityAnimation(a);
+          });
+        }
+        if (data.summary) {
+          updateSummary(data.summary);
+        }
+      }
+      break;
+
+    case 'sources':
+      updateSources(data.sources);
+      break;
+  }
+}
+
+function handleActivityAnimation(activity) {
+  if (!activity) return;
+
+  // Mail particle animation.
+  if (activity.event === 'mail_sent' && activity.from && activity.to) {
+    Graph.animateMailParticle(activity.from, activity.to);
+  }
+
+  // Merge pulse animation.
+  if (activity.event === 'merge_complete' && activity.agent) {
+    // Find the refinery node for the agent's rig.
+    const parts = activity.agent.split('/');
+    if (parts.length >= 1) {
+      const rig = parts[0];
+      Graph.animateMergePulse(rig + '/refinery');
+    }
+  }
+}
+
+function applyDiff(snapshot, diff) {
+  if (diff.nodes_removed) {
+    snapshot.nodes = snapshot.nodes.filter(n => !diff.nodes_removed.includes(n.id));
+  }
+
+  if (diff.nodes_added) {
+    snapshot.nodes.push(...diff.nodes_added);
+  }
+
+  if (diff.nodes_updated) {
+    diff.nodes_updated.forEach(update => {
+      const idx = snapshot.nodes.findIndex(n => n.id === update.id);
+      if (idx !== -1) {
+        // Updates carry the whole node, and omit fields that went back to
+        // their zero value, such as stale; replace the node, keeping only its
+        // place in the layout.
+        const { x, y, vx, vy, fx, fy } = snapshot.nodes[idx];
+        snapshot.nodes[idx] = { ...update, x, y, vx, vy, fx, fy };
+      }
+    });
+  }
+
+  if (diff.edges_removed) {
+    snapshot.edges = snapshot.edges.filter(e => {
+      const key = e.type + ':' + e.source + ':' + e.target;
+      return !diff.edges_removed.includes(key);
+    });
+  }
+
+  if (diff.edges_added) {
+    snapshot.edges.push(...diff.edges_added);
+  }
+
+  if (diff.activity_append) {
+    snapshot.activity.push(...diff.activity_append);
+    if (snapshot.activity.length > 100) {
+      snapshot.activity = snapshot.activity.slice(-100);
+    }
+  }
+
+  if (diff.summary) {
+    snapshot.summary = diff.summary;
+  }
+
+  snapshot.timestamp = diff.timestamp;
+}
+
+function updateSummary(summary) {
+  if (!summary) return;
+  rigsEl.textContent = summary.rig_count + ' rig' + (summary.rig_count !== 1 ? 's' : '');
+  polecatsEl.textContent = summary.active_polecats + ' polecat' + (summary.active_polecats !== 1 ? 's' : '');
+  beadsEl.textContent = summary.open_beads + ' bead' + (summary.open_beads !== 1 ? 's' : '');
+  convoysEl.textContent = summary.active_convoys + ' convoy' + (summary.active_convoys !== 1 ? 's' : '');
+}
+
+function updateSources(sources) {
+  const failing = (sources || []).filter(s => s.stale);
+  const warnings = (sources || []).flatMap(s => s.warnings || []);
+  if (failing.length) {
+    sourcesEl.textContent = '\u26A0 ' + failing.map(s => s.name).join(', ') + ' failing';
+  } else if (warnings.length) {
+    // Output gt or bd printed that this build only partly understands.
+    sourcesEl.textContent = '\u26A0 ' + warnings.length + (warnings.length === 1 ? ' schema warning' : ' schema warnings');
+  } else {
+    sourcesEl.textContent = '';
+  }
+  sourcesEl.title = failing.map(s => s.name + ': ' + s.last_error).concat(warnings).join('\n');
+}
+
+function setStatus(status) {
+  statusEl.className = status;
+  switch (status) {
+    case 'connected':
+      statusEl.textContent = '\u25CF connected';
+      break;
+    case 'disconnected':
+      statusEl.textContent = '\u25CB disconnected';
+      break;
+    case 'connecting':
+      statusEl.textContent = '\u25CC connecting...';
+      break;
+  }
+}
+
+// Initialize when DOM is ready.
+if (document.readyState === 'loading') {
+  document.addEventListener('DOMContentLoaded', init);
+} else {
+  init();
+}
+})();
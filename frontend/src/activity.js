@@ -11,8 +11,10 @@ const eventIcons = {
   polecat_nuked: '\uD83D\uDC80',
   mail_sent: '\u2709',
   merge_complete: '\uD83D\uDD00',
+  merge_failed: '\u274C',
   escalation: '\uD83D\uDEA8',
   state_change: '\u21BB',
+  commit: '\u25CF',
 };
 
 function formatTime(ts) {
@@ -191,6 +191,7 @@ function renderRigs(rigs) {
 
   rigSel.exit().remove();
   rigElements = rigSel.merge(rigEnter);
+  rigElements.classed('degraded', d => d.nodes.some(n => n.metadata?.rig_health === 'degraded'));
 }
 
 function zoomToRig(rig) {
@@ -339,6 +340,9 @@ function renderNodes(nodes, spawnedIds) {
   // Apply pulse to hooked beads.
   merged.classed('pulse-yellow', d => d.type === 'bead' && d.state === 'hooked');
 
+  // Dim nodes whose source is failing; they show the last known state.
+  merged.classed('stale', d => !!d.stale);
+
   // Nuke animation: dissolve.
   nodeSel.exit()
     .transition().duration(800)
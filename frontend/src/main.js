@@ -9,10 +9,30 @@ const statusEl = document.getElementById('connection-status');
 const rigsEl = document.getElementById('status-rigs');
 const polecatsEl = document.getElementById('status-polecats');
 const beadsEl = document.getElementById('status-beads');
+const convoysEl = document.getElementById('status-convoys');
+const sourcesEl = document.getElementById('status-sources');
+
+// ?town=<name> follows one town; otherwise the server's default stream,
+// which covers every town it serves.
+const params = new URLSearchParams(location.search);
+const town = params.get('town');
+const apiBase = town ? '/api/towns/' + encodeURIComponent(town) : '/api';
+// ?token=<token> is passed on to the API when the server requires one.
+const token = params.get('token');
+
+function apiURL(path, query = {}) {
+  const q = new URLSearchParams(query);
+  if (token) q.set('token', token);
+  const s = q.toString();
+  return apiBase + path + (s ? '?' + s : '');
+}
 
 let eventSource = null;
 let reconnectTimer = null;
 let lastSnapshot = null;
+// Id of the last event received. Reconnecting with it replays the diffs
+// missed meanwhile instead of sending a full snapshot.
+let lastEventId = '';
 
 function init() {
   Graph.init('#graph', handleNodeClick, handleNodeContext);
@@ -86,14 +106,19 @@ function connect() {
     eventSource.close();
   }
 
-  eventSource = new EventSource('/api/events');
+  eventSource = new EventSource(apiURL('/events', lastEventId ? { last_event_id: lastEventId } : {}));
 
   eventSource.addEventListener('connected', () => {
     setStatus('connected');
     clearTimeout(reconnectTimer);
+    fetch(apiURL('/sources'))
+      .then(res => res.json())
+      .then(data => updateSources(data.sources))
+      .catch(() => {});
   });
 
   eventSource.onmessage = (event) => {
+    if (event.lastEventId) lastEventId = event.lastEventId;
     try {
       const data = JSON.parse(event.data);
       handleMessage(data);
@@ -133,6 +158,10 @@ function handleMessage(data) {
         }
       }
       break;
+
+    case 'sources':
+      updateSources(data.sources);
+      break;
   }
 }
 
@@ -140,9 +169,8 @@ function handleActivityAnimation(activity) {
   if (!activity) return;
 
   // Mail particle animation.
-  if (activity.event === 'mail_sent' && activity.agent) {
-    // If we can find source and target from the activity detail, animate.
-    // For now, this is triggered by the backend sending mail events.
+  if (activity.event === 'mail_sent' && activity.from && activity.to) {
+    Graph.animateMailParticle(activity.from, activity.to);
   }
 
   // Merge pulse animation.
@@ -169,7 +197,11 @@ function applyDiff(snapshot, diff) {
     diff.nodes_updated.forEach(update => {
       const idx = snapshot.nodes.findIndex(n => n.id === update.id);
       if (idx !== -1) {
-        Object.assign(snapshot.nodes[idx], update);
+        // Updates carry the whole node, and omit fields that went back to
+        // their zero value, such as stale; replace the node, keeping only its
+        // place in the layout.
+        const { x, y, vx, vy, fx, fy } = snapshot.nodes[idx];
+        snapshot.nodes[idx] = { ...update, x, y, vx, vy, fx, fy };
       }
     });
   }
@@ -204,6 +236,21 @@ function updateSummary(summary) {
   rigsEl.textContent = summary.rig_count + ' rig' + (summary.rig_count !== 1 ? 's' : '');
   polecatsEl.textContent = summary.active_polecats + ' polecat' + (summary.active_polecats !== 1 ? 's' : '');
   beadsEl.textContent = summary.open_beads + ' bead' + (summary.open_beads !== 1 ? 's' : '');
+  convoysEl.textContent = summary.active_convoys + ' convoy' + (summary.active_convoys !== 1 ? 's' : '');
+}
+
+function updateSources(sources) {
+  const failing = (sources || []).filter(s => s.stale);
+  const warnings = (sources || []).flatMap(s => s.warnings || []);
+  if (failing.length) {
+    sourcesEl.textContent = '\u26A0 ' + failing.map(s => s.name).join(', ') + ' failing';
+  } else if (warnings.length) {
+    // Output gt or bd printed that this build only partly understands.
+    sourcesEl.textContent = '\u26A0 ' + warnings.length + (warnings.length === 1 ? ' schema warning' : ' schema warnings');
+  } else {
+    sourcesEl.textContent = '';
+  }
+  sourcesEl.title = failing.map(s => s.name + ': ' + s.last_error).concat(warnings).join('\n');
 }
 
 function setStatus(status) {
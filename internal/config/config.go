@@ -0,0 +1,300 @@
+// Package config reads Zeppelin's configuration files.
+//
+// Settings come from ~/.config/zeppelin/config.json. The .zeppelin.json file
+// in a town root overrides, key by key, that town's polling, command timeout
+// and retention settings; it cannot change the server, its authentication or
+// the commands run, since whoever can write to a town should not control
+// the others. Command-line flags override both.
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gronitab/zeppelin/internal/poller"
+	"github.com/gronitab/zeppelin/internal/state"
+)
+
+// RootFile is the name of the configuration file in a town root.
+const RootFile = ".zeppelin.json"
+
+// Config is Zeppelin's configuration. Zero fields are unset, leaving the
+// flag defaults in place.
+type Config struct {
+	Port int    `json:"port"`
+	Bind string `json:"bind"`
+	Auth Auth   `json:"auth"`
+
+	// Towns maps town names to root directories. Only the user
+	// configuration may list towns.
+	Towns map[string]string `json:"towns"`
+
+	// Intervals overrides how often sources poll, by source name.
+	Intervals      map[string]Duration `json:"intervals"`
+	SafetyInterval Duration            `json:"safety_interval"`
+	Watch          *bool               `json:"watch"`
+	MailTTL        Duration            `json:"mail_ttl"`
+
+	// DataDir holds each town's on-disk event log.
+	DataDir string `json:"data_dir"`
+
+	Commands  Commands  `json:"commands"`
+	Retention Retention `json:"retention"`
+}
+
+// Auth protects the API.
+type Auth struct {
+	// Token, if set, must accompany every API request, as a bearer token
+	// or a token query parameter.
+	Token string `json:"token"`
+}
+
+// Commands configures how gt, bd and git are run.
+type Commands struct {
+	MaxConcurrent int                 `json:"max_concurrent"`
+	Timeout       Duration            `json:"timeout"`
+	Timeouts      map[string]Duration `json:"timeouts"` // by command prefix, as --timeout
+	Binaries      map[string]string   `json:"binaries"` // command name -> path
+	Env           map[string]string   `json:"env"`      // added to the commands' environment
+}
+
+// Retention bounds what the server keeps in memory and on disk.
+type Retention struct {
+	// Activity is how many activity entries each town keeps.
+	Activity int `json:"activity"`
+	// History is how many diffs each town keeps for time travel.
+	History int `json:"history"`
+	// LogSegments, LogSegmentSize (in bytes) and LogAge bound each town's
+	// event log in the data directory.
+	LogSegments    int      `json:"log_segments"`
+	LogSegmentSize int64    `json:"log_segment_size"`
+	LogAge         Duration `json:"log_age"`
+}
+
+// binaries are the commands whose path may be configured.
+var binaries = []string{"gt", "bd", "git"}
+
+// Duration is a time.Duration written as a string such as "5s" or "1m30s".
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("expected a duration such as \"5s\", got %s", data)
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf("invalid duration %q: use a number with a unit, such as \"5s\" or \"2m\"", s)
+	}
+	*d = Duration(v)
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UserPath returns the path of the user configuration file.
+func UserPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "zeppelin", "config.json")
+}
+
+// Load reads the user configuration file at path over c. A missing file is
+// not an error unless required is set.
+func (c *Config) Load(path string, required bool) error {
+	return c.load(path, required, nil)
+}
+
+// ForTown returns the configuration of the town at root: c, with the town's
+// own settings from its root file. c is not changed.
+func (c Config) ForTown(root string) (Config, error) {
+	c.Intervals = maps.Clone(c.Intervals)
+	c.Commands.Timeouts = maps.Clone(c.Commands.Timeouts)
+	err := c.load(filepath.Join(root, RootFile), false, Config.checkTownOnly)
+	return c, err
+}
+
+// checkTownOnly rejects the settings a root file cannot make.
+func (c Config) checkTownOnly() error {
+	var keys []string
+	for key, set := range map[string]bool{
+		"port":              c.Port != 0,
+		"bind":              c.Bind != "",
+		"auth":              c.Auth != Auth{},
+		"towns":             len(c.Towns) > 0,
+		"data_dir":          c.DataDir != "",
+		"commands.binaries": len(c.Commands.Binaries) > 0,
+		"commands.env":      len(c.Commands.Env) > 0,
+	} {
+		if set {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	slices.Sort(keys)
+	return fmt.Errorf("%s can only be set in %s", strings.Join(keys, ", "), UserPath())
+}
+
+// load reads the file at path over c, after check, if given, accepts it.
+func (c *Config) load(path string, required bool, check func(Config) error) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) && !required {
+		if toml := strings.TrimSuffix(path, ".json") + ".toml"; toml != path {
+			if _, err := os.Stat(toml); err == nil {
+				return fmt.Errorf("%s: TOML is not supported; write the configuration as JSON in %s", toml, path)
+			}
+		}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var file Config
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&file); err != nil {
+		return fmt.Errorf("%s: %s", path, describe(data, err))
+	}
+	if check != nil {
+		if err := check(file); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	if err := file.Validate(); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	// Decode again over c, so maps merge and absent keys keep earlier values.
+	return json.Unmarshal(data, c)
+}
+
+// describe turns a decoding error into a message that points at the problem.
+func describe(data []byte, err error) string {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		line, col := position(data, syntaxErr.Offset)
+		return fmt.Sprintf("line %d, column %d: %v", line, col, err)
+	case errors.As(err, &typeErr):
+		line, col := position(data, typeErr.Offset)
+		return fmt.Sprintf("line %d, column %d: %s must be %s, not %s", line, col, typeErr.Field, typeErr.Type, typeErr.Value)
+	default:
+		// Unknown fields are named by the json package, and invalid
+		// durations quote the value.
+		return strings.TrimPrefix(err.Error(), "json: ")
+	}
+}
+
+// position converts the offset the json package reports, just past the
+// offending byte, into the 1-based line and column of that byte.
+func position(data []byte, offset int64) (int, int) {
+	before := data[:min(max(int(offset)-1, 0), len(data))]
+	line := bytes.Count(before, []byte("\n")) + 1
+	col := len(before) - bytes.LastIndexByte(before, '\n')
+	return line, col
+}
+
+// Validate checks the settings present in c.
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.Port >= 0 && c.Port <= 65535, "port %d is out of range 1-65535", c.Port)
+	if c.Bind != "" {
+		check(net.ParseIP(c.Bind) != nil || validHost(c.Bind), "bind %q is not an IP address or host name", c.Bind)
+	}
+	for _, name := range slices.Sorted(maps.Keys(c.Towns)) {
+		if err := CheckTownName(name); err != nil {
+			errs = append(errs, fmt.Errorf("towns: %w", err))
+		}
+		check(c.Towns[name] != "", "towns: %s has no root directory", name)
+	}
+	for _, name := range slices.Sorted(maps.Keys(c.Intervals)) {
+		check(poller.IsSource(name), "intervals: unknown source %q (sources are %s)", name, strings.Join(poller.SourceNames(), ", "))
+		check(time.Duration(c.Intervals[name]) >= time.Second, "intervals: %s must be at least 1s", name)
+	}
+	check(c.SafetyInterval == 0 || time.Duration(c.SafetyInterval) >= time.Second, "safety_interval must be at least 1s")
+	check(c.MailTTL >= 0, "mail_ttl must not be negative")
+
+	check(c.Commands.MaxConcurrent >= 0, "commands.max_concurrent must not be negative")
+	check(c.Commands.Timeout >= 0, "commands.timeout must not be negative")
+	for _, cmd := range slices.Sorted(maps.Keys(c.Commands.Timeouts)) {
+		check(strings.TrimSpace(cmd) != "", "commands.timeouts: empty command")
+		check(c.Commands.Timeouts[cmd] > 0, "commands.timeouts: %q must be positive", cmd)
+	}
+	for _, name := range slices.Sorted(maps.Keys(c.Commands.Binaries)) {
+		check(slices.Contains(binaries, name), "commands.binaries: unknown command %q (commands are %s)", name, strings.Join(binaries, ", "))
+		check(c.Commands.Binaries[name] != "", "commands.binaries: %s has an empty path", name)
+	}
+	for _, k := range slices.Sorted(maps.Keys(c.Commands.Env)) {
+		check(k != "" && !strings.ContainsAny(k, "= \t"), "commands.env: invalid variable name %q", k)
+	}
+	check(c.Retention.Activity >= 0, "retention.activity must not be negative")
+	check(c.Retention.History >= 0, "retention.history must not be negative")
+	check(c.Retention.LogSegments >= 0, "retention.log_segments must not be negative")
+	check(c.Retention.LogSegmentSize >= 0, "retention.log_segment_size must not be negative")
+	check(c.Retention.LogAge >= 0, "retention.log_age must not be negative")
+
+	return errors.Join(errs...)
+}
+
+// CheckTownName reports whether name can name a town: it appears in URLs
+// and namespaced node IDs, so it cannot contain "/" or the town separator.
+func CheckTownName(name string) error {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, "/ "+state.TownSeparator) {
+		return fmt.Errorf("invalid town name %q", name)
+	}
+	return nil
+}
+
+// EnvList returns the extra command environment as "KEY=VALUE" pairs.
+func (c Commands) EnvList() []string {
+	var env []string
+	for _, k := range slices.Sorted(maps.Keys(c.Env)) {
+		env = append(env, k+"="+c.Env[k])
+	}
+	return env
+}
+
+// Durations converts a map of configured durations.
+func Durations(m map[string]Duration) map[string]time.Duration {
+	if m == nil {
+		return nil
+	}
+	out := make(map[string]time.Duration, len(m))
+	for k, v := range m {
+		out[k] = time.Duration(v)
+	}
+	return out
+}
+
+func validHost(h string) bool {
+	for _, label := range strings.Split(h, ".") {
+		if label == "" || strings.Trim(label, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-") != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,132 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, dir, name, data string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestForTownMerges(t *testing.T) {
+	dir := t.TempDir()
+	user := writeFile(t, dir, "config.json", `{
+		"port": 8080,
+		"towns": {"home": "~/gt"},
+		"intervals": {"beads": "30s", "mail": "10s"},
+		"commands": {"binaries": {"gt": "/opt/gt"}, "env": {"BD_DB": "x"}}
+	}`)
+	writeFile(t, dir, RootFile, `{
+		"intervals": {"mail": "20s"},
+		"retention": {"activity": 500}
+	}`)
+
+	var cfg Config
+	if err := cfg.Load(user, true); err != nil {
+		t.Fatalf("load user config: %v", err)
+	}
+	town, err := cfg.ForTown(dir)
+	if err != nil {
+		t.Fatalf("load root config: %v", err)
+	}
+
+	if town.Port != 8080 || town.Retention.Activity != 500 {
+		t.Errorf("expected settings from both files, got %+v", town)
+	}
+	if time.Duration(town.Intervals["beads"]) != 30*time.Second || time.Duration(town.Intervals["mail"]) != 20*time.Second {
+		t.Errorf("expected root file to override mail only, got %v", town.Intervals)
+	}
+	if env := town.Commands.EnvList(); len(env) != 1 || env[0] != "BD_DB=x" {
+		t.Errorf("expected env list, got %v", env)
+	}
+
+	// Other towns keep the user settings.
+	if time.Duration(cfg.Intervals["mail"]) != 10*time.Second || cfg.Retention.Activity != 0 {
+		t.Errorf("expected the user configuration unchanged, got %v, %+v", cfg.Intervals, cfg.Retention)
+	}
+	other, err := cfg.ForTown(t.TempDir())
+	if err != nil || time.Duration(other.Intervals["mail"]) != 10*time.Second {
+		t.Errorf("expected a town without a root file to keep the user settings, got %v, %v", other.Intervals, err)
+	}
+}
+
+func TestLoadMissing(t *testing.T) {
+	var cfg Config
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := cfg.Load(path, false); err != nil {
+		t.Errorf("expected a missing optional file to be skipped, got %v", err)
+	}
+	if err := cfg.Load(path, true); err == nil {
+		t.Error("expected a missing required file to be an error")
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name, data, want string
+	}{
+		{"syntax", "{\n  \"port\": 80,\n}", "line 3, column 1"},
+		{"type", `{"port": "80"}`, "port must be int, not string"},
+		{"unknown field", `{"intervls": {}}`, `unknown field "intervls"`},
+		{"duration", `{"mail_ttl": "10"}`, `invalid duration "10"`},
+		{"source", `{"intervals": {"bogus": "5s"}}`, `unknown source "bogus"`},
+		{"interval", `{"intervals": {"beads": "100ms"}}`, "beads must be at least 1s"},
+		{"port", `{"port": 70000}`, "port 70000 is out of range"},
+		{"bind", `{"bind": "not an address"}`, `bind "not an address"`},
+		{"binary", `{"commands": {"binaries": {"rm": "/bin/rm"}}}`, `unknown command "rm"`},
+		{"env", `{"commands": {"env": {"A=B": "c"}}}`, `invalid variable name "A=B"`},
+		{"town", `{"towns": {"a:b": "/x"}}`, `invalid town name "a:b"`},
+		{"retention", `{"retention": {"log_segments": -1}}`, "retention.log_segments must not be negative"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeFile(t, t.TempDir(), "config.json", tt.data)
+			var cfg Config
+			err := cfg.Load(path, true)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+			if err != nil && !strings.HasPrefix(err.Error(), path+": ") {
+				t.Errorf("expected error to name the file, got %v", err)
+			}
+		})
+	}
+}
+
+func TestForTownRejectsServerSettings(t *testing.T) {
+	for _, data := range []string{
+		`{"towns": {"a": "/x"}}`,
+		`{"port": 80}`,
+		`{"bind": "0.0.0.0"}`,
+		`{"auth": {"token": "x"}}`,
+		`{"data_dir": "/tmp"}`,
+		`{"commands": {"binaries": {"gt": "/tmp/gt"}}}`,
+		`{"commands": {"env": {"PATH": "/tmp"}}}`,
+	} {
+		dir := t.TempDir()
+		writeFile(t, dir, RootFile, data)
+		_, err := Config{}.ForTown(dir)
+		if err == nil || !strings.Contains(err.Error(), "can only be set in") {
+			t.Errorf("%s: expected the root file rejected, got %v", data, err)
+		}
+	}
+}
+
+func TestLoadRejectsTOML(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "config.toml", "port = 80\n")
+	var cfg Config
+	err := cfg.Load(filepath.Join(dir, "config.json"), false)
+	if err == nil || !strings.Contains(err.Error(), "TOML is not supported") {
+		t.Errorf("expected TOML file to be reported, got %v", err)
+	}
+}
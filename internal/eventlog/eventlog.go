@@ -0,0 +1,293 @@
+// Package eventlog keeps a town's changes on disk, so a restarted server can
+// restore its last state and history instead of starting empty.
+//
+// The log is a directory of segments named by sequence number. Each segment
+// holds JSON lines: a full snapshot, then the diffs that followed it. Once a
+// segment reaches its size limit, the log is compacted into a new segment
+// beginning with the current state, so older segments only serve history and
+// are deleted once past the retention limits.
+package eventlog
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gronitab/zeppelin/internal/state"
+)
+
+const (
+	// DefaultSegmentSize is the size after which a new segment starts.
+	DefaultSegmentSize = 4 << 20
+	// DefaultSegments is how many segments are kept, including the current one.
+	DefaultSegments = 8
+)
+
+const segmentExt = ".jsonl"
+
+// Options bounds the log's size on disk. Zero fields take the defaults.
+type Options struct {
+	SegmentSize int64         // bytes after which a new segment starts
+	Segments    int           // segments kept, including the current one
+	MaxAge      time.Duration // segments last written longer ago are deleted; 0 keeps them
+}
+
+// Log is a segmented, append-only log of a store's changes. It implements
+// state.Journal.
+type Log struct {
+	dir  string
+	opts Options
+
+	mu     sync.Mutex
+	file   *os.File // current segment, nil until Start
+	size   int64
+	seq    uint64 // sequence number of the newest segment
+	failed bool   // the last write failed and was logged
+	closed bool
+}
+
+// Open opens the log in dir, creating the directory if needed.
+func Open(dir string, opts Options) (*Log, error) {
+	if opts.SegmentSize <= 0 {
+		opts.SegmentSize = DefaultSegmentSize
+	}
+	if opts.Segments <= 0 {
+		opts.Segments = DefaultSegments
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	l := &Log{dir: dir, opts: opts}
+	segs, err := l.segments()
+	if err != nil {
+		return nil, err
+	}
+	if len(segs) > 0 {
+		l.seq = segs[len(segs)-1]
+	}
+	return l, nil
+}
+
+// Load reads the retained segments back: the snapshot the oldest readable one
+// begins with and every diff since. A segment cut short, such as by a crash,
+// breaks the chain, so the state starts again from the next segment's
+// snapshot. ok is false if there was nothing to load.
+func (l *Log) Load() (snap state.Snapshot, diffs []*state.Diff, ok bool, err error) {
+	segs, err := l.segments()
+	if err != nil {
+		return snap, nil, false, err
+	}
+	broken := true
+	for _, seq := range segs {
+		s, ds, complete, err := l.readSegment(seq)
+		if err != nil {
+			log.Printf("eventlog: %v", err)
+		}
+		if s == nil {
+			broken = true
+			continue
+		}
+		if broken {
+			snap, diffs, ok = *s, nil, true
+		}
+		diffs = append(diffs, ds...)
+		broken = !complete
+	}
+	return snap, diffs, ok, nil
+}
+
+// readSegment reads one segment. It returns what it could read before any
+// damage, and reports whether the whole segment was intact.
+func (l *Log) readSegment(seq uint64) (*state.Snapshot, []*state.Diff, bool, error) {
+	path := l.path(seq)
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	defer f.Close()
+
+	var snap *state.Snapshot
+	var diffs []*state.Diff
+	r := bufio.NewReader(f)
+	for n := 1; ; n++ {
+		line, err := r.ReadBytes('\n')
+		if err == io.EOF {
+			if len(line) > 0 {
+				return snap, diffs, false, fmt.Errorf("%s: line %d: incomplete record", path, n)
+			}
+			return snap, diffs, snap != nil, nil
+		}
+		if err != nil {
+			return snap, diffs, false, fmt.Errorf("%s: %w", path, err)
+		}
+
+		var rec struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal(line, &rec); err != nil {
+			return snap, diffs, false, fmt.Errorf("%s: line %d: %w", path, n, err)
+		}
+		switch {
+		case n == 1 && rec.Type == "snapshot":
+			var s state.Snapshot
+			if err = json.Unmarshal(line, &s); err == nil {
+				snap = &s
+			}
+		case n > 1 && rec.Type == "diff":
+			var d state.Diff
+			if err = json.Unmarshal(line, &d); err == nil {
+				diffs = append(diffs, &d)
+			}
+		default:
+			err = fmt.Errorf("unexpected %q record", rec.Type)
+		}
+		if err != nil {
+			return snap, diffs, false, fmt.Errorf("%s: line %d: %w", path, n, err)
+		}
+	}
+}
+
+// Start begins a new segment holding snap, the store's current state.
+func (l *Log) Start(snap state.Snapshot) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.report(l.startSegment(snap))
+}
+
+// Record appends a diff, starting a new segment with snap, the state after
+// it, once the current segment is full.
+func (l *Log) Record(d *state.Diff, snap state.Snapshot) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil || l.closed {
+		return
+	}
+	err := l.write(d)
+	if err == nil && l.size >= l.opts.SegmentSize {
+		snap.Type = "snapshot"
+		snap.Timestamp = d.Timestamp
+		err = l.startSegment(snap)
+	}
+	l.report(err)
+}
+
+// Close closes the current segment. Later changes are not recorded.
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.closed = true
+	if l.file == nil {
+		return nil
+	}
+	err := errors.Join(l.file.Sync(), l.file.Close())
+	l.file = nil
+	return err
+}
+
+// startSegment closes the current segment, opens the next one with snap and
+// deletes segments past the retention limits. l.mu must be held.
+func (l *Log) startSegment(snap state.Snapshot) error {
+	if l.closed {
+		return nil
+	}
+	if l.file != nil {
+		err := errors.Join(l.file.Sync(), l.file.Close())
+		l.file = nil
+		if err != nil {
+			return err
+		}
+	}
+	l.seq++
+	f, err := os.OpenFile(l.path(l.seq), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+	l.file, l.size = f, 0
+	if err := l.write(snap); err != nil {
+		return err
+	}
+	return l.prune()
+}
+
+// write appends one record to the current segment. l.mu must be held.
+func (l *Log) write(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	n, err := l.file.Write(append(data, '\n'))
+	l.size += int64(n)
+	return err
+}
+
+// prune deletes the oldest segments beyond the segment limit, and segments
+// not written to within MaxAge. The current segment is always kept. l.mu must
+// be held.
+func (l *Log) prune() error {
+	segs, err := l.segments()
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for i, seq := range segs {
+		if seq == l.seq {
+			continue
+		}
+		drop := len(segs)-i > l.opts.Segments
+		if !drop && l.opts.MaxAge > 0 {
+			info, err := os.Stat(l.path(seq))
+			drop = err == nil && time.Since(info.ModTime()) > l.opts.MaxAge
+		}
+		if drop {
+			errs = append(errs, os.Remove(l.path(seq)))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// report logs a write failure once, and its recovery.
+func (l *Log) report(err error) {
+	switch {
+	case err != nil && !l.failed:
+		log.Printf("eventlog: %v", err)
+	case err == nil && l.failed:
+		log.Printf("eventlog: writing to %s again", l.dir)
+	}
+	l.failed = err != nil
+}
+
+// segments returns the sequence numbers of the segments on disk, oldest first.
+func (l *Log) segments() ([]uint64, error) {
+	entries, err := os.ReadDir(l.dir)
+	if err != nil {
+		return nil, err
+	}
+	var segs []uint64
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), segmentExt)
+		if !ok || !e.Type().IsRegular() {
+			continue
+		}
+		if seq, err := strconv.ParseUint(name, 10, 64); err == nil {
+			segs = append(segs, seq)
+		}
+	}
+	slices.Sort(segs)
+	return segs, nil
+}
+
+func (l *Log) path(seq uint64) string {
+	return filepath.Join(l.dir, fmt.Sprintf("%020d%s", seq, segmentExt))
+}
+
+var _ state.Journal = (*Log)(nil)
@@ -0,0 +1,156 @@
+package eventlog
+
+import (
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/gronitab/zeppelin/internal/state"
+)
+
+// update reports a polecat whose metadata changes with i.
+func update(s *state.Store, i int) {
+	s.UpdateSource("topology", state.Fragment{Nodes: []state.Node{
+		{ID: "mayor", Type: "mayor", State: "running"},
+		{ID: "gastown/polecats/rust", Type: "polecat", State: "working", Metadata: map[string]string{"n": strconv.Itoa(i)}},
+	}})
+}
+
+// restored loads the log in dir into a new store.
+func restored(t *testing.T, dir string) (*state.Store, bool) {
+	t.Helper()
+	l, err := Open(dir, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	snap, diffs, ok, err := l.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := state.NewStore()
+	if ok {
+		s.Restore(snap, diffs)
+	}
+	return s, ok
+}
+
+func polecatN(s *state.Store) string {
+	for _, n := range s.GetSnapshot().Nodes {
+		if n.Type == "polecat" {
+			return n.Metadata["n"]
+		}
+	}
+	return ""
+}
+
+func TestLogRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	l, err := Open(dir, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := state.NewStore()
+	s.SetJournal(l)
+	for i := range 5 {
+		update(s, i)
+	}
+	s.AppendActivity(state.Activity{Event: "test", Agent: "mayor"})
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, ok := restored(t, dir)
+	if !ok {
+		t.Fatal("expected a state to load")
+	}
+	if n := polecatN(r); n != "4" {
+		t.Errorf("expected the last update restored, got n=%q", n)
+	}
+	if acts := r.GetSnapshot().Activity; len(acts) == 0 || acts[len(acts)-1].Event != "test" {
+		t.Errorf("expected activity restored, got %+v", acts)
+	}
+	for _, n := range r.GetSnapshot().Nodes {
+		if !n.Stale {
+			t.Errorf("expected restored node %s to be stale", n.ID)
+		}
+	}
+}
+
+func TestLogEmpty(t *testing.T) {
+	if _, ok := restored(t, t.TempDir()); ok {
+		t.Error("expected nothing to load from an empty directory")
+	}
+}
+
+func TestLogCompactsAndPrunes(t *testing.T) {
+	dir := t.TempDir()
+	l, err := Open(dir, Options{SegmentSize: 1024, Segments: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := state.NewStore()
+	s.SetJournal(l)
+	for i := range 100 {
+		update(s, i)
+	}
+	l.Close()
+
+	segs, err := l.segments()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(segs) != 3 {
+		t.Errorf("expected 3 segments kept, got %d", len(segs))
+	}
+	if n := polecatN(mustRestore(t, dir)); n != "99" {
+		t.Errorf("expected the last update restored from the kept segments, got n=%q", n)
+	}
+}
+
+func TestLogSurvivesDamage(t *testing.T) {
+	dir := t.TempDir()
+	l, err := Open(dir, Options{SegmentSize: 1024})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := state.NewStore()
+	s.SetJournal(l)
+	for i := range 30 {
+		update(s, i)
+	}
+	l.Close()
+	segs, _ := l.segments()
+	if len(segs) < 3 {
+		t.Fatalf("expected several segments, got %d", len(segs))
+	}
+
+	// A crash mid-write leaves a partial line at the end.
+	last := l.path(segs[len(segs)-1])
+	f, err := os.OpenFile(last, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"type":"diff","nodes_upd`)
+	f.Close()
+	if n := polecatN(mustRestore(t, dir)); n != "29" {
+		t.Errorf("expected the partial record skipped, got n=%q", n)
+	}
+
+	// A damaged segment breaks the chain; later segments start over from
+	// their own snapshot.
+	if err := os.WriteFile(l.path(segs[0]), []byte("garbage\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if n := polecatN(mustRestore(t, dir)); n != "29" {
+		t.Errorf("expected the state restored past the damaged segment, got n=%q", n)
+	}
+}
+
+func mustRestore(t *testing.T, dir string) *state.Store {
+	t.Helper()
+	s, ok := restored(t, dir)
+	if !ok {
+		t.Fatal("expected a state to load")
+	}
+	return s
+}
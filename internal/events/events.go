@@ -0,0 +1,112 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gronitab/zeppelin/internal/state"
+)
+
+// event is a single line of the Gas Town `.events.jsonl` feed.
+type event struct {
+	Timestamp  time.Time      `json:"ts"`
+	Source     string         `json:"source"`
+	Type       string         `json:"type"`
+	Actor      string         `json:"actor"`
+	Payload    map[string]any `json:"payload"`
+	Visibility string         `json:"visibility"`
+}
+
+// eventNames maps Gas Town event types to the activity events the frontend
+// knows how to render. Unknown types pass through unchanged.
+var eventNames = map[string]string{
+	"sling":           "bead_hooked",
+	"hook":            "bead_hooked",
+	"unhook":          "state_change",
+	"done":            "state_change",
+	"handoff":         "state_change",
+	"spawn":           "polecat_spawned",
+	"session_start":   "polecat_spawned",
+	"kill":            "polecat_nuked",
+	"nuke":            "polecat_nuked",
+	"session_death":   "polecat_nuked",
+	"merged":          "merge_complete",
+	"merge_failed":    "merge_failed",
+	"escalation_sent": "escalation",
+	"escalation":      "escalation",
+	"bead_closed":     "bead_closed",
+	"bead_created":    "bead_opened",
+}
+
+// polledTypes lists the event types a poller source reports with more
+// detail, so the feed's copy would only duplicate them: mail, which the mail
+// source reads from the inboxes along with its sender and recipient.
+var polledTypes = map[string]bool{
+	"mail": true,
+}
+
+// parseLine decodes one events line into an activity. It reports false for
+// blank lines, malformed JSON, audit-only events and types left to the
+// poller.
+func parseLine(line []byte) (state.Activity, bool) {
+	var ev event
+	if err := json.Unmarshal(line, &ev); err != nil || ev.Type == "" {
+		return state.Activity{}, false
+	}
+	if ev.Visibility == "audit" || polledTypes[ev.Type] {
+		return state.Activity{}, false
+	}
+
+	name, ok := eventNames[ev.Type]
+	if !ok {
+		name = ev.Type
+	}
+	ts := ev.Timestamp
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+	a := state.Activity{
+		Timestamp: ts,
+		Event:     name,
+		Agent:     strings.TrimSuffix(ev.Actor, "/"),
+		Detail:    describe(ev),
+	}
+	// The merge queue reports merges too; a shared key records them once.
+	if branch, _ := ev.Payload["branch"].(string); branch != "" && (ev.Type == "merged" || ev.Type == "merge_failed") {
+		a.Key = state.MergeKey(name, branch)
+	}
+	return a, true
+}
+
+// describe renders the event payload as a short human-readable detail.
+func describe(ev event) string {
+	str := func(k string) string {
+		v, _ := ev.Payload[k].(string)
+		return v
+	}
+
+	switch ev.Type {
+	case "sling":
+		if str("target") != "" {
+			return fmt.Sprintf("%s → %s", str("bead"), str("target"))
+		}
+		return str("bead")
+	case "hook", "unhook", "done":
+		return ev.Type + " " + str("bead")
+	}
+
+	// Generic fallback: key=value pairs in a stable order.
+	keys := make([]string, 0, len(ev.Payload))
+	for k := range ev.Payload {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, fmt.Sprintf("%s=%v", k, ev.Payload[k]))
+	}
+	return strings.Join(parts, " ")
+}
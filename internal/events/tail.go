@@ -0,0 +1,161 @@
+// Package events follows the Gas Town `.events.jsonl` feed and turns each
+// event into an activity entry as soon as it is written.
+package events
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/gronitab/zeppelin/internal/state"
+)
+
+// backfillBytes is how much of an existing file is replayed on startup, so the
+// feed shows recent history instead of starting empty.
+const backfillBytes = 16 * 1024
+
+// Tailer follows an events file across rotation and truncation.
+type Tailer struct {
+	// Since, if set before Run, skips backfilled events written at or
+	// before it, such as activity a restored store already holds.
+	Since time.Time
+
+	path       string
+	interval   time.Duration
+	onActivity func(state.Activity)
+
+	file    *os.File
+	info    os.FileInfo
+	offset  int64
+	partial []byte
+	// skipPartial drops the first line read after a backfill seek, which
+	// usually starts mid-line.
+	skipPartial bool
+	// since is Since while reading the file opened with backfill.
+	since time.Time
+}
+
+// NewTailer creates a tailer for the given events file. onActivity is called
+// from the tailer's goroutine for every event read.
+func NewTailer(path string, onActivity func(state.Activity)) *Tailer {
+	return &Tailer{path: path, interval: 250 * time.Millisecond, onActivity: onActivity}
+}
+
+// Run follows the file until the context is cancelled.
+func (t *Tailer) Run(ctx context.Context) {
+	defer t.close()
+
+	t.open(true)
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+
+	for {
+		t.poll()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (t *Tailer) poll() {
+	if t.file == nil {
+		// The file may not exist yet; new files are read from the start.
+		t.open(false)
+		if t.file == nil {
+			return
+		}
+	}
+
+	info, err := os.Stat(t.path)
+	switch {
+	case err != nil:
+		// Removed; keep what we have and wait for it to reappear.
+		t.read()
+		t.close()
+		return
+	case !os.SameFile(info, t.info):
+		// Rotated: finish the old file, then follow the new one from the start.
+		t.read()
+		t.close()
+		t.open(false)
+	case info.Size() < t.offset:
+		// Truncated in place.
+		t.offset = 0
+		t.partial = nil
+		if _, err := t.file.Seek(0, io.SeekStart); err != nil {
+			log.Printf("events: seek %s: %v", t.path, err)
+			t.close()
+			return
+		}
+	}
+	t.read()
+}
+
+// open opens the events file. When backfill is set, reading starts near the
+// end of the file instead of at the beginning.
+func (t *Tailer) open(backfill bool) {
+	f, err := os.Open(t.path)
+	if err != nil {
+		return
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return
+	}
+	t.file, t.info, t.offset, t.partial, t.skipPartial = f, info, 0, nil, false
+	t.since = time.Time{}
+	if backfill {
+		t.since = t.Since
+	}
+
+	if backfill && info.Size() > backfillBytes {
+		t.offset, _ = f.Seek(info.Size()-backfillBytes, io.SeekStart)
+		t.skipPartial = true
+	}
+}
+
+func (t *Tailer) close() {
+	if t.file != nil {
+		t.file.Close()
+		t.file = nil
+	}
+}
+
+// read consumes everything appended since the last read and emits one
+// activity per complete line.
+func (t *Tailer) read() {
+	if t.file == nil {
+		return
+	}
+	buf, err := io.ReadAll(t.file)
+	if err != nil {
+		log.Printf("events: read %s: %v", t.path, err)
+	}
+	t.offset += int64(len(buf))
+	if len(buf) == 0 {
+		return
+	}
+
+	data := append(t.partial, buf...)
+	lines := bytes.Split(data, []byte("\n"))
+	// The last element is an incomplete line (or empty after a trailing newline).
+	t.partial = append([]byte(nil), lines[len(lines)-1]...)
+	lines = lines[:len(lines)-1]
+
+	if t.skipPartial && len(lines) > 0 {
+		lines = lines[1:]
+		t.skipPartial = false
+	}
+
+	for _, line := range lines {
+		if a, ok := parseLine(bytes.TrimSpace(line)); ok && a.Timestamp.After(t.since) {
+			t.onActivity(a)
+		}
+	}
+}
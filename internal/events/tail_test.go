@@ -0,0 +1,134 @@
+package events
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gronitab/zeppelin/internal/state"
+)
+
+func TestParseLine(t *testing.T) {
+	a, ok := parseLine([]byte(`{"ts":"2026-02-20T07:14:55Z","source":"gt","type":"sling","actor":"mayor/","payload":{"bead":"zep-4zs","target":"zeppelin/polecats/rust"},"visibility":"feed"}`))
+	if !ok {
+		t.Fatal("expected event to parse")
+	}
+	if a.Event != "bead_hooked" {
+		t.Errorf("expected event 'bead_hooked', got %q", a.Event)
+	}
+	if a.Agent != "mayor" {
+		t.Errorf("expected agent 'mayor', got %q", a.Agent)
+	}
+	if a.Detail != "zep-4zs → zeppelin/polecats/rust" {
+		t.Errorf("unexpected detail %q", a.Detail)
+	}
+}
+
+func TestParseLineSkips(t *testing.T) {
+	for _, line := range []string{
+		``,
+		`not json`,
+		`{"type":""}`,
+		`{"type":"patrol","visibility":"audit"}`,
+		`{"type":"mail","actor":"mayor/","payload":{"to":"gastown/witness","subject":"hi"}}`,
+	} {
+		if _, ok := parseLine([]byte(line)); ok {
+			t.Errorf("expected %q to be skipped", line)
+		}
+	}
+}
+
+func TestParseLineUnknownType(t *testing.T) {
+	a, ok := parseLine([]byte(`{"type":"nudge","actor":"zeppelin/witness","payload":{"target":"zeppelin/polecats/rust"}}`))
+	if !ok {
+		t.Fatal("expected event to parse")
+	}
+	if a.Event != "nudge" {
+		t.Errorf("expected unknown type to pass through, got %q", a.Event)
+	}
+	if a.Detail != "target=zeppelin/polecats/rust" {
+		t.Errorf("unexpected detail %q", a.Detail)
+	}
+}
+
+func TestParseLineMergeKey(t *testing.T) {
+	a, ok := parseLine([]byte(`{"type":"merged","actor":"gastown/refinery","payload":{"branch":"polecat/toast/gt-12","mr":"mr-1"}}`))
+	if !ok {
+		t.Fatal("expected event to parse")
+	}
+	if a.Event != "merge_complete" || a.Key != state.MergeKey("merge_complete", "polecat/toast/gt-12") {
+		t.Errorf("expected a merge keyed by branch, got %+v", a)
+	}
+}
+
+func TestTailerFollowsAppendsTruncationAndRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".events.jsonl")
+	var got []string
+	tl := NewTailer(path, func(a state.Activity) { got = append(got, a.Detail) })
+	defer tl.close()
+
+	write := func(flag int, s string) {
+		t.Helper()
+		f, err := os.OpenFile(path, flag|os.O_WRONLY|os.O_CREATE, 0o644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		if _, err := f.WriteString(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Missing file: nothing happens.
+	tl.poll()
+
+	write(os.O_APPEND, `{"type":"hook","payload":{"bead":"a"}}`+"\n"+`{"type":"hook","payload":{"bead":"b"}}`)
+	tl.poll()
+	if len(got) != 1 {
+		t.Fatalf("expected partial line to be held back, got %v", got)
+	}
+	write(os.O_APPEND, "\n")
+	tl.poll()
+
+	// Truncation.
+	write(os.O_TRUNC, `{"type":"hook","payload":{"bead":"c"}}`+"\n")
+	tl.poll()
+
+	// Rotation.
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	write(os.O_APPEND, `{"type":"hook","payload":{"bead":"d"}}`+"\n")
+	tl.poll()
+
+	want := []string{"hook a", "hook b", "hook c", "hook d"}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("entry %d: expected %q, got %q", i, want[i], got[i])
+		}
+	}
+}
+
+func TestTailerBackfillSkipsRestored(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".events.jsonl")
+	feed := `{"ts":"2026-02-20T07:00:00Z","type":"hook","payload":{"bead":"a"}}` + "\n" +
+		`{"ts":"2026-02-20T07:05:00Z","type":"hook","payload":{"bead":"b"}}` + "\n" +
+		`{"ts":"2026-02-20T07:10:00Z","type":"hook","payload":{"bead":"c"}}` + "\n"
+	if err := os.WriteFile(path, []byte(feed), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	tl := NewTailer(path, func(a state.Activity) { got = append(got, a.Detail) })
+	defer tl.close()
+	tl.Since = time.Date(2026, 2, 20, 7, 5, 0, 0, time.UTC)
+
+	tl.open(true)
+	tl.poll()
+	if len(got) != 1 || got[0] != "hook c" {
+		t.Errorf("expected only the event after the restored activity, got %v", got)
+	}
+}
@@ -0,0 +1,70 @@
+package poller
+
+import (
+	"strings"
+
+	"github.com/gronitab/zeppelin/internal/state"
+)
+
+// canonicalAgentID maps a gt agent address ("mayor/", "zeppelin/rust",
+// "zeppelin/polecats/rust", "zeppelin/witness") to the node ID used for that
+// agent in the graph. It returns "" for addresses it cannot place.
+func canonicalAgentID(addr string) string {
+	addr = strings.Trim(strings.TrimSpace(addr), "/")
+	if addr == "" {
+		return ""
+	}
+
+	parts := strings.Split(addr, "/")
+	switch len(parts) {
+	case 1:
+		switch parts[0] {
+		case "mayor", "deacon", "overseer":
+			return parts[0]
+		case "human":
+			return "overseer"
+		}
+		return ""
+	case 2:
+		rig, name := parts[0], parts[1]
+		switch name {
+		case "witness", "refinery":
+			return rig + "/" + name
+		}
+		// Short polecat form: <rig>/<name>.
+		return rig + "/polecats/" + name
+	case 3:
+		switch parts[1] {
+		case "polecats", "crew":
+			return addr
+		}
+	}
+	return ""
+}
+
+// assigneeID maps a bead's assignee to an agent node ID. Besides the
+// addresses canonicalAgentID accepts, a bare polecat name is placed in the
+// rig that owns the bead. It returns "" for assignees it cannot place.
+func assigneeID(assignee, rig string) string {
+	if id := canonicalAgentID(assignee); id != "" {
+		return id
+	}
+	name := strings.Trim(strings.TrimSpace(assignee), "/")
+	if rig == "" || name == "" || strings.ContainsAny(name, "/ @") {
+		return ""
+	}
+	return rig + "/polecats/" + name
+}
+
+// mailAddress is the inverse of canonicalAgentID: the address gt mail
+// commands accept for an agent node.
+func mailAddress(n state.Node) string {
+	switch n.Type {
+	case "mayor", "deacon":
+		return n.ID + "/"
+	case "polecat":
+		return n.Rig + "/" + n.Label
+	default:
+		return n.ID
+	}
+}
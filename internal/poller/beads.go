@@ -0,0 +1,137 @@
+package poller
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gronitab/zeppelin/internal/state"
+)
+
+// beadDB is a beads database the poller lists: the town's own, or a rig's.
+type beadDB struct {
+	rig string // empty for the town database
+	dir string
+}
+
+// beadDBs returns the databases to list: the town root, then every rig that
+// has a .beads directory, in rig name order.
+func (p *Poller) beadDBs() []beadDB {
+	dbs := []beadDB{{dir: p.root}}
+	for _, rig := range p.rigNames() {
+		if dir := rigBeadsDir(filepath.Join(p.root, rig)); dir != "" {
+			dbs = append(dbs, beadDB{rig: rig, dir: dir})
+		}
+	}
+	return dbs
+}
+
+// rigNames returns the rigs known from the topology or routed to in the
+// town's routes file, sorted by name.
+func (p *Poller) rigNames() []string {
+	rigs := make(map[string]bool)
+	for _, n := range p.latestNodes(func(n state.Node) bool { return n.Rig != "" && n.Type != "bead" }) {
+		rigs[n.Rig] = true
+	}
+	for _, rig := range p.loadBeadRoutes() {
+		if rig != "" {
+			rigs[rig] = true
+		}
+	}
+
+	names := make([]string, 0, len(rigs))
+	for rig := range rigs {
+		names = append(names, rig)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// beadTracker remembers the last list of each beads database, so the beads
+// of a database that fails to list are kept, marked stale, until it recovers.
+type beadTracker struct {
+	lists map[string][]beadInfo // database directory -> last successful list
+}
+
+// fillFailed replaces each failed list with the database's last successful
+// one, reporting which lists are stale, and remembers the successful ones.
+func (t *beadTracker) fillFailed(dbs []beadDB, lists [][]beadInfo, errs []error) []bool {
+	last := t.lists
+	t.lists = make(map[string][]beadInfo, len(dbs))
+	stale := make([]bool, len(dbs))
+	for i, db := range dbs {
+		if errs[i] == nil {
+			t.lists[db.dir] = lists[i]
+			continue
+		}
+		if prev, ok := last[db.dir]; ok {
+			t.lists[db.dir] = prev
+			lists[i], stale[i] = prev, true
+		}
+	}
+	return stale
+}
+
+// rigBeadsDir returns the directory to run bd in for a rig: the rig itself
+// when it holds a .beads directory, else the mayor's clone of the rig. It
+// returns "" when the rig has no beads database of its own.
+func rigBeadsDir(rigDir string) string {
+	for _, dir := range []string{rigDir, filepath.Join(rigDir, "mayor", "rig")} {
+		if fi, err := os.Stat(filepath.Join(dir, ".beads")); err == nil && fi.IsDir() {
+			return dir
+		}
+	}
+	return ""
+}
+
+// beadRoute maps a bead ID prefix to the rig whose database owns it, as
+// listed in the town's .beads/routes.jsonl.
+type beadRoute struct {
+	Prefix string `json:"prefix"`
+	Path   string `json:"path"`
+}
+
+// beadRoutes maps bead ID prefixes to rig names. The town's own prefixes map
+// to "".
+type beadRoutes map[string]string
+
+// loadBeadRoutes reads the town's routes file. A missing or unreadable file
+// yields no routes.
+func (p *Poller) loadBeadRoutes() beadRoutes {
+	routes := make(beadRoutes)
+	f, err := os.Open(filepath.Join(p.root, ".beads", "routes.jsonl"))
+	if err != nil {
+		return routes
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var r beadRoute
+		if json.Unmarshal(sc.Bytes(), &r) != nil || r.Prefix == "" {
+			continue
+		}
+		// The path is relative to the town root, e.g. "gastown/mayor/rig";
+		// its first element names the rig.
+		rig, _, _ := strings.Cut(filepath.ToSlash(filepath.Clean(r.Path)), "/")
+		if rig == "." {
+			rig = ""
+		}
+		routes[r.Prefix] = rig
+	}
+	return routes
+}
+
+// rigFor returns the rig owning the bead ID by its longest matching prefix.
+func (r beadRoutes) rigFor(id string) (string, bool) {
+	best, rig, ok := 0, "", false
+	for prefix, name := range r {
+		if len(prefix) > best && strings.HasPrefix(id, prefix) {
+			best, rig, ok = len(prefix), name, true
+		}
+	}
+	return rig, ok
+}
@@ -0,0 +1,163 @@
+package poller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gronitab/zeppelin/internal/state"
+)
+
+// maxStderr bounds how much of a failing command's stderr is kept for the
+// health report.
+const maxStderr = 2048
+
+func (p *Poller) runCmd(ctx context.Context, name string, args ...string) (string, error) {
+	return p.runCmdIn(ctx, p.root, name, args...)
+}
+
+// runCmdIn runs a command in the given directory. It waits for a free slot
+// under the concurrency limit and is killed when ctx is cancelled or the
+// command's timeout elapses. Every run is recorded for the health report of
+// the source collecting under ctx.
+func (p *Poller) runCmdIn(ctx context.Context, dir, name string, args ...string) (string, error) {
+	select {
+	case p.sem <- struct{}{}:
+		defer func() { <-p.sem }()
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+
+	rec := recorderFrom(ctx)
+	cmdCtx, cancel := context.WithTimeout(ctx, p.timeoutFor(name, args))
+	defer cancel()
+
+	start := time.Now()
+	stdout, stderr, err := p.opts.Runner.Run(cmdCtx, Command{Dir: dir, Name: name, Args: args})
+	h := state.CommandHealth{
+		Command:    p.commandLine(dir, name, args),
+		LastRun:    start,
+		DurationMS: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		if cmdCtx.Err() == context.DeadlineExceeded {
+			err = fmt.Errorf("timed out after %v", p.timeoutFor(name, args))
+		}
+		h.LastError = err.Error()
+		h.Stderr = truncate(strings.TrimSpace(string(stderr)), maxStderr)
+		rec.record(h)
+		return "", fmt.Errorf("%s: %w", h.Command, err)
+	}
+	h.LastSuccess = start
+	rec.record(h)
+	return strings.TrimSpace(string(stdout)), nil
+}
+
+// commandLine renders a command for logs and the health report, noting the
+// directory when it is not the town root.
+func (p *Poller) commandLine(dir, name string, args []string) string {
+	line := strings.Join(append([]string{name}, args...), " ")
+	if dir != p.root {
+		if rel, err := filepath.Rel(p.root, dir); err == nil {
+			dir = rel
+		}
+		line += " (in " + dir + ")"
+	}
+	return line
+}
+
+// timeoutFor returns the timeout of the longest Timeouts key matching the
+// command line, or CommandTimeout if none does.
+func (p *Poller) timeoutFor(name string, args []string) time.Duration {
+	timeout, best := p.opts.CommandTimeout, -1
+	words := append([]string{name}, args...)
+	for key, d := range p.opts.Timeouts {
+		prefix := strings.Fields(key)
+		if len(prefix) <= best || len(prefix) > len(words) {
+			continue
+		}
+		match := true
+		for i, w := range prefix {
+			if words[i] != w {
+				match = false
+				break
+			}
+		}
+		if match {
+			timeout, best = d, len(prefix)
+		}
+	}
+	return timeout
+}
+
+// parallel calls fn for each index in [0, n) concurrently and waits for all
+// calls to return. Commands started by fn still respect the concurrency limit.
+func parallel(n int, fn func(i int)) {
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Go(func() { fn(i) })
+	}
+	wg.Wait()
+}
+
+// allFailed returns the joined errors if every one of several independent
+// lookups failed, and nil if any succeeded or there were none.
+func allFailed(errs []error) error {
+	for _, err := range errs {
+		if err == nil {
+			return nil
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// cmdRecorder collects the command runs and schema warnings of one source
+// collection.
+type cmdRecorder struct {
+	mu       sync.Mutex
+	cmds     []state.CommandHealth
+	warnings []string
+}
+
+type recorderKey struct{}
+
+func withRecorder(ctx context.Context, rec *cmdRecorder) context.Context {
+	return context.WithValue(ctx, recorderKey{}, rec)
+}
+
+func recorderFrom(ctx context.Context) *cmdRecorder {
+	rec, _ := ctx.Value(recorderKey{}).(*cmdRecorder)
+	return rec
+}
+
+func (r *cmdRecorder) record(h state.CommandHealth) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cmds = append(r.cmds, h)
+}
+
+func (r *cmdRecorder) warn(w string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !slices.Contains(r.warnings, w) {
+		r.warnings = append(r.warnings, w)
+	}
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "…"
+}
@@ -0,0 +1,71 @@
+package poller
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gronitab/zeppelin/internal/state"
+)
+
+func TestTimeoutFor(t *testing.T) {
+	p := New(state.NewStore(), t.TempDir(), Options{
+		CommandTimeout: time.Second,
+		Timeouts: map[string]time.Duration{
+			"gt":                      2 * time.Second,
+			"gt status":               3 * time.Second,
+			"gt status --json --fast": 4 * time.Second,
+			"bd list":                 5 * time.Second,
+		},
+	}, func(*state.Diff) {})
+
+	cases := []struct {
+		line []string
+		want time.Duration
+	}{
+		{[]string{"gt", "status", "--json"}, 3 * time.Second},
+		{[]string{"gt", "status", "--json", "--fast"}, 4 * time.Second},
+		{[]string{"gt", "mail", "inbox"}, 2 * time.Second},
+		{[]string{"gt"}, 2 * time.Second},
+		{[]string{"bd", "list", "--json"}, 5 * time.Second},
+		{[]string{"bd", "show"}, time.Second},
+		{[]string{"gtx", "status"}, time.Second},
+	}
+	for _, c := range cases {
+		if got := p.timeoutFor(c.line[0], c.line[1:]); got != c.want {
+			t.Errorf("timeoutFor(%v) = %v, want %v", c.line, got, c.want)
+		}
+	}
+}
+
+func TestConcurrencyLimit(t *testing.T) {
+	var mu sync.Mutex
+	running, peak := 0, 0
+	runner := runnerFunc(func(ctx context.Context, c Command) ([]byte, []byte, error) {
+		mu.Lock()
+		running++
+		peak = max(peak, running)
+		mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return nil, nil, nil
+	})
+	p := New(state.NewStore(), t.TempDir(), Options{Runner: runner, MaxConcurrent: 2}, func(*state.Diff) {})
+
+	parallel(8, func(int) { p.runCmd(context.Background(), "gt", "status") })
+	if peak != 2 {
+		t.Errorf("expected at most 2 commands at once, and 2 reached, got a peak of %d", peak)
+	}
+
+	// A command waiting for a slot gives up when its context is done.
+	p.sem <- struct{}{}
+	p.sem <- struct{}{}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := p.runCmd(ctx, "gt", "status"); err != context.DeadlineExceeded {
+		t.Errorf("expected a waiting command to time out, got %v", err)
+	}
+}
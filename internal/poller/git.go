@@ -0,0 +1,214 @@
+package poller
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gronitab/zeppelin/internal/state"
+)
+
+const (
+	// recentCommits is how many commits a polecat's metadata lists.
+	recentCommits = 3
+	// commitScan is how many commits each poll reads when looking for new
+	// ones.
+	commitScan = 10
+)
+
+// gitCommit is one entry from `git log`.
+type gitCommit struct {
+	Hash    string
+	Short   string
+	Author  string
+	Time    time.Time
+	Subject string
+}
+
+// worktreeInfo is what the git source reads from a polecat's worktree.
+type worktreeInfo struct {
+	Branch  string
+	Dirty   int // changed or untracked files
+	Ahead   int
+	Behind  int
+	Base    string // branch ahead/behind are measured against; "" if unknown
+	Commits []gitCommit
+}
+
+// gitTracker remembers each polecat's last seen HEAD, so only new commits
+// produce activity.
+type gitTracker struct {
+	heads map[string]string // polecat ID -> HEAD hash
+}
+
+// collectGit annotates every polecat with its worktree's branch, recent
+// commits, ahead/behind counts against the rig's main branch and dirty
+// status, and reports commits made since the last poll as commit activities.
+func (p *Poller) collectGit(ctx context.Context) (state.Fragment, error) {
+	t := &p.git
+	if t.heads == nil {
+		t.heads = make(map[string]string)
+	}
+
+	polecats := p.latestNodes(func(n state.Node) bool {
+		return n.Type == "polecat" && n.State != "nuked"
+	})
+
+	infos := make([]worktreeInfo, len(polecats))
+	found := make([]bool, len(polecats))
+	errs := make([]error, len(polecats))
+	parallel(len(polecats), func(i int) {
+		pc := polecats[i]
+		dir := filepath.Join(p.root, pc.Rig, "polecats", pc.Label)
+		if _, err := os.Stat(dir); err != nil {
+			return
+		}
+		found[i] = true
+		infos[i], errs[i] = p.readWorktree(ctx, dir)
+	})
+
+	annotations := make(map[string]state.Annotation)
+	var acts []state.Activity
+	for i, pc := range polecats {
+		if !found[i] || errs[i] != nil {
+			continue
+		}
+		info := infos[i]
+		annotations[pc.ID] = state.Annotation{Metadata: info.metadata()}
+		acts = append(acts, t.newCommits(pc, info.Commits)...)
+	}
+
+	// Forget polecats that are gone.
+	current := make(map[string]bool, len(polecats))
+	for _, pc := range polecats {
+		current[pc.ID] = true
+	}
+	for id := range t.heads {
+		if !current[id] {
+			delete(t.heads, id)
+		}
+	}
+
+	return state.Fragment{Annotations: annotations, Activities: acts}, allFailed(errs)
+}
+
+// readWorktree reads a worktree's branch, dirty files, position relative to
+// the main branch and latest commits.
+func (p *Poller) readWorktree(ctx context.Context, dir string) (worktreeInfo, error) {
+	var info worktreeInfo
+
+	status, err := p.runCmdIn(ctx, dir, "git", "status", "--porcelain=v2", "--branch")
+	if err != nil {
+		return info, err
+	}
+	for _, line := range strings.Split(status, "\n") {
+		switch {
+		case strings.HasPrefix(line, "# branch.head "):
+			info.Branch = strings.TrimPrefix(line, "# branch.head ")
+		case line != "" && !strings.HasPrefix(line, "#"):
+			info.Dirty++
+		}
+	}
+
+	out, err := p.runCmdIn(ctx, dir, "git", "log", "-"+strconv.Itoa(commitScan), "--format=%H%x1f%h%x1f%an%x1f%at%x1f%s")
+	if err == nil {
+		// A branch without commits yet has no log.
+		info.Commits = parseGitLog(out)
+	}
+
+	// Ahead/behind are best effort: the rig may have no origin/HEAD, in
+	// which case for-each-ref prints nothing.
+	base, err := p.runCmdIn(ctx, dir, "git", "for-each-ref", "--format=%(symref:short)", "refs/remotes/origin/HEAD")
+	if err == nil && base != "" {
+		counts, err := p.runCmdIn(ctx, dir, "git", "rev-list", "--left-right", "--count", base+"...HEAD")
+		if err == nil {
+			if _, err := fmt.Sscanf(counts, "%d %d", &info.Behind, &info.Ahead); err == nil {
+				info.Base = base
+			}
+		}
+	}
+	return info, nil
+}
+
+// parseGitLog parses `git log` output in the unit-separated format used by
+// readWorktree.
+func parseGitLog(out string) []gitCommit {
+	var commits []gitCommit
+	for _, line := range strings.Split(out, "\n") {
+		f := strings.Split(line, "\x1f")
+		if len(f) != 5 || f[0] == "" {
+			continue
+		}
+		c := gitCommit{Hash: f[0], Short: f[1], Author: f[2], Subject: f[4]}
+		if sec, err := strconv.ParseInt(f[3], 10, 64); err == nil {
+			c.Time = time.Unix(sec, 0)
+		}
+		commits = append(commits, c)
+	}
+	return commits
+}
+
+// metadata flattens the worktree into polecat node metadata.
+func (w worktreeInfo) metadata() map[string]string {
+	md := map[string]string{
+		"branch": w.Branch,
+		"dirty":  "clean",
+	}
+	if w.Dirty > 0 {
+		md["dirty"] = strconv.Itoa(w.Dirty) + " files"
+	}
+	if w.Base != "" {
+		md["ahead"] = strconv.Itoa(w.Ahead)
+		md["behind"] = strconv.Itoa(w.Behind)
+		md["base_branch"] = w.Base
+	}
+	var recent []string
+	for _, c := range w.Commits[:min(len(w.Commits), recentCommits)] {
+		recent = append(recent, c.Short+" "+c.Subject)
+	}
+	md["recent_commits"] = strings.Join(recent, "\n")
+	return md
+}
+
+// newCommits returns commit activities for the commits on a polecat's branch
+// since its HEAD was last seen, oldest first. The first look at a polecat
+// only records its HEAD. When the old HEAD is no longer in recent history,
+// as after a rebase or a burst of commits, only the new HEAD is reported.
+func (t *gitTracker) newCommits(pc state.Node, commits []gitCommit) []state.Activity {
+	if len(commits) == 0 {
+		return nil
+	}
+	prev, known := t.heads[pc.ID]
+	t.heads[pc.ID] = commits[0].Hash
+	if !known || prev == commits[0].Hash {
+		return nil
+	}
+
+	fresh := commits[:1]
+	for i, c := range commits {
+		if c.Hash == prev {
+			fresh = commits[:i]
+			break
+		}
+	}
+
+	acts := make([]state.Activity, 0, len(fresh))
+	for i := len(fresh) - 1; i >= 0; i-- {
+		c := fresh[i]
+		ts := c.Time
+		if ts.IsZero() {
+			ts = time.Now()
+		}
+		acts = append(acts, state.Activity{
+			Timestamp: ts,
+			Event:     "commit",
+			Agent:     pc.ID,
+			Detail:    c.Short + " " + c.Subject,
+		})
+	}
+	return acts
+}
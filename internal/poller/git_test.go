@@ -0,0 +1,64 @@
+package poller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gronitab/zeppelin/internal/state"
+)
+
+func TestCollectGit(t *testing.T) {
+	p := replayPoller("git")
+	toast := state.Node{ID: "gastown/polecats/toast", Type: "polecat", Label: "toast", Rig: "gastown", State: "working"}
+	p.latest["polecats"] = state.Fragment{Nodes: []state.Node{
+		toast,
+		{ID: "gastown/polecats/gone", Type: "polecat", Label: "gone", Rig: "gastown", State: "idle"},
+	}}
+
+	f, err := p.collectGit(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(f.Activities) != 0 {
+		t.Errorf("first look reported %d commits, want none", len(f.Activities))
+	}
+	if len(f.Annotations) != 1 {
+		t.Fatalf("annotations = %v, want only the polecat with a worktree", f.Annotations)
+	}
+	md := f.Annotations[toast.ID].Metadata
+	want := map[string]string{
+		"branch":         "polecat/toast",
+		"dirty":          "2 files",
+		"ahead":          "2",
+		"behind":         "1",
+		"base_branch":    "origin/main",
+		"recent_commits": "9fceb02 Add widget tests\ne83c516 Implement widget\n1b2c3d4 Initial commit",
+	}
+	for k, v := range want {
+		if md[k] != v {
+			t.Errorf("%s = %q, want %q", k, md[k], v)
+		}
+	}
+}
+
+func TestNewCommits(t *testing.T) {
+	tr := gitTracker{heads: make(map[string]string)}
+	pc := state.Node{ID: "gastown/polecats/toast"}
+	c := func(hash string) gitCommit { return gitCommit{Hash: hash, Short: hash, Subject: "msg " + hash} }
+
+	if acts := tr.newCommits(pc, []gitCommit{c("b"), c("a")}); len(acts) != 0 {
+		t.Errorf("first look: got %d activities, want 0", len(acts))
+	}
+	acts := tr.newCommits(pc, []gitCommit{c("d"), c("c"), c("b"), c("a")})
+	if len(acts) != 2 || acts[0].Detail != "c msg c" || acts[1].Detail != "d msg d" || acts[0].Event != "commit" {
+		t.Errorf("new commits = %+v, want c then d", acts)
+	}
+	if acts := tr.newCommits(pc, []gitCommit{c("d"), c("c")}); len(acts) != 0 {
+		t.Errorf("unchanged HEAD: got %d activities", len(acts))
+	}
+	// Rebased: the old HEAD is gone, so only the new HEAD is reported.
+	acts = tr.newCommits(pc, []gitCommit{c("f"), c("e"), c("a")})
+	if len(acts) != 1 || acts[0].Detail != "f msg f" {
+		t.Errorf("after rebase = %+v, want only f", acts)
+	}
+}
@@ -0,0 +1,146 @@
+package poller
+
+import (
+	"context"
+	"time"
+
+	"github.com/gronitab/zeppelin/internal/state"
+)
+
+// mailMessage represents a message from `gt mail inbox <address> --json`.
+type mailMessage struct {
+	ID        string    `json:"id"`
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	Subject   string    `json:"subject"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+func (mailMessage) ignoredFields() []string {
+	return []string{"body", "read", "priority", "type", "thread_id", "reply_to"}
+}
+
+// mailTracker remembers what the mail source has already seen, so only new
+// messages produce activity.
+type mailTracker struct {
+	counts map[string]string          // agent ID -> last unread_mail value
+	seen   map[string]map[string]bool // agent ID -> message IDs in the inbox
+	edges  []state.Edge               // mail edges that have not expired yet
+}
+
+// collectMail lists the inbox of every agent whose unread count changed since
+// the last poll, and reports each new message as a mail_sent activity plus a
+// short-lived mail edge from sender to recipient. It is the only producer of
+// mail activity; the events feed leaves mail to it. Agents gt reports no
+// unread count for are never listed.
+func (p *Poller) collectMail(ctx context.Context) (state.Fragment, error) {
+	t := &p.mail
+	if t.counts == nil {
+		t.counts = make(map[string]string)
+		t.seen = make(map[string]map[string]bool)
+	}
+
+	agents := p.latestNodes(func(n state.Node) bool {
+		switch n.Type {
+		case "mayor", "deacon", "witness", "refinery", "polecat", "crew":
+			return true
+		}
+		return false
+	})
+
+	// Decide which inboxes need listing, then list them concurrently.
+	var stale []state.Node
+	for _, a := range agents {
+		count, hasCount := a.Metadata["unread_mail"]
+		if !hasCount || count == t.counts[a.ID] {
+			continue
+		}
+		t.counts[a.ID] = count
+		if count == "0" && t.seen[a.ID] != nil {
+			continue
+		}
+		stale = append(stale, a)
+	}
+	inboxes := make([][]mailMessage, len(stale))
+	errs := make([]error, len(stale))
+	parallel(len(stale), func(i int) {
+		out, err := p.runCmd(ctx, "gt", "mail", "inbox", mailAddress(stale[i]), "--json")
+		if err == nil {
+			err = decodeJSON(ctx, "gt mail inbox", out, &inboxes[i])
+		}
+		errs[i] = err
+	})
+
+	var acts []state.Activity
+	now := time.Now()
+	for i, a := range stale {
+		if errs[i] != nil {
+			// Try again next poll.
+			delete(t.counts, a.ID)
+			continue
+		}
+		msgs := inboxes[i]
+
+		// The first listing of an inbox only establishes what was already there.
+		prev, known := t.seen[a.ID]
+		cur := make(map[string]bool, len(msgs))
+		for _, m := range msgs {
+			cur[m.ID] = true
+			if !known || prev[m.ID] {
+				continue
+			}
+			from := canonicalAgentID(m.From)
+			to := canonicalAgentID(orDefault(m.To, mailAddress(a)))
+			if to == "" {
+				to = a.ID
+			}
+			ts := m.Timestamp
+			if ts.IsZero() {
+				ts = now
+			}
+			acts = append(acts, state.Activity{
+				Timestamp: ts,
+				Event:     "mail_sent",
+				Agent:     orDefault(from, m.From),
+				Detail:    m.Subject,
+				From:      from,
+				To:        to,
+			})
+			if from != "" {
+				t.addEdge(state.Edge{
+					Source:    from,
+					Target:    to,
+					Type:      "mail",
+					Label:     m.Subject,
+					ExpiresAt: now.Add(p.opts.MailTTL),
+				})
+			}
+		}
+		t.seen[a.ID] = cur
+	}
+
+	return state.Fragment{Edges: t.live(now), Activities: acts}, allFailed(errs)
+}
+
+// addEdge records a mail edge, replacing an older one between the same agents.
+func (t *mailTracker) addEdge(e state.Edge) {
+	for i, old := range t.edges {
+		if old.Source == e.Source && old.Target == e.Target {
+			t.edges[i] = e
+			return
+		}
+	}
+	t.edges = append(t.edges, e)
+}
+
+// live drops expired edges and returns the rest.
+func (t *mailTracker) live(now time.Time) []state.Edge {
+	kept := t.edges[:0]
+	for _, e := range t.edges {
+		if e.ExpiresAt.After(now) {
+			kept = append(kept, e)
+		}
+	}
+	t.edges = kept
+	return append([]state.Edge(nil), kept...)
+}
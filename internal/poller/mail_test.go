@@ -0,0 +1,45 @@
+package poller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gronitab/zeppelin/internal/state"
+)
+
+func TestCollectMailListsOnCountChange(t *testing.T) {
+	inbox := `[]`
+	listed := map[string]int{}
+	runner := runnerFunc(func(ctx context.Context, c Command) ([]byte, []byte, error) {
+		listed[c.Args[2]]++
+		return []byte(inbox), nil, nil
+	})
+	p := New(state.NewStore(), t.TempDir(), Options{Runner: runner}, func(*state.Diff) {})
+	topology := func(unread string) {
+		p.latest["topology"] = state.Fragment{Nodes: []state.Node{
+			{ID: "mayor", Type: "mayor", Metadata: map[string]string{"unread_mail": unread}},
+			{ID: "deacon", Type: "deacon"},
+		}}
+	}
+	ctx := context.Background()
+
+	topology("0")
+	p.collectMail(ctx)
+	p.collectMail(ctx)
+	if listed["mayor/"] != 1 || listed["deacon/"] != 0 {
+		t.Fatalf("expected the mayor listed once and the deacon never, got %v", listed)
+	}
+
+	inbox = `[{"id":"m1","from":"gastown/witness","to":"mayor/","subject":"stuck"}]`
+	topology("1")
+	f, err := p.collectMail(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if listed["mayor/"] != 2 {
+		t.Errorf("expected the mayor listed again after the count changed, got %v", listed)
+	}
+	if len(f.Activities) != 1 || f.Activities[0].From != "gastown/witness" || f.Activities[0].To != "mayor" {
+		t.Errorf("expected one mail from the witness to the mayor, got %+v", f.Activities)
+	}
+}
@@ -0,0 +1,207 @@
+package poller
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gronitab/zeppelin/internal/state"
+)
+
+// mergeRequest represents a merge request from `gt mq list <rig> --json`.
+// gt builds differ in field names, so the common alternatives are accepted.
+type mergeRequest struct {
+	ID       string `json:"id"`
+	Branch   string `json:"branch"`
+	Worker   string `json:"worker"`
+	Polecat  string `json:"polecat"`
+	Issue    string `json:"issue"`
+	Bead     string `json:"source_issue"`
+	Status   string `json:"status"`
+	Position int    `json:"position"`
+	Error    string `json:"error"`
+}
+
+// polecatID returns the node ID of the polecat that submitted the request,
+// from its worker field or a "polecat/<name>/..." branch.
+func (mr mergeRequest) polecatID(rig string) string {
+	worker := orDefault(mr.Worker, mr.Polecat)
+	if worker == "" {
+		if rest, ok := strings.CutPrefix(mr.Branch, "polecat/"); ok {
+			worker, _, _ = strings.Cut(rest, "/")
+		}
+	}
+	if worker == "" {
+		return ""
+	}
+	if !strings.Contains(worker, "/") {
+		return rig + "/polecats/" + worker
+	}
+	return canonicalAgentID(worker)
+}
+
+func (mr mergeRequest) bead() string {
+	return orDefault(mr.Issue, mr.Bead)
+}
+
+// outcome maps the request's status to "queued", "merged", "failed" or
+// "closed", for requests that left the queue without a confirmed merge.
+func (mr mergeRequest) outcome() string {
+	switch strings.ToLower(mr.Status) {
+	case "merged", "done", "completed":
+		return "merged"
+	case "failed", "rejected", "conflict", "error":
+		return "failed"
+	case "closed", "cancelled", "canceled", "abandoned", "superseded":
+		return "closed"
+	default:
+		return "queued"
+	}
+}
+
+// mqTracker remembers the merge requests seen in each rig's queue, so merge
+// results produce activity once.
+type mqTracker struct {
+	queues map[string]map[string]queuedMerge // rig -> request ID -> request
+}
+
+type queuedMerge struct {
+	mr      mergeRequest
+	polecat string
+}
+
+// collectMergeQueue lists each rig's refinery queue. Queued requests mark
+// their bead in_refinery and draw a submitted edge from the polecat to the
+// refinery. Requests whose status turns merged or failed since the last poll
+// produce merge_complete or merge_failed activities, keyed by branch so the
+// events feed's report of the same merge is dropped. A request that leaves
+// the queue without such a status reports nothing, since it may have been
+// cancelled.
+func (p *Poller) collectMergeQueue(ctx context.Context) (state.Fragment, error) {
+	t := &p.mq
+	if t.queues == nil {
+		t.queues = make(map[string]map[string]queuedMerge)
+	}
+
+	refineries := p.latestNodes(func(n state.Node) bool { return n.Type == "refinery" })
+	polecats := make(map[string]bool)
+	for _, n := range p.latestNodes(func(n state.Node) bool { return n.Type == "polecat" }) {
+		polecats[n.ID] = true
+	}
+
+	queues := make([][]mergeRequest, len(refineries))
+	errs := make([]error, len(refineries))
+	parallel(len(refineries), func(i int) {
+		rig := refineries[i].Rig
+		out, err := p.runCmd(ctx, "gt", "mq", "list", rig, "--json")
+		if err == nil {
+			if err = decodeJSON(ctx, "gt mq list", out, &queues[i]); err != nil {
+				err = fmt.Errorf("gt mq list %s: %w", rig, err)
+			}
+		}
+		errs[i] = err
+	})
+
+	f := state.Fragment{Annotations: make(map[string]state.Annotation)}
+	now := time.Now()
+	for i, ref := range refineries {
+		if errs[i] != nil {
+			continue
+		}
+		rig := ref.Rig
+		prev, known := t.queues[rig]
+		cur := make(map[string]queuedMerge)
+		queued := 0
+
+		for _, mr := range queues[i] {
+			id := orDefault(mr.ID, mr.Branch)
+			qm := queuedMerge{mr: mr, polecat: mr.polecatID(rig)}
+			cur[id] = qm
+
+			switch mr.outcome() {
+			case "queued":
+			case "closed":
+				continue
+			default:
+				if old, ok := prev[id]; known && (!ok || old.mr.outcome() == "queued") {
+					f.Activities = append(f.Activities, mergeActivity(qm, rig, now))
+				}
+				continue
+			}
+
+			queued++
+			if b := mr.bead(); b != "" {
+				md := map[string]string{
+					"mq_status": orDefault(mr.Status, "queued"),
+					"mq_branch": mr.Branch,
+				}
+				if mr.Position > 0 {
+					md["mq_position"] = strconv.Itoa(mr.Position)
+				}
+				f.Annotations["bead:"+b] = state.Annotation{State: "in_refinery", Metadata: md}
+			}
+
+			// Polecats are often nuked right after submitting, so fall
+			// back to drawing the edge from the bead.
+			src := qm.polecat
+			if !polecats[src] {
+				src = ""
+				if b := mr.bead(); b != "" {
+					src = "bead:" + b
+				}
+			}
+			if src != "" {
+				f.Edges = append(f.Edges, state.Edge{
+					Source:   src,
+					Target:   ref.ID,
+					Type:     "merge_queue",
+					Label:    "submitted",
+					Metadata: map[string]string{"branch": mr.Branch},
+				})
+			}
+		}
+
+		t.queues[rig] = cur
+		f.Annotations[ref.ID] = state.Annotation{Metadata: map[string]string{
+			"queue": strconv.Itoa(queued),
+		}}
+	}
+
+	// Forget rigs whose refinery is gone.
+	for rig := range t.queues {
+		found := false
+		for _, ref := range refineries {
+			found = found || ref.Rig == rig
+		}
+		if !found {
+			delete(t.queues, rig)
+		}
+	}
+
+	return f, allFailed(errs)
+}
+
+// mergeActivity reports a merge result, attributed to the submitting polecat
+// so the frontend can find the rig's refinery.
+func mergeActivity(qm queuedMerge, rig string, now time.Time) state.Activity {
+	subject := orDefault(qm.mr.bead(), qm.mr.Branch)
+	a := state.Activity{
+		Timestamp: now,
+		Event:     "merge_complete",
+		Agent:     orDefault(qm.polecat, rig+"/refinery"),
+		Detail:    "Merged " + subject,
+	}
+	if qm.mr.outcome() == "failed" {
+		a.Event = "merge_failed"
+		a.Detail = "Merge failed: " + subject
+		if qm.mr.Error != "" {
+			a.Detail += ": " + qm.mr.Error
+		}
+	}
+	if qm.mr.Branch != "" {
+		a.Key = state.MergeKey(a.Event, qm.mr.Branch)
+	}
+	return a
+}
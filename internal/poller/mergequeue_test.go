@@ -0,0 +1,91 @@
+package poller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gronitab/zeppelin/internal/state"
+)
+
+func TestCollectMergeQueue(t *testing.T) {
+	queue := `[
+		{"id":"mr-1","branch":"polecat/toast/gt-12","issue":"gt-12","status":"pending","position":1},
+		{"id":"mr-2","branch":"polecat/nux/gt-13","worker":"gastown/nux","issue":"gt-13","status":"processing","position":2},
+		{"id":"mr-3","branch":"polecat/slit/gt-14","issue":"gt-14","status":"pending","position":3},
+		{"id":"mr-4","branch":"polecat/slit/gt-15","issue":"gt-15","status":"pending","position":4}
+	]`
+	runner := runnerFunc(func(ctx context.Context, c Command) ([]byte, []byte, error) {
+		return []byte(queue), nil, nil
+	})
+	p := New(state.NewStore(), t.TempDir(), Options{Runner: runner}, func(*state.Diff) {})
+	p.latest["topology"] = state.Fragment{Nodes: []state.Node{
+		{ID: "gastown/refinery", Type: "refinery", Rig: "gastown"},
+		{ID: "gastown/polecats/toast", Type: "polecat", Label: "toast", Rig: "gastown"},
+	}}
+	ctx := context.Background()
+
+	f, err := p.collectMergeQueue(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(f.Activities) != 0 {
+		t.Errorf("first listing reported %d activities, want none", len(f.Activities))
+	}
+	if a := f.Annotations["bead:gt-12"]; a.State != "in_refinery" || a.Metadata["mq_position"] != "1" {
+		t.Errorf("bead:gt-12 annotation = %+v", a)
+	}
+	if q := f.Annotations["gastown/refinery"].Metadata["queue"]; q != "4" {
+		t.Errorf("refinery queue = %q, want 4", q)
+	}
+	// toast is on the graph; nux and slit were already nuked, so their beads
+	// submit.
+	want := map[string]bool{"gastown/polecats/toast": true, "bead:gt-13": true, "bead:gt-14": true, "bead:gt-15": true}
+	for _, e := range f.Edges {
+		if e.Target != "gastown/refinery" || e.Type != "merge_queue" || e.Label != "submitted" || !want[e.Source] {
+			t.Errorf("unexpected edge %+v", e)
+		}
+		delete(want, e.Source)
+	}
+	if len(want) != 0 {
+		t.Errorf("missing submitted edges from %v", want)
+	}
+
+	// mr-1 merges; mr-2 fails; mr-3 is cancelled and mr-4 leaves the queue
+	// without a status, neither of which confirms a merge.
+	queue = `[
+		{"id":"mr-1","branch":"polecat/toast/gt-12","issue":"gt-12","status":"merged"},
+		{"id":"mr-2","branch":"polecat/nux/gt-13","worker":"gastown/nux","issue":"gt-13","status":"failed","error":"conflict in widget.go"},
+		{"id":"mr-3","branch":"polecat/slit/gt-14","issue":"gt-14","status":"cancelled"}
+	]`
+	f, err = p.collectMergeQueue(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(f.Activities) != 2 {
+		t.Fatalf("activities = %+v, want a merge and a failure", f.Activities)
+	}
+	for _, a := range f.Activities {
+		switch a.Event {
+		case "merge_failed":
+			if a.Agent != "gastown/polecats/nux" || a.Detail != "Merge failed: gt-13: conflict in widget.go" {
+				t.Errorf("unexpected failure %+v", a)
+			}
+		case "merge_complete":
+			if a.Agent != "gastown/polecats/toast" || a.Detail != "Merged gt-12" || a.Key != state.MergeKey("merge_complete", "polecat/toast/gt-12") {
+				t.Errorf("unexpected merge %+v", a)
+			}
+		default:
+			t.Errorf("unexpected activity %+v", a)
+		}
+	}
+	for _, b := range []string{"bead:gt-12", "bead:gt-13", "bead:gt-14"} {
+		if _, ok := f.Annotations[b]; ok {
+			t.Errorf("request for %s left the queue but keeps its bead in_refinery", b)
+		}
+	}
+
+	// Results are reported once.
+	if f, _ = p.collectMergeQueue(ctx); len(f.Activities) != 0 {
+		t.Errorf("repeat poll reported %+v", f.Activities)
+	}
+}
@@ -0,0 +1,143 @@
+package poller
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gronitab/zeppelin/internal/state"
+)
+
+// moleculeInfo represents the output of `bd mol current --json`.
+type moleculeInfo struct {
+	ID    string         `json:"id"`
+	Title string         `json:"title"`
+	Steps []moleculeStep `json:"steps"`
+}
+
+type moleculeStep struct {
+	ID     string `json:"id"`
+	Title  string `json:"title"`
+	Status string `json:"status"`
+}
+
+// collectMolecules annotates every working polecat with the steps of the
+// molecule it is currently executing.
+func (p *Poller) collectMolecules(ctx context.Context) (state.Fragment, error) {
+	working := p.latestNodes(func(n state.Node) bool {
+		return n.Type == "polecat" && n.State == "working"
+	})
+
+	mols := make([]moleculeInfo, len(working))
+	errs := make([]error, len(working))
+	parallel(len(working), func(i int) {
+		pc := working[i]
+		dir := filepath.Join(p.root, pc.Rig, "polecats", pc.Label)
+		if _, err := os.Stat(dir); err != nil {
+			return
+		}
+		mols[i], errs[i] = p.currentMolecule(ctx, dir)
+	})
+
+	annotations := make(map[string]state.Annotation)
+	for i, pc := range working {
+		if mols[i].ID != "" {
+			annotations[pc.ID] = state.Annotation{Metadata: mols[i].metadata()}
+		}
+	}
+
+	return state.Fragment{Annotations: annotations}, allFailed(errs)
+}
+
+// currentMolecule runs `bd mol current` in a polecat's worktree, preferring
+// JSON and falling back to parsing the text output. A polecat without a
+// molecule yields an empty moleculeInfo.
+func (p *Poller) currentMolecule(ctx context.Context, dir string) (moleculeInfo, error) {
+	var mol moleculeInfo
+	out, err := p.runCmdIn(ctx, dir, "bd", "mol", "current", "--json")
+	if err == nil && decodeJSON(ctx, "bd mol current", out, &mol) == nil && mol.ID != "" {
+		return mol, nil
+	}
+
+	text, err := p.runCmdIn(ctx, dir, "bd", "mol", "current")
+	if err != nil {
+		return moleculeInfo{}, err
+	}
+	return parseMoleculeText(text), nil
+}
+
+// metadata flattens the molecule into polecat node metadata.
+func (m moleculeInfo) metadata() map[string]string {
+	done := 0
+	current := ""
+	steps := make([]string, 0, len(m.Steps))
+	for _, st := range m.Steps {
+		status := mapBeadStatus(st.Status)
+		marker := "○"
+		switch status {
+		case "closed":
+			marker = "✓"
+			done++
+		case "in_progress", "hooked":
+			marker = "→"
+		}
+		if current == "" && status != "closed" {
+			current = st.Title
+		}
+		steps = append(steps, marker+" "+st.Title)
+	}
+
+	return map[string]string{
+		"molecule":          m.ID,
+		"molecule_title":    m.Title,
+		"molecule_progress": strconv.Itoa(done) + "/" + strconv.Itoa(len(m.Steps)),
+		"molecule_step":     current,
+		"molecule_steps":    strings.Join(steps, " › "),
+	}
+}
+
+var (
+	// "Molecule: zep-mol-abc (mol-polecat-work)"
+	moleculeHeaderRe = regexp.MustCompile(`^(?i:(?:current\s+)?molecule):?\s+(\S+)\s*(?:[(\-–—:]\s*(.*?)\)?)?$`)
+	// "  ✓ zep-s1  Load context", "[x] Load context", "2. → Implement"
+	moleculeStepRe = regexp.MustCompile(`^(?:\d+[.)]\s*)?(\[[ xX~>]\]|[✓✔●→▶○◯•·])\s+(?:([a-z0-9]+-[a-z0-9.\-]+)\s+)?(.+)$`)
+)
+
+// parseMoleculeText parses the human-readable output of `bd mol current`.
+func parseMoleculeText(text string) moleculeInfo {
+	var mol moleculeInfo
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if mol.ID == "" {
+			if m := moleculeHeaderRe.FindStringSubmatch(line); m != nil {
+				mol.ID, mol.Title = m[1], strings.TrimSpace(m[2])
+			}
+			continue
+		}
+		if m := moleculeStepRe.FindStringSubmatch(line); m != nil {
+			mol.Steps = append(mol.Steps, moleculeStep{
+				ID:     m[2],
+				Title:  strings.TrimSpace(m[3]),
+				Status: stepMarkerStatus(m[1]),
+			})
+		}
+	}
+	return mol
+}
+
+func stepMarkerStatus(marker string) string {
+	switch marker {
+	case "[x]", "[X]", "✓", "✔", "●":
+		return "closed"
+	case "[~]", "[>]", "→", "▶":
+		return "in_progress"
+	default:
+		return "open"
+	}
+}
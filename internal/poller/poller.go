@@ -2,133 +2,727 @@ package poller
 
 import (
 	"context"
-	"encoding/json"
+	"fmt"
 	"log"
-	"os/exec"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gronitab/zeppelin/internal/state"
 )
 
-// Poller periodically runs gt/bd CLI commands and updates the state store.
+// Options tunes the poller. Zero fields select their defaults.
+type Options struct {
+	// MailTTL is how long a mail edge stays on the graph after the message
+	// is seen. Defaults to 10s.
+	MailTTL time.Duration
+	// Intervals overrides the polling interval of the named sources.
+	Intervals map[string]time.Duration
+
+	// MaxConcurrent caps how many commands run at once across all sources.
+	// Defaults to 4.
+	MaxConcurrent int
+	// CommandTimeout bounds each command. Defaults to 10s.
+	CommandTimeout time.Duration
+	// Timeouts overrides CommandTimeout for commands whose name and leading
+	// arguments match a key, such as "bd" or "bd list". The longest match wins.
+	Timeouts map[string]time.Duration
+	// Runner runs the commands. Defaults to ExecRunner.
+	Runner CommandRunner
+
+	// NoWatch disables watching the root for changes, leaving every source
+	// on its fixed interval.
+	NoWatch bool
+	// SafetyInterval is how often file-backed sources poll while the root
+	// is watched, in case a change goes unnoticed. Defaults to 1m.
+	SafetyInterval time.Duration
+
+	// Viewers reports how many clients are watching. While it reports
+	// none, sources poll IdleBackoff times less often. Nil means the town
+	// is always watched.
+	Viewers func() int
+	// IdleBackoff multiplies intervals while nobody is watching.
+	// Defaults to 6.
+	IdleBackoff int
+	// QuietAfter is how long the town must go unchanged before intervals
+	// are stretched by quietBackoff. Defaults to 10m.
+	QuietAfter time.Duration
+	// MaxInterval caps how far backoff stretches an interval. It never
+	// shortens a source's own interval. Defaults to 5m.
+	MaxInterval time.Duration
+}
+
+const (
+	// quietBackoff multiplies intervals once the town has been quiet for
+	// QuietAfter.
+	quietBackoff = 4
+	// burstWindow is how long after a change sources poll at twice their
+	// usual rate, to follow the activity closely.
+	burstWindow = 30 * time.Second
+	// minInterval is the shortest interval the burst rate goes down to.
+	minInterval = time.Second
+)
+
+func (o Options) withDefaults() Options {
+	if o.MailTTL <= 0 {
+		o.MailTTL = 10 * time.Second
+	}
+	if o.MaxConcurrent <= 0 {
+		o.MaxConcurrent = 4
+	}
+	if o.CommandTimeout <= 0 {
+		o.CommandTimeout = 10 * time.Second
+	}
+	if o.Runner == nil {
+		o.Runner = ExecRunner{}
+	}
+	if o.SafetyInterval <= 0 {
+		o.SafetyInterval = time.Minute
+	}
+	if o.IdleBackoff <= 0 {
+		o.IdleBackoff = 6
+	}
+	if o.QuietAfter <= 0 {
+		o.QuietAfter = 10 * time.Minute
+	}
+	if o.MaxInterval <= 0 {
+		o.MaxInterval = 5 * time.Minute
+	}
+	return o
+}
+
+// Poller runs gt/bd CLI commands on per-source schedules and updates the
+// state store.
 type Poller struct {
 	store    *state.Store
 	root     string
-	onChange func() // called when state changes
+	opts     Options
+	onChange func(*state.Diff) // called with each non-empty diff
+	sources  []Source
+	wake     map[string]chan struct{} // per source; see Trigger
+
+	sem chan struct{} // limits concurrent commands
+
+	// publish orders store updates with their onChange calls, so clients
+	// receive diffs in version order.
+	publish sync.Mutex
+
+	mu      sync.Mutex
+	latest  map[string]state.Fragment // last fragment collected per source
+	changed time.Time                 // when a poll last changed the store
+
+	beads beadTracker // owned by the beads source's goroutine
+	mail  mailTracker // owned by the mail source's goroutine
+	git   gitTracker  // owned by the git source's goroutine
+	mq    mqTracker   // owned by the mergequeue source's goroutine
+
+	health   map[string]state.SourceHealth // guarded by mu
+	dangling map[string]bool               // dangling edges already logged; guarded by mu
+
+	// OnHealth, if set before Run, is called when a source fails, recovers
+	// or reports a different error.
+	OnHealth func(state.HealthEvent)
+}
+
+// New creates a poller that updates the given store with the default sources.
+func New(store *state.Store, root string, opts Options, onChange func(*state.Diff)) *Poller {
+	opts = opts.withDefaults()
+	p := &Poller{
+		store:    store,
+		root:     root,
+		opts:     opts,
+		onChange: onChange,
+		sem:      make(chan struct{}, opts.MaxConcurrent),
+		wake:     make(map[string]chan struct{}),
+		latest:   make(map[string]state.Fragment),
+		health:   make(map[string]state.SourceHealth),
+		dangling: make(map[string]bool),
+	}
+	collectors := map[string]func(context.Context) (state.Fragment, error){
+		"topology":   p.collectTopology,
+		"polecats":   p.collectPolecats,
+		"rigs":       p.collectRigs,
+		"beads":      p.collectBeads,
+		"convoys":    p.collectConvoys,
+		"molecules":  p.collectMolecules,
+		"mail":       p.collectMail,
+		"git":        p.collectGit,
+		"mergequeue": p.collectMergeQueue,
+	}
+	for _, s := range builtinSources {
+		interval := s.interval
+		if d, ok := opts.Intervals[s.name]; ok && d > 0 {
+			interval = d
+		}
+		p.AddSource(NewSource(s.name, interval, collectors[s.name]))
+	}
+	return p
+}
+
+// builtinSources are the default sources in precedence order, with their
+// default intervals.
+var builtinSources = []struct {
+	name     string
+	interval time.Duration
+}{
+	{"topology", 5 * time.Second},
+	{"polecats", 5 * time.Second},
+	{"rigs", 10 * time.Second},
+	{"beads", 10 * time.Second},
+	{"convoys", 15 * time.Second},
+	{"molecules", 15 * time.Second},
+	{"mail", 5 * time.Second},
+	{"git", 15 * time.Second},
+	{"mergequeue", 10 * time.Second},
+}
+
+// SourceNames returns the names of the default sources.
+func SourceNames() []string {
+	names := make([]string, len(builtinSources))
+	for i, s := range builtinSources {
+		names[i] = s.name
+	}
+	return names
+}
+
+// IsSource reports whether name is one of the default sources.
+func IsSource(name string) bool {
+	return slices.Contains(SourceNames(), name)
+}
+
+// AddSource registers an additional source. It must be called before Run.
+// Sources added earlier take precedence when fragments report the same node.
+func (p *Poller) AddSource(src Source) {
+	p.sources = append(p.sources, src)
+	p.wake[src.Name()] = make(chan struct{}, 1)
+}
+
+// Trigger asks the named source to poll now rather than at its next tick.
+// Triggers arriving while the source is polling are coalesced into one
+// more poll.
+func (p *Poller) Trigger(name string) {
+	select {
+	case p.wake[name] <- struct{}{}:
+	default:
+	}
+}
+
+// AppendActivity records activity observed outside the poller, such as by
+// the events feed, and passes the diff to onChange in order with the
+// poller's own.
+func (p *Poller) AppendActivity(acts ...state.Activity) {
+	p.publish.Lock()
+	defer p.publish.Unlock()
+	if diff := p.store.AppendActivity(acts...); diff != nil {
+		p.onChange(diff)
+	}
 }
 
-// New creates a poller that updates the given store.
-func New(store *state.Store, root string, onChange func()) *Poller {
-	return &Poller{store: store, root: root, onChange: onChange}
+// Refresh asks every source to poll now, such as when the first client
+// connects after the poller has backed off.
+func (p *Poller) Refresh() {
+	for _, src := range p.sources {
+		p.Trigger(src.Name())
+	}
 }
 
-// Run starts polling loops. It blocks until the context is cancelled.
+// Run starts one polling loop per source, and watches the root to trigger
+// sources as soon as their files change. It blocks until the context is
+// cancelled and all loops have returned.
 func (p *Poller) Run(ctx context.Context) {
-	// Run an initial poll immediately.
-	p.poll()
+	// Startup counts as a change, so the first polls follow each other
+	// closely rather than starting out backed off.
+	p.mu.Lock()
+	p.changed = time.Now()
+	p.mu.Unlock()
 
-	ticker := time.NewTicker(5 * time.Second)
+	w := p.startWatcher()
+
+	var wg sync.WaitGroup
+	for _, src := range p.sources {
+		interval := src.Interval()
+		if w != nil && fileBacked[src.Name()] {
+			// Changes trigger a poll; the ticker is only a safety net.
+			interval = max(interval, p.opts.SafetyInterval)
+		}
+		wg.Go(func() { p.runSource(ctx, src, interval) })
+	}
+	if w != nil {
+		wg.Go(func() { p.runWatcher(ctx, w) })
+	}
+	wg.Go(func() { p.runExpiry(ctx) })
+	wg.Wait()
+}
+
+// runExpiry removes transient edges from the store as they expire.
+func (p *Poller) runExpiry(ctx context.Context) {
+	ticker := time.NewTicker(time.Second)
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-ctx.Done():
 			return
-		case <-ticker.C:
-			p.poll()
+		case now := <-ticker.C:
+			p.publish.Lock()
+			if diff := p.store.Expire(now); diff != nil {
+				p.onChange(diff)
+			}
+			p.publish.Unlock()
 		}
 	}
 }
 
-func (p *Poller) poll() {
-	nodes, edges, summary := p.collectState()
-	diff := p.store.Update(nodes, edges, summary)
-	if diff != nil {
-		p.onChange()
+func (p *Poller) runSource(ctx context.Context, src Source, interval time.Duration) {
+	// Run an initial poll immediately.
+	p.poll(ctx, src)
+
+	timer := time.NewTimer(p.nextInterval(interval, time.Now()))
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		case <-p.wake[src.Name()]:
+		}
+		p.poll(ctx, src)
+		timer.Reset(p.nextInterval(interval, time.Now()))
 	}
 }
 
+// nextInterval adapts a source's interval to the town: twice as fast just
+// after a change, slower once the town has been quiet for a while, and
+// slower still while nobody is watching.
+func (p *Poller) nextInterval(base time.Duration, now time.Time) time.Duration {
+	p.mu.Lock()
+	sinceChange := now.Sub(p.changed)
+	p.mu.Unlock()
+
+	d := base
+	switch {
+	case sinceChange < burstWindow:
+		d = max(base/2, minInterval)
+	case sinceChange > p.opts.QuietAfter:
+		d = base * quietBackoff
+	}
+	if p.opts.Viewers != nil && p.opts.Viewers() == 0 {
+		d *= time.Duration(p.opts.IdleBackoff)
+	}
+	return min(d, max(base, p.opts.MaxInterval))
+}
+
+func (p *Poller) poll(ctx context.Context, src Source) {
+	start := time.Now()
+	rec := &cmdRecorder{}
+	f, err := src.Collect(withRecorder(ctx, rec))
+	if ctx.Err() != nil {
+		// Shutting down: commands were killed, so the fragment is incomplete.
+		return
+	}
+	p.recordHealth(src.Name(), start, rec, err)
+
+	p.publish.Lock()
+	defer p.publish.Unlock()
+	var diffs []*state.Diff
+	if err != nil {
+		// Keep the last good fragment, flagged stale, rather than rendering
+		// a failing source as an empty town.
+		log.Printf("poller: %s: %v", src.Name(), err)
+		diffs = append(diffs, p.store.MarkStale(src.Name()))
+	} else {
+		p.mu.Lock()
+		p.latest[src.Name()] = f
+		p.mu.Unlock()
+		diffs = append(diffs, p.store.UpdateSource(src.Name(), f))
+	}
+	// A state restored from disk stands in until every source has polled
+	// once; after that, what failing sources reported last run is gone.
+	p.mu.Lock()
+	polled := len(p.health) == len(p.sources)
+	p.mu.Unlock()
+	if polled {
+		diffs = append(diffs, p.store.DropRestored())
+	}
+	p.reportDangling()
+	for _, diff := range diffs {
+		if diff == nil {
+			continue
+		}
+		p.mu.Lock()
+		p.changed = time.Now()
+		p.mu.Unlock()
+		p.onChange(diff)
+	}
+}
+
+// recordHealth updates the source's health report after a collection and
+// notifies OnHealth when it changed meaningfully.
+func (p *Poller) recordHealth(name string, start time.Time, rec *cmdRecorder, err error) {
+	p.mu.Lock()
+	prev := p.health[name]
+
+	h := state.SourceHealth{
+		Name:        name,
+		LastRun:     start,
+		LastSuccess: prev.LastSuccess,
+		DurationMS:  time.Since(start).Milliseconds(),
+		Stale:       err != nil,
+	}
+	if err != nil {
+		h.LastError = err.Error()
+	} else {
+		h.LastSuccess = start
+	}
+
+	// Carry each command's last success over from earlier runs.
+	lastOK := make(map[string]time.Time, len(prev.Commands))
+	for _, c := range prev.Commands {
+		lastOK[c.Command] = c.LastSuccess
+	}
+	for _, c := range rec.cmds {
+		if c.LastSuccess.IsZero() {
+			c.LastSuccess = lastOK[c.Command]
+		}
+		h.Commands = append(h.Commands, c)
+	}
+	h.Warnings = rec.warnings
+	p.health[name] = h
+	p.mu.Unlock()
+
+	if p.store.SetSourceHealth(h) && p.OnHealth != nil {
+		p.OnHealth(p.store.HealthEvent())
+	}
+}
+
+// reportDangling logs edges the store newly withheld for pointing at missing
+// nodes, and notifies OnHealth when the set of such edges changed.
+func (p *Poller) reportDangling() {
+	dangling := p.store.DanglingEdges()
+	current := make(map[string]bool, len(dangling))
+
+	p.mu.Lock()
+	changed := false
+	for _, d := range dangling {
+		k := d.Source + "|" + d.Edge.Source + "|" + d.Edge.Target + "|" + d.Edge.Type
+		current[k] = true
+		if !p.dangling[k] {
+			changed = true
+			log.Printf("poller: %s: dropping %s edge %s -> %s: no node %s",
+				d.Source, d.Edge.Type, d.Edge.Source, d.Edge.Target, d.Missing)
+		}
+	}
+	changed = changed || len(current) != len(p.dangling)
+	p.dangling = current
+	p.mu.Unlock()
+
+	if changed && p.OnHealth != nil {
+		p.OnHealth(p.store.HealthEvent())
+	}
+}
+
+// latestNodes returns the nodes most recently collected by any source that
+// match the filter, de-duplicated by ID. Sources use it to find the agents
+// they need to inspect without reading back from the store.
+func (p *Poller) latestNodes(match func(state.Node) bool) []state.Node {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var nodes []state.Node
+	seen := make(map[string]bool)
+	for _, src := range p.sources {
+		for _, n := range p.latest[src.Name()].Nodes {
+			if !seen[n.ID] && match(n) {
+				seen[n.ID] = true
+				nodes = append(nodes, n)
+			}
+		}
+	}
+	return nodes
+}
+
 // gtStatusOutput represents the JSON from `gt status --json`.
 type gtStatusOutput struct {
-	Rigs []rigInfo `json:"rigs"`
+	Mayor    *agentInfo    `json:"mayor"`
+	Deacon   *agentInfo    `json:"deacon"`
+	Overseer *overseerInfo `json:"overseer"`
+	Agents   []agentInfo   `json:"agents"` // town-level agents, in newer gt builds
+	Rigs     []rigInfo     `json:"rigs" schema:"required"`
+}
+
+// overseerInfo describes the human operator of the town.
+type overseerInfo struct {
+	Name       string `json:"name"`
+	Email      string `json:"email"`
+	UnreadMail int    `json:"unread_mail"`
+}
+
+// townAgent returns the town-level agent with the given name, whether gt
+// reports it as a top-level object or in the agents list.
+func (s gtStatusOutput) townAgent(name string, direct *agentInfo) (agentInfo, bool) {
+	if direct != nil {
+		return *direct, true
+	}
+	for _, a := range s.Agents {
+		if strings.TrimSuffix(a.Name, "/") == name {
+			return a, true
+		}
+	}
+	return agentInfo{}, false
 }
 
 type rigInfo struct {
-	Name     string      `json:"name"`
+	Name     string      `json:"name" schema:"required"`
 	Witness  agentInfo   `json:"witness"`
 	Refinery agentInfo   `json:"refinery"`
 	Polecats []agentInfo `json:"polecats"`
 	Crew     []agentInfo `json:"crew"`
 }
 
+// agentInfo is an agent as gt status reports it. Status is an older name
+// for State. Details are passed through to the node's metadata whatever the
+// agent's type.
 type agentInfo struct {
-	Name    string            `json:"name"`
-	State   string            `json:"state"`
-	Details map[string]string `json:"details"`
+	Name       string       `json:"name" schema:"required"`
+	State      string       `json:"state"`
+	Status     string       `json:"status"`
+	Running    *bool        `json:"running"`
+	UnreadMail *int         `json:"unread_mail"`
+	Details    agentDetails `json:"details"`
+}
+
+// metadata returns the agent's details plus its unread mail count, if known.
+func (a agentInfo) metadata() map[string]string {
+	if a.UnreadMail == nil {
+		return a.Details
+	}
+	md := make(map[string]string, len(a.Details)+1)
+	for k, v := range a.Details {
+		md[k] = v
+	}
+	md["unread_mail"] = strconv.Itoa(*a.UnreadMail)
+	return md
+}
+
+// mergeDetails adds an agent's details to its metadata, without overriding
+// the keys md already has.
+func mergeDetails(md map[string]string, details agentDetails) map[string]string {
+	for k, v := range details {
+		if _, ok := md[k]; !ok {
+			md[k] = v
+		}
+	}
+	return md
+}
+
+// stateOr returns the state gt reported for the agent, or def.
+func (a agentInfo) stateOr(def string) string {
+	return orDefault(orDefault(a.State, a.Status), def)
+}
+
+// agentState derives an agent's state from what gt reported: an explicit
+// state, else the running flag, else "unknown".
+func agentState(a agentInfo) string {
+	switch {
+	case a.State != "":
+		return a.State
+	case a.Status != "":
+		return a.Status
+	case a.Running == nil:
+		return "unknown"
+	case *a.Running:
+		return "running"
+	default:
+		return "stopped"
+	}
 }
 
 // beadInfo represents a single bead from `bd list --json`.
 type beadInfo struct {
-	ID       string `json:"id"`
+	ID       string `json:"id" schema:"required"`
 	Title    string `json:"title"`
-	Status   string `json:"status"`
+	Status   string `json:"status" schema:"required"`
 	Assignee string `json:"assignee"`
 	Priority int    `json:"priority"`
 	Type     string `json:"type"`
+
+	Dependencies []beadDep `json:"dependencies"`
+	Dependents   []beadDep `json:"dependents"`
 }
 
-func (p *Poller) collectState() ([]state.Node, []state.Edge, state.Summary) {
-	var nodes []state.Node
-	var edges []state.Edge
+// ignoredFields lists the fields bd prints that Zeppelin does not use, so
+// they are not reported as unknown.
+func (beadInfo) ignoredFields() []string {
+	return []string{
+		"description", "design", "acceptance_criteria", "notes", "issue_type",
+		"estimated_minutes", "created_at", "updated_at", "closed_at",
+		"external_ref", "labels", "comments", "dependency_count",
+		"dependent_count", "created_by", "owner", "source_repo",
+	}
+}
+
+// beadDep is a dependency record. bd reports either link records
+// (issue_id/depends_on_id/type) or embedded issues (id/dependency_type),
+// depending on the version.
+type beadDep struct {
+	IssueID        string `json:"issue_id"`
+	DependsOnID    string `json:"depends_on_id"`
+	ID             string `json:"id"`
+	Type           string `json:"type"`
+	DependencyType string `json:"dependency_type"`
+}
+
+// other returns the ID of the bead on the far side of the link from self.
+func (d beadDep) other(self string) string {
+	switch {
+	case d.DependsOnID != "" && d.DependsOnID != self:
+		return d.DependsOnID
+	case d.IssueID != "" && d.IssueID != self:
+		return d.IssueID
+	default:
+		return d.ID
+	}
+}
+
+// blocking reports whether the link gates work, as opposed to informational
+// links such as related or discovered-from.
+func (d beadDep) blocking() bool {
+	typ := d.DependencyType
+	if typ == "" {
+		typ = d.Type
+	}
+	return typ == "" || typ == "blocks"
+}
+
+// convoyInfo represents a single convoy from `gt convoy list --json`.
+type convoyInfo struct {
+	ID        string          `json:"id"`
+	Title     string          `json:"title"`
+	Status    string          `json:"status"`
+	Tracked   []convoyTracked `json:"tracked"`
+	Completed int             `json:"completed"`
+	Total     int             `json:"total"`
+}
+
+type convoyTracked struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+}
 
-	// Try gt status --json first.
-	statusOut := p.runCmd("gt", "status", "--json")
+// collectTopology builds the town and rig agents from `gt status --json`,
+// falling back to parsing `gt status` text and then to individual commands
+// when it is unavailable.
+func (p *Poller) collectTopology(ctx context.Context) (state.Fragment, error) {
 	var status gtStatusOutput
+	statusOut, err := p.runCmd(ctx, "gt", "status", "--json")
+	if err == nil {
+		if err = decodeJSON(ctx, "gt status", statusOut, &status); err != nil {
+			recorderFrom(ctx).warn("gt status: unreadable JSON, falling back to text: " + err.Error())
+		}
+	}
 
-	if err := json.Unmarshal([]byte(statusOut), &status); err == nil && len(status.Rigs) > 0 {
-		nodes, edges = p.buildFromStatus(status)
-	} else {
-		// Fallback: build state from individual commands.
-		nodes, edges = p.buildFromCommands()
+	var f state.Fragment
+	if err == nil && len(status.Rigs) > 0 {
+		f.Nodes, f.Edges = p.buildFromStatus(status)
+		return f, nil
 	}
 
-	// Collect beads.
-	beadNodes, beadEdges := p.collectBeads()
-	nodes = append(nodes, beadNodes...)
-	edges = append(edges, beadEdges...)
+	// Older gt builds without --json still print the whole tree as text.
+	if err != nil {
+		if text, textErr := p.runCmd(ctx, "gt", "status"); textErr == nil {
+			if parsed := parseStatusText(text); len(parsed.Rigs) > 0 {
+				f.Nodes, f.Edges = p.buildFromStatus(parsed)
+				return f, nil
+			}
+		}
+	}
 
-	summary := state.Summary{
-		RigCount:       countByType(nodes, "witness"), // one witness per rig
-		ActivePolecats: countByTypeAndState(nodes, "polecat", "working"),
-		OpenBeads:      countByType(nodes, "bead"),
+	// Fallback: build state from individual commands.
+	var fallbackErr error
+	f.Nodes, f.Edges, fallbackErr = p.buildFromCommands(ctx)
+	if fallbackErr != nil {
+		return f, fmt.Errorf("gt status: %v; fallback: %w", err, fallbackErr)
 	}
+	return f, nil
+}
 
-	return nodes, edges, summary
+// collectPolecats reports every polecat from `gt polecat list --all`, so
+// polecats show up even when `gt status` omits or fails to report them.
+func (p *Poller) collectPolecats(ctx context.Context) (state.Fragment, error) {
+	nodes, edges, _, err := p.listPolecats(ctx)
+	if err != nil {
+		return state.Fragment{}, err
+	}
+	for _, n := range nodes {
+		edges = append(edges, state.Edge{
+			Source: n.Rig + "/witness",
+			Target: n.ID,
+			Type:   "monitoring",
+		})
+	}
+	return state.Fragment{Nodes: nodes, Edges: edges}, nil
 }
 
 func (p *Poller) buildFromStatus(status gtStatusOutput) ([]state.Node, []state.Edge) {
 	var nodes []state.Node
 	var edges []state.Edge
 
-	// Add mayor node.
+	// Town-level agents.
+	mayorState, mayorMeta := "unknown", map[string]string(nil)
+	if mayor, ok := status.townAgent("mayor", status.Mayor); ok {
+		mayorState, mayorMeta = agentState(mayor), mayor.metadata()
+	}
 	nodes = append(nodes, state.Node{
-		ID:    "mayor",
-		Type:  "mayor",
-		Label: "Mayor",
-		State: "running",
+		ID:       "mayor",
+		Type:     "mayor",
+		Label:    "Mayor",
+		State:    mayorState,
+		Metadata: mayorMeta,
 	})
 
+	deacon, hasDeacon := status.townAgent("deacon", status.Deacon)
+	if hasDeacon {
+		nodes = append(nodes, state.Node{
+			ID:       "deacon",
+			Type:     "deacon",
+			Label:    "Deacon",
+			State:    agentState(deacon),
+			Metadata: deacon.metadata(),
+		})
+	}
+
+	if o := status.Overseer; o != nil {
+		nodes = append(nodes, state.Node{
+			ID:    "overseer",
+			Type:  "overseer",
+			Label: orDefault(o.Name, "Overseer"),
+			State: "active",
+			Metadata: map[string]string{
+				"email":       o.Email,
+				"unread_mail": strconv.Itoa(o.UnreadMail),
+			},
+		})
+		edges = append(edges, state.Edge{
+			Source: "overseer",
+			Target: "mayor",
+			Type:   "town",
+		})
+	}
+
 	for _, rig := range status.Rigs {
 		// Witness.
 		if rig.Witness.Name != "" {
 			wID := rig.Name + "/witness"
 			nodes = append(nodes, state.Node{
-				ID:    wID,
-				Type:  "witness",
-				Label: "Witness",
-				Rig:   rig.Name,
-				State: orDefault(rig.Witness.State, "running"),
+				ID:       wID,
+				Type:     "witness",
+				Label:    "Witness",
+				Rig:      rig.Name,
+				State:    agentState(rig.Witness),
+				Metadata: rig.Witness.metadata(),
 			})
 		}
 
@@ -136,14 +730,20 @@ func (p *Poller) buildFromStatus(status gtStatusOutput) ([]state.Node, []state.E
 		if rig.Refinery.Name != "" {
 			rID := rig.Name + "/refinery"
 			nodes = append(nodes, state.Node{
-				ID:    rID,
-				Type:  "refinery",
-				Label: "Refinery",
-				Rig:   rig.Name,
-				State: orDefault(rig.Refinery.State, "running"),
+				ID:       rID,
+				Type:     "refinery",
+				Label:    "Refinery",
+				Rig:      rig.Name,
+				State:    agentState(rig.Refinery),
+				Metadata: rig.Refinery.metadata(),
 			})
 		}
 
+		// Town-level edges into the rig.
+		if anchor := rigAnchor(rig); anchor != "" {
+			edges = append(edges, townEdges(rig.Name, anchor, hasDeacon)...)
+		}
+
 		// Polecats.
 		for _, pc := range rig.Polecats {
 			pcID := rig.Name + "/polecats/" + pc.Name
@@ -152,18 +752,12 @@ func (p *Poller) buildFromStatus(status gtStatusOutput) ([]state.Node, []state.E
 				Type:     "polecat",
 				Label:    pc.Name,
 				Rig:      rig.Name,
-				State:    orDefault(pc.State, "idle"),
-				Metadata: pc.Details,
+				State:    pc.stateOr("idle"),
+				Metadata: pc.metadata(),
 			})
 
-			// Assignment edge from mayor.
-			if hookBead, ok := pc.Details["hooked_bead"]; ok && hookBead != "" {
-				edges = append(edges, state.Edge{
-					Source: "mayor",
-					Target: pcID,
-					Type:   "assignment",
-					Label:  hookBead,
-				})
+			if hookBead := pc.Details["hooked_bead"]; hookBead != "" {
+				edges = append(edges, hookEdge(pcID, hookBead))
 			}
 
 			// Monitoring edge from witness.
@@ -179,11 +773,12 @@ func (p *Poller) buildFromStatus(status gtStatusOutput) ([]state.Node, []state.E
 		for _, cr := range rig.Crew {
 			crID := rig.Name + "/crew/" + cr.Name
 			nodes = append(nodes, state.Node{
-				ID:    crID,
-				Type:  "crew",
-				Label: cr.Name,
-				Rig:   rig.Name,
-				State: orDefault(cr.State, "idle"),
+				ID:       crID,
+				Type:     "crew",
+				Label:    cr.Name,
+				Rig:      rig.Name,
+				State:    cr.stateOr("idle"),
+				Metadata: cr.metadata(),
 			})
 		}
 	}
@@ -191,30 +786,101 @@ func (p *Poller) buildFromStatus(status gtStatusOutput) ([]state.Node, []state.E
 	return nodes, edges
 }
 
-func (p *Poller) buildFromCommands() ([]state.Node, []state.Edge) {
+func (p *Poller) buildFromCommands(ctx context.Context) ([]state.Node, []state.Edge, error) {
 	var nodes []state.Node
 	var edges []state.Edge
 
-	// Add mayor.
+	// Without gt status there is nothing to tell us whether the mayor is up.
 	nodes = append(nodes, state.Node{
 		ID:    "mayor",
 		Type:  "mayor",
 		Label: "Mayor",
-		State: "running",
+		State: "unknown",
 	})
 
+	pcNodes, pcEdges, rigs, err := p.listPolecats(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	nodes = append(nodes, pcNodes...)
+	edges = append(edges, pcEdges...)
+
+	// Link the rigs the polecats belong to. Their witness and refinery come
+	// from the rigs source, which knows whether they are actually running.
+	for rig := range rigs {
+		wID := rig + "/witness"
+		edges = append(edges, townEdges(rig, wID, false)...)
+
+		// Monitoring edges for all polecats in this rig.
+		for _, n := range nodes {
+			if n.Type == "polecat" && n.Rig == rig {
+				edges = append(edges, state.Edge{
+					Source: wID,
+					Target: n.ID,
+					Type:   "monitoring",
+				})
+			}
+		}
+	}
+
+	return nodes, edges, nil
+}
+
+// rigAnchor picks the node town-level edges into a rig attach to: the
+// witness, or the refinery when the rig has no witness.
+func rigAnchor(rig rigInfo) string {
+	switch {
+	case rig.Witness.Name != "":
+		return rig.Name + "/witness"
+	case rig.Refinery.Name != "":
+		return rig.Name + "/refinery"
+	default:
+		return ""
+	}
+}
+
+// townEdges links the mayor, and the deacon if present, to a rig's anchor node.
+func townEdges(rig, anchor string, deacon bool) []state.Edge {
+	edges := []state.Edge{{
+		Source: "mayor",
+		Target: anchor,
+		Type:   "town",
+		Label:  rig,
+	}}
+	if deacon {
+		edges = append(edges, state.Edge{
+			Source: "deacon",
+			Target: anchor,
+			Type:   "monitoring",
+		})
+	}
+	return edges
+}
+
+// listPolecats runs `gt polecat list --all`, preferring JSON output, and
+// returns the polecat nodes, their assignment edges and the rigs they belong to.
+func (p *Poller) listPolecats(ctx context.Context) ([]state.Node, []state.Edge, map[string]bool, error) {
+	var nodes []state.Node
+	var edges []state.Edge
+
 	// Try gt polecat list --all --json.
-	polecatOut := p.runCmd("gt", "polecat", "list", "--all", "--json")
+	polecatOut, err := p.runCmd(ctx, "gt", "polecat", "list", "--all", "--json")
 	var polecats []struct {
-		Name  string `json:"name"`
-		Rig   string `json:"rig"`
-		State string `json:"state"`
-		Hook  string `json:"hook"`
+		Name    string       `json:"name"`
+		Rig     string       `json:"rig"`
+		State   string       `json:"state"`
+		Hook    string       `json:"hook"`
+		Details agentDetails `json:"details"`
 	}
 
 	rigs := make(map[string]bool)
 
-	if err := json.Unmarshal([]byte(polecatOut), &polecats); err == nil {
+	if err == nil {
+		if err = decodeJSON(ctx, "gt polecat list", polecatOut, &polecats); err != nil {
+			recorderFrom(ctx).warn("gt polecat list: unreadable JSON, falling back to text: " + err.Error())
+		}
+	}
+	if err == nil {
 		for _, pc := range polecats {
 			rigs[pc.Rig] = true
 			pcID := pc.Rig + "/polecats/" + pc.Name
@@ -224,59 +890,25 @@ func (p *Poller) buildFromCommands() ([]state.Node, []state.Edge) {
 				Label: pc.Name,
 				Rig:   pc.Rig,
 				State: orDefault(pc.State, "idle"),
-				Metadata: map[string]string{
+				Metadata: mergeDetails(map[string]string{
 					"hooked_bead": pc.Hook,
-				},
+				}, pc.Details),
 			})
 
 			if pc.Hook != "" {
-				edges = append(edges, state.Edge{
-					Source: "mayor",
-					Target: pcID,
-					Type:   "assignment",
-					Label:  pc.Hook,
-				})
+				edges = append(edges, hookEdge(pcID, pc.Hook))
 			}
 		}
 	} else {
 		// Fallback: parse text output of gt polecat list --all
-		textOut := p.runCmd("gt", "polecat", "list", "--all")
-		nodes, edges, rigs = parsePolecatText(textOut, nodes, edges)
-	}
-
-	// Add rig-level agents for discovered rigs.
-	for rig := range rigs {
-		wID := rig + "/witness"
-		nodes = append(nodes, state.Node{
-			ID:    wID,
-			Type:  "witness",
-			Label: "Witness",
-			Rig:   rig,
-			State: "running",
-		})
-
-		rID := rig + "/refinery"
-		nodes = append(nodes, state.Node{
-			ID:    rID,
-			Type:  "refinery",
-			Label: "Refinery",
-			Rig:   rig,
-			State: "running",
-		})
-
-		// Monitoring edges for all polecats in this rig.
-		for _, n := range nodes {
-			if n.Type == "polecat" && n.Rig == rig {
-				edges = append(edges, state.Edge{
-					Source: wID,
-					Target: n.ID,
-					Type:   "monitoring",
-				})
-			}
+		textOut, textErr := p.runCmd(ctx, "gt", "polecat", "list", "--all")
+		if textErr != nil {
+			return nil, nil, nil, fmt.Errorf("%v; text fallback: %w", err, textErr)
 		}
+		nodes, edges, rigs = parsePolecatText(textOut, nodes, edges)
 	}
 
-	return nodes, edges
+	return nodes, edges, rigs, nil
 }
 
 func parsePolecatText(text string, nodes []state.Node, edges []state.Edge) ([]state.Node, []state.Edge, map[string]bool) {
@@ -313,50 +945,245 @@ func parsePolecatText(text string, nodes []state.Node, edges []state.Edge) ([]st
 				},
 			})
 			if hook != "" {
-				edges = append(edges, state.Edge{
-					Source: "mayor",
-					Target: pcID,
-					Type:   "assignment",
-					Label:  hook,
-				})
+				edges = append(edges, hookEdge(pcID, hook))
 			}
 		}
 	}
 	return nodes, edges, rigs
 }
 
-func (p *Poller) collectBeads() ([]state.Node, []state.Edge) {
+// hookEdge links a polecat to the bead on its hook.
+func hookEdge(polecatID, bead string) state.Edge {
+	return state.Edge{
+		Source: polecatID,
+		Target: "bead:" + bead,
+		Type:   "hook",
+	}
+}
+
+// collectBeads lists the town's beads database and each rig's, tagging every
+// bead with the rig that owns it. A bead reported by several databases is
+// kept once, preferring the copy from its owner. A database that fails to
+// list keeps the beads it last reported, marked stale; the source fails only
+// when every database does.
+func (p *Poller) collectBeads(ctx context.Context) (state.Fragment, error) {
 	var nodes []state.Node
 	var edges []state.Edge
 
-	out := p.runCmd("bd", "list", "--json")
+	dbs := p.beadDBs()
+	lists := make([][]beadInfo, len(dbs))
+	errs := make([]error, len(dbs))
+	parallel(len(dbs), func(i int) {
+		out, err := p.runCmdIn(ctx, dbs[i].dir, "bd", "list", "--json")
+		if err == nil {
+			if err = decodeJSON(ctx, "bd list", out, &lists[i]); err != nil {
+				err = fmt.Errorf("bd list in %s: %w", orDefault(dbs[i].rig, "town"), err)
+			}
+		}
+		errs[i] = err
+	})
+	if err := allFailed(errs); err != nil {
+		return state.Fragment{}, err
+	}
+	stale := p.beads.fillFailed(dbs, lists, errs)
+
+	routes := p.loadBeadRoutes()
 	var beads []beadInfo
-	if err := json.Unmarshal([]byte(out), &beads); err == nil {
-		for _, b := range beads {
-			beadState := mapBeadStatus(b.Status)
-			nodes = append(nodes, state.Node{
-				ID:    "bead:" + b.ID,
-				Type:  "bead",
-				Label: b.ID,
-				State: beadState,
-				Metadata: map[string]string{
-					"title":    b.Title,
-					"assignee": b.Assignee,
-				},
+	rigOf := make(map[string]string)
+	staleOf := make(map[string]bool)
+	owned := make(map[string]bool)
+	index := make(map[string]int)
+	for i, list := range lists {
+		for _, b := range list {
+			rig, routed := routes.rigFor(b.ID)
+			if !routed {
+				rig = dbs[i].rig
+			}
+			fromOwner := dbs[i].rig == rig
+			j, dup := index[b.ID]
+			switch {
+			case !dup:
+				index[b.ID] = len(beads)
+				beads = append(beads, b)
+			case fromOwner && !owned[b.ID]:
+				beads[j] = b
+			default:
+				continue
+			}
+			rigOf[b.ID], owned[b.ID], staleOf[b.ID] = rig, fromOwner, stale[i]
+		}
+	}
+
+	deps := beadDeps(beads)
+
+	for _, b := range beads {
+		beadState := mapBeadStatus(b.Status)
+		assignee := assigneeID(b.Assignee, rigOf[b.ID])
+		nodes = append(nodes, state.Node{
+			ID:    "bead:" + b.ID,
+			Type:  "bead",
+			Label: b.ID,
+			State: beadState,
+			Rig:   rigOf[b.ID],
+			Stale: staleOf[b.ID],
+			Metadata: map[string]string{
+				"title":      b.Title,
+				"assignee":   orDefault(assignee, b.Assignee),
+				"blocks":     strings.Join(deps.blocks[b.ID], ", "),
+				"blocked_by": strings.Join(deps.blockedBy[b.ID], ", "),
+			},
+		})
+
+		// Edge from bead to its assignee, when the assignee is an agent.
+		if assignee != "" {
+			edges = append(edges, state.Edge{
+				Source: "bead:" + b.ID,
+				Target: assignee,
+				Type:   "assignment",
 			})
+		}
+	}
 
-			// Edge from bead to its assignee.
-			if b.Assignee != "" {
-				edges = append(edges, state.Edge{
-					Source: "bead:" + b.ID,
-					Target: b.Assignee,
-					Type:   "assignment",
-				})
+	return state.Fragment{Nodes: nodes, Edges: deps.appendEdges(edges)}, nil
+}
+
+func (p *Poller) collectConvoys(ctx context.Context) (state.Fragment, error) {
+	var nodes []state.Node
+	var edges []state.Edge
+
+	out, err := p.runCmd(ctx, "gt", "convoy", "list", "--json")
+	if err != nil {
+		return state.Fragment{}, err
+	}
+	var convoys []convoyInfo
+	if err := decodeJSON(ctx, "gt convoy list", out, &convoys); err != nil {
+		return state.Fragment{}, fmt.Errorf("gt convoy list: %w", err)
+	}
+	for _, c := range convoys {
+		cID := "convoy:" + c.ID
+
+		// Prefer counting tracked beads ourselves; fall back to the totals
+		// gt reports when the tracked list is omitted.
+		tracked, completed := c.Total, c.Completed
+		if len(c.Tracked) > 0 {
+			tracked, completed = len(c.Tracked), 0
+			for _, t := range c.Tracked {
+				if mapBeadStatus(t.Status) == "closed" {
+					completed++
+				}
+			}
+		}
+		progress := 0
+		if tracked > 0 {
+			progress = completed * 100 / tracked
+		}
+
+		nodes = append(nodes, state.Node{
+			ID:    cID,
+			Type:  "convoy",
+			Label: c.ID,
+			State: mapConvoyStatus(c.Status, tracked, completed),
+			Metadata: map[string]string{
+				"title":     c.Title,
+				"tracked":   strconv.Itoa(tracked),
+				"completed": strconv.Itoa(completed),
+				"progress":  strconv.Itoa(progress) + "%",
+			},
+		})
+
+		// Tracking edges from the convoy to each of its beads.
+		for _, t := range c.Tracked {
+			if t.ID == "" {
+				continue
 			}
+			edges = append(edges, state.Edge{
+				Source: cID,
+				Target: "bead:" + t.ID,
+				Type:   "convoy_tracking",
+			})
 		}
 	}
 
-	return nodes, edges
+	return state.Fragment{Nodes: nodes, Edges: edges}, nil
+}
+
+// depSet collects blocking links between beads. Each blocker/blocked pair is
+// kept once, whichever side reports it.
+type depSet struct {
+	links     []depLink
+	seen      map[depLink]bool
+	blocks    map[string][]string
+	blockedBy map[string][]string
+}
+
+type depLink struct {
+	blocker, blocked string
+}
+
+// beadDeps collects the blocking links the beads report, from either side.
+func beadDeps(beads []beadInfo) *depSet {
+	deps := newDepSet()
+	for _, b := range beads {
+		for _, d := range b.Dependencies {
+			if d.blocking() {
+				deps.add(d.other(b.ID), b.ID)
+			}
+		}
+		for _, d := range b.Dependents {
+			if d.blocking() {
+				deps.add(b.ID, d.other(b.ID))
+			}
+		}
+	}
+	return deps
+}
+
+func newDepSet() *depSet {
+	return &depSet{
+		seen:      make(map[depLink]bool),
+		blocks:    make(map[string][]string),
+		blockedBy: make(map[string][]string),
+	}
+}
+
+func (s *depSet) add(blocker, blocked string) {
+	l := depLink{blocker: blocker, blocked: blocked}
+	if blocker == "" || blocked == "" || blocker == blocked || s.seen[l] {
+		return
+	}
+	s.seen[l] = true
+	s.links = append(s.links, l)
+	s.blocks[blocker] = append(s.blocks[blocker], blocked)
+	s.blockedBy[blocked] = append(s.blockedBy[blocked], blocker)
+}
+
+// appendEdges emits one dependency edge per link, always drawn blocker →
+// blocked, so a link looks the same whichever bead reported it.
+func (s *depSet) appendEdges(edges []state.Edge) []state.Edge {
+	for _, l := range s.links {
+		edges = append(edges, state.Edge{
+			Source:   "bead:" + l.blocker,
+			Target:   "bead:" + l.blocked,
+			Type:     "dependency",
+			Label:    "blocks",
+			Metadata: map[string]string{"direction": "blocks"},
+		})
+	}
+	return edges
+}
+
+func mapConvoyStatus(status string, tracked, completed int) string {
+	switch strings.ToLower(status) {
+	case "closed", "landed", "done", "completed":
+		return "completed"
+	case "":
+		if tracked > 0 && completed == tracked {
+			return "completed"
+		}
+		return "active"
+	default:
+		return "active"
+	}
 }
 
 func mapBeadStatus(status string) string {
@@ -374,40 +1201,6 @@ func mapBeadStatus(status string) string {
 	}
 }
 
-func (p *Poller) runCmd(name string, args ...string) string {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	cmd := exec.CommandContext(ctx, name, args...)
-	cmd.Dir = p.root
-	out, err := cmd.Output()
-	if err != nil {
-		log.Printf("poller: %s %v: %v", name, args, err)
-		return ""
-	}
-	return strings.TrimSpace(string(out))
-}
-
-func countByType(nodes []state.Node, typ string) int {
-	n := 0
-	for _, node := range nodes {
-		if node.Type == typ {
-			n++
-		}
-	}
-	return n
-}
-
-func countByTypeAndState(nodes []state.Node, typ, st string) int {
-	n := 0
-	for _, node := range nodes {
-		if node.Type == typ && node.State == st {
-			n++
-		}
-	}
-	return n
-}
-
 func orDefault(s, def string) string {
 	if s == "" {
 		return def
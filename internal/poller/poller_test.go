@@ -0,0 +1,290 @@
+package poller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gronitab/zeppelin/internal/state"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files in testdata")
+
+// replayPoller returns a poller that replays the fixtures recorded in
+// testdata/<name>/commands against the town in testdata/<name>/town.
+func replayPoller(name string) *Poller {
+	dir := filepath.Join("testdata", name)
+	root := filepath.Join(dir, "town")
+	runner := &ReplayRunner{Dir: filepath.Join(dir, "commands"), Root: root}
+	return New(state.NewStore(), root, Options{Runner: runner}, func(*state.Diff) {})
+}
+
+// checkGolden compares a fragment, in a stable order, with
+// testdata/<name>/<file>.
+func checkGolden(t *testing.T, name, file string, f state.Fragment) {
+	t.Helper()
+	sort.Slice(f.Nodes, func(i, j int) bool { return f.Nodes[i].ID < f.Nodes[j].ID })
+	sort.Slice(f.Edges, func(i, j int) bool {
+		a, b := f.Edges[i], f.Edges[j]
+		return a.Source+" "+a.Target+" "+a.Type < b.Source+" "+b.Target+" "+b.Type
+	})
+	got, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = append(got, '\n')
+
+	path := filepath.Join("testdata", name, file)
+	if *update {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s mismatch (rerun with -update to accept):\n%s", path, got)
+	}
+}
+
+func TestTopologyFromStatus(t *testing.T) {
+	p := replayPoller("status")
+	f, err := p.collectTopology(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, "status", "topology.golden.json", f)
+}
+
+func TestTopologyFromCommands(t *testing.T) {
+	p := replayPoller("fallback")
+	f, err := p.collectTopology(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, "fallback", "topology.golden.json", f)
+}
+
+func TestCollectBeads(t *testing.T) {
+	p := replayPoller("beads")
+	f, err := p.collectBeads(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, "beads", "beads.golden.json", f)
+}
+
+func TestCollectBeadsKeepsFailingDatabase(t *testing.T) {
+	dir := filepath.Join("testdata", "beads")
+	root := filepath.Join(dir, "town")
+	replay := &ReplayRunner{Dir: filepath.Join(dir, "commands"), Root: root}
+	failing := false
+	runner := runnerFunc(func(ctx context.Context, c Command) ([]byte, []byte, error) {
+		if failing && c.Dir != root {
+			return nil, []byte("database locked"), os.ErrPermission
+		}
+		return replay.Run(ctx, c)
+	})
+	p := New(state.NewStore(), root, Options{Runner: runner}, func(*state.Diff) {})
+	ctx := context.Background()
+
+	if _, err := p.collectBeads(ctx); err != nil {
+		t.Fatal(err)
+	}
+	failing = true
+	f, err := p.collectBeads(ctx)
+	if err != nil {
+		t.Fatalf("expected one failing database not to fail the source, got %v", err)
+	}
+	stale := map[string]bool{}
+	for _, n := range f.Nodes {
+		stale[n.ID] = n.Stale
+	}
+	if len(stale) != 3 || !stale["bead:gt-12"] || !stale["bead:gt-10"] || stale["bead:hq-1"] {
+		t.Errorf("expected the gastown beads kept and stale, the town's fresh; got %v", stale)
+	}
+}
+
+func TestRigNames(t *testing.T) {
+	// testdata/beads/town has a gastown directory, routed to in routes.jsonl.
+	p := replayPoller("beads")
+	p.latest["topology"] = state.Fragment{Nodes: []state.Node{
+		{ID: "beads/witness", Type: "witness", Rig: "beads"},
+		{ID: "bead:xx-1", Type: "bead", Rig: "elsewhere"},
+	}}
+	got := p.rigNames()
+	if len(got) != 2 || got[0] != "beads" || got[1] != "gastown" {
+		t.Errorf("rigNames() = %v, want [beads gastown]", got)
+	}
+
+	// Directories neither the topology nor the routes name are not rigs.
+	p = New(state.NewStore(), t.TempDir(), Options{}, func(*state.Diff) {})
+	os.Mkdir(filepath.Join(p.root, "notes"), 0o755)
+	if got := p.rigNames(); len(got) != 0 {
+		t.Errorf("rigNames() = %v, want none", got)
+	}
+}
+
+func TestRecordThenReplay(t *testing.T) {
+	root, fixtures := t.TempDir(), t.TempDir()
+	fake := runnerFunc(func(ctx context.Context, c Command) ([]byte, []byte, error) {
+		if c.Name == "bd" {
+			return nil, []byte("no database"), os.ErrNotExist
+		}
+		return []byte(`{"rigs":[]}`), nil, nil
+	})
+	rec := &RecordingRunner{Runner: fake, Dir: fixtures, Root: root}
+	replay := &ReplayRunner{Dir: fixtures, Root: root}
+	ctx := context.Background()
+
+	status := Command{Dir: root, Name: "gt", Args: []string{"status", "--json"}}
+	beads := Command{Dir: filepath.Join(root, "gastown"), Name: "bd", Args: []string{"list", "--json"}}
+	for _, c := range []Command{status, beads} {
+		if _, _, err := rec.Run(ctx, c); err != nil && c.Name != "bd" {
+			t.Fatal(err)
+		}
+	}
+
+	out, _, err := replay.Run(ctx, status)
+	if err != nil || string(out) != `{"rigs":[]}` {
+		t.Errorf("replay %s = %q, %v", status, out, err)
+	}
+	_, stderr, err := replay.Run(ctx, beads)
+	if err == nil || string(stderr) != "no database" {
+		t.Errorf("replay %s = stderr %q, err %v; want the recorded failure", beads, stderr, err)
+	}
+	if _, _, err := replay.Run(ctx, Command{Dir: root, Name: "gt", Args: []string{"mail"}}); err != ErrNotRecorded {
+		t.Errorf("unrecorded command: err = %v, want ErrNotRecorded", err)
+	}
+}
+
+type runnerFunc func(ctx context.Context, c Command) ([]byte, []byte, error)
+
+func (f runnerFunc) Run(ctx context.Context, c Command) ([]byte, []byte, error) { return f(ctx, c) }
+
+func TestRestoredDroppedOncePolled(t *testing.T) {
+	store := state.NewStore()
+	store.Restore(state.Snapshot{Nodes: []state.Node{{ID: "gastown/polecats/ghost", Type: "polecat", State: "working"}}}, nil)
+	// Every command fails, so no source ever reports.
+	runner := runnerFunc(func(ctx context.Context, c Command) ([]byte, []byte, error) {
+		return nil, []byte("not a town"), os.ErrNotExist
+	})
+	p := New(store, t.TempDir(), Options{Runner: runner}, func(*state.Diff) {})
+
+	for i, src := range p.sources {
+		if len(store.GetSnapshot().Nodes) == 0 {
+			t.Fatalf("restored state dropped after %d of %d sources polled", i, len(p.sources))
+		}
+		p.poll(context.Background(), src)
+	}
+	if nodes := store.GetSnapshot().Nodes; len(nodes) != 0 {
+		t.Errorf("expected the restored state dropped once every source polled, got %+v", nodes)
+	}
+}
+
+func TestAppendActivityInVersionOrder(t *testing.T) {
+	var versions []uint64
+	p := New(state.NewStore(), t.TempDir(), Options{}, func(d *state.Diff) {
+		versions = append(versions, d.Version)
+	})
+	parallel(50, func(i int) {
+		p.AppendActivity(state.Activity{Event: "hook", Detail: strconv.Itoa(i)})
+	})
+	if len(versions) != 50 {
+		t.Fatalf("expected 50 diffs, got %d", len(versions))
+	}
+	for i := 1; i < len(versions); i++ {
+		if versions[i] <= versions[i-1] {
+			t.Fatalf("diffs published out of order: %v", versions)
+		}
+	}
+}
+
+func TestNextInterval(t *testing.T) {
+	viewers := 1
+	p := New(state.NewStore(), t.TempDir(), Options{
+		Viewers:     func() int { return viewers },
+		QuietAfter:  10 * time.Minute,
+		MaxInterval: 5 * time.Minute,
+	}, func(*state.Diff) {})
+	now := time.Now()
+
+	cases := []struct {
+		name    string
+		base    time.Duration
+		since   time.Duration
+		viewers int
+		want    time.Duration
+	}{
+		{"after a change", 10 * time.Second, 5 * time.Second, 1, 5 * time.Second},
+		{"burst floor", time.Second, 5 * time.Second, 1, time.Second},
+		{"steady", 10 * time.Second, time.Minute, 1, 10 * time.Second},
+		{"quiet town", 10 * time.Second, time.Hour, 1, 40 * time.Second},
+		{"nobody watching", 10 * time.Second, time.Minute, 0, time.Minute},
+		{"quiet and unwatched", 10 * time.Second, time.Hour, 0, 4 * time.Minute},
+		{"capped", 30 * time.Second, time.Hour, 0, 5 * time.Minute},
+		{"cap never shortens", 10 * time.Minute, time.Hour, 0, 10 * time.Minute},
+	}
+	for _, c := range cases {
+		p.changed = now.Add(-c.since)
+		viewers = c.viewers
+		if got := p.nextInterval(c.base, now); got != c.want {
+			t.Errorf("%s: nextInterval(%v) = %v, want %v", c.name, c.base, got, c.want)
+		}
+	}
+}
+
+func TestCollectRigs(t *testing.T) {
+	p := replayPoller("rigs")
+	f, err := p.collectRigs(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, "rigs", "rigs.golden.json", f)
+}
+
+func TestBeadDeps(t *testing.T) {
+	// gt-1 blocks gt-2, which blocks gt-3; each link is reported by the
+	// blocked bead, the blocker, or both.
+	blocked := beadInfo{ID: "gt-2", Dependencies: []beadDep{{IssueID: "gt-2", DependsOnID: "gt-1", Type: "blocks"}}}
+	blocker := beadInfo{ID: "gt-1", Dependents: []beadDep{{ID: "gt-2", DependencyType: "blocks"}}}
+	next := beadInfo{ID: "gt-3", Dependencies: []beadDep{
+		{ID: "gt-2", DependencyType: "blocks"},
+		{ID: "gt-1", DependencyType: "related"},
+	}}
+
+	cases := map[string][]beadInfo{
+		"blocked first": {blocked, blocker, next},
+		"blocker first": {blocker, blocked, next},
+		"blocked only":  {blocked, next},
+		"blocker only":  {blocker, next},
+	}
+	want := [][2]string{{"bead:gt-1", "bead:gt-2"}, {"bead:gt-2", "bead:gt-3"}}
+	for name, beads := range cases {
+		deps := beadDeps(beads)
+		edges := deps.appendEdges(nil)
+		if len(edges) != len(want) {
+			t.Errorf("%s: expected %d edges, got %+v", name, len(want), edges)
+			continue
+		}
+		for i, e := range edges {
+			if e.Source != want[i][0] || e.Target != want[i][1] || e.Metadata["direction"] != "blocks" {
+				t.Errorf("%s: edge %s → %s (%s), want %s → %s (blocks)",
+					name, e.Source, e.Target, e.Metadata["direction"], want[i][0], want[i][1])
+			}
+		}
+		if got := deps.blockedBy["gt-2"]; len(got) != 1 || got[0] != "gt-1" {
+			t.Errorf("%s: gt-2 blocked by %v, want [gt-1]", name, got)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package poller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gronitab/zeppelin/internal/state"
+)
+
+// rigListEntry represents a rig from `gt rig list --json`.
+type rigListEntry struct {
+	Name         string         `json:"name"`
+	Status       string         `json:"status"`
+	Witness      rigAgentStatus `json:"witness"`
+	Refinery     rigAgentStatus `json:"refinery"`
+	Polecats     listCount      `json:"polecats"`
+	PolecatCount int            `json:"polecat_count"`
+	Crew         listCount      `json:"crew"`
+	CrewCount    int            `json:"crew_count"`
+}
+
+// rigAgentStatus is a rig agent's status, which gt reports as a state
+// string, a running flag, or an object with either and the agent's details.
+type rigAgentStatus struct {
+	State   string
+	Details agentDetails
+}
+
+func (s *rigAgentStatus) UnmarshalJSON(data []byte) error {
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	switch v := v.(type) {
+	case string:
+		s.State = v
+	case bool:
+		s.State = runningState(v)
+	case map[string]any:
+		if st, ok := v["state"].(string); ok && st != "" {
+			s.State = st
+		} else if st, ok := v["status"].(string); ok && st != "" {
+			s.State = st
+		} else if r, ok := v["running"].(bool); ok {
+			s.State = runningState(r)
+		}
+		if d, ok := v["details"].(map[string]any); ok {
+			s.Details = make(agentDetails, len(d))
+			for k, dv := range d {
+				if str := jsonString(dv); str != "" {
+					s.Details[k] = str
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func runningState(running bool) string {
+	if running {
+		return "running"
+	}
+	return "stopped"
+}
+
+// listCount is a count that gt reports either as a number or as the list of
+// things counted.
+type listCount int
+
+func (c *listCount) UnmarshalJSON(data []byte) error {
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	switch v := v.(type) {
+	case float64:
+		*c = listCount(v)
+	case []any:
+		*c = listCount(len(v))
+	}
+	return nil
+}
+
+// health returns the rig's overall state: gt's own verdict when it gives
+// one, else "healthy" when both the witness and refinery are running and
+// "degraded" when either is not.
+func (r rigListEntry) health() string {
+	switch strings.ToLower(r.Status) {
+	case "ok", "healthy", "running", "active":
+		return "healthy"
+	case "":
+	default:
+		return strings.ToLower(r.Status)
+	}
+	if r.Witness.State == "" && r.Refinery.State == "" {
+		return "unknown"
+	}
+	if r.Witness.State == "running" && r.Refinery.State == "running" {
+		return "healthy"
+	}
+	return "degraded"
+}
+
+// metadata returns the rig-level details carried by the rig's agents.
+func (r rigListEntry) metadata() map[string]string {
+	return map[string]string{
+		"rig_health":   r.health(),
+		"rig_polecats": strconv.Itoa(max(int(r.Polecats), r.PolecatCount)),
+		"rig_crew":     strconv.Itoa(max(int(r.Crew), r.CrewCount)),
+	}
+}
+
+// collectRigs reports the witness and refinery of every rig from
+// `gt rig list --json` with their real state, so rigs show up, and dead
+// agents show as dead, even when `gt status` fails. Both nodes carry the
+// rig's polecat and crew counts and its health.
+func (p *Poller) collectRigs(ctx context.Context) (state.Fragment, error) {
+	out, err := p.runCmd(ctx, "gt", "rig", "list", "--json")
+	if err != nil {
+		return state.Fragment{}, err
+	}
+	var rigs []rigListEntry
+	if err := decodeJSON(ctx, "gt rig list", out, &rigs); err != nil {
+		return state.Fragment{}, fmt.Errorf("gt rig list: %w", err)
+	}
+
+	var f state.Fragment
+	for _, r := range rigs {
+		if r.Name == "" {
+			continue
+		}
+		f.Nodes = append(f.Nodes,
+			state.Node{
+				ID:       r.Name + "/witness",
+				Type:     "witness",
+				Label:    "Witness",
+				Rig:      r.Name,
+				State:    orDefault(r.Witness.State, "unknown"),
+				Metadata: mergeDetails(r.metadata(), r.Witness.Details),
+			},
+			state.Node{
+				ID:       r.Name + "/refinery",
+				Type:     "refinery",
+				Label:    "Refinery",
+				Rig:      r.Name,
+				State:    orDefault(r.Refinery.State, "unknown"),
+				Metadata: mergeDetails(r.metadata(), r.Refinery.Details),
+			},
+		)
+	}
+	return f, nil
+}
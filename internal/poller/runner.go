@@ -0,0 +1,170 @@
+package poller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Command is one run of an external command by the poller.
+type Command struct {
+	Dir  string // absolute working directory
+	Name string
+	Args []string
+}
+
+func (c Command) String() string {
+	return strings.Join(append([]string{c.Name}, c.Args...), " ")
+}
+
+// CommandRunner runs the poller's gt/bd commands. It returns the command's
+// stdout and stderr; an error means the command could not start or exited
+// unsuccessfully.
+type CommandRunner interface {
+	Run(ctx context.Context, cmd Command) (stdout, stderr []byte, err error)
+}
+
+// waitDelay bounds how long a killed command's output is waited for, in case
+// processes it started, such as a tmux server, keep its pipes open.
+const waitDelay = 2 * time.Second
+
+// ExecRunner runs commands as child processes, killing them when ctx is done.
+type ExecRunner struct {
+	// Binaries maps command names to the executables run for them, such as
+	// "gt" to "/opt/gastown/bin/gt". Other commands are looked up in PATH.
+	Binaries map[string]string
+	// Env is added to the environment commands inherit, as "KEY=VALUE".
+	Env []string
+}
+
+func (r ExecRunner) Run(ctx context.Context, c Command) ([]byte, []byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, orDefault(r.Binaries[c.Name], c.Name), c.Args...)
+	cmd.Dir = c.Dir
+	if len(r.Env) > 0 {
+		cmd.Env = append(os.Environ(), r.Env...)
+	}
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	cmd.WaitDelay = waitDelay
+	err := cmd.Run()
+	return stdout.Bytes(), stderr.Bytes(), err
+}
+
+// fixture is the recorded result of a command. Dir is relative to the town
+// root, so fixtures replay against any root.
+type fixture struct {
+	Dir     string   `json:"dir"`
+	Command []string `json:"command"`
+	Stdout  string   `json:"stdout"`
+	Stderr  string   `json:"stderr,omitempty"`
+	Error   string   `json:"error,omitempty"`
+}
+
+// fixturePath names the fixture file for a command, such as
+// "gt_status_--json.json" or "gastown_bd_list_--json.json" for a command
+// run in the gastown rig.
+func fixturePath(dir, root string, c Command) (string, string) {
+	rel, err := filepath.Rel(root, c.Dir)
+	if err != nil {
+		rel = c.Dir
+	}
+	rel = filepath.ToSlash(rel)
+
+	key := c.String()
+	if rel != "." {
+		key = rel + " " + key
+	}
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-':
+			return r
+		default:
+			return '_'
+		}
+	}, key)
+	return filepath.Join(dir, name+".json"), rel
+}
+
+// RecordingRunner runs commands with Runner and saves each result to a
+// fixture in Dir, replacing the previous result of the same command.
+type RecordingRunner struct {
+	Runner CommandRunner
+	Dir    string // fixture directory
+	Root   string // town root the commands run under
+}
+
+func (r *RecordingRunner) Run(ctx context.Context, c Command) ([]byte, []byte, error) {
+	stdout, stderr, err := r.Runner.Run(ctx, c)
+	if ctx.Err() != nil {
+		// Cancelled or timed out: not the command's real output.
+		return stdout, stderr, err
+	}
+
+	path, rel := fixturePath(r.Dir, r.Root, c)
+	fx := fixture{
+		Dir:     rel,
+		Command: append([]string{c.Name}, c.Args...),
+		Stdout:  string(stdout),
+		Stderr:  string(stderr),
+	}
+	if err != nil {
+		fx.Error = err.Error()
+	}
+	if werr := writeFixture(path, fx); werr != nil {
+		log.Printf("poller: recording %s: %v", c, werr)
+	}
+	return stdout, stderr, err
+}
+
+func writeFixture(path string, fx fixture) error {
+	data, err := json.MarshalIndent(fx, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// ErrNotRecorded is returned by ReplayRunner for commands with no fixture.
+var ErrNotRecorded = errors.New("no recorded output")
+
+// ReplayRunner serves the results saved by a RecordingRunner instead of
+// running commands. Commands must run under Root as they did when recorded;
+// anything the poller reads from the filesystem still comes from Root.
+type ReplayRunner struct {
+	Dir  string // fixture directory
+	Root string
+}
+
+func (r *ReplayRunner) Run(ctx context.Context, c Command) ([]byte, []byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+	path, _ := fixturePath(r.Dir, r.Root, c)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil, ErrNotRecorded
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	var fx fixture
+	if err := json.Unmarshal(data, &fx); err != nil {
+		return nil, nil, fmt.Errorf("fixture %s: %w", filepath.Base(path), err)
+	}
+	if fx.Error != "" {
+		return []byte(fx.Stdout), []byte(fx.Stderr), errors.New(fx.Error)
+	}
+	return []byte(fx.Stdout), []byte(fx.Stderr), nil
+}
@@ -0,0 +1,245 @@
+package poller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// knownSchemas is the newest JSON schema version of each command's output
+// that this build understands. Output that does not declare a version is
+// taken to be version 1. Newer output is still decoded, with a warning.
+var knownSchemas = map[string]int{
+	"gt status":       1,
+	"gt polecat list": 1,
+	"gt rig list":     1,
+	"gt convoy list":  1,
+	"gt mail inbox":   1,
+	"gt mq list":      1,
+	"bd list":         1,
+	"bd mol current":  1,
+}
+
+// maxWarnings bounds the schema warnings kept per command and collection.
+const maxWarnings = 10
+
+// decodeJSON decodes the output of cmd, such as "gt status", into v. Empty
+// output means the command had nothing to report and leaves v untouched.
+//
+// Decoding is tolerant, so that a gt or bd upgrade degrades the graph rather
+// than blanking it: a list wrapped in an object is unwrapped, fields of an
+// unexpected type are left zero, and the rest is decoded. Only output that is
+// not JSON at all is an error. Newer schema versions, type mismatches,
+// unknown fields and missing required fields are reported as warnings on the
+// health of the source collecting under ctx.
+func decodeJSON(ctx context.Context, cmd, out string, v any) error {
+	if out == "" {
+		return nil
+	}
+	var raw any
+	if err := json.Unmarshal([]byte(out), &raw); err != nil {
+		return err
+	}
+	rec := recorderFrom(ctx)
+
+	if version, ok := schemaVersion(raw); ok && version > knownSchemas[cmd] {
+		rec.warn(fmt.Sprintf("%s: schema version %d is newer than supported version %d", cmd, version, knownSchemas[cmd]))
+	}
+
+	data := []byte(out)
+	t := reflect.TypeOf(v).Elem()
+	if obj, ok := raw.(map[string]any); ok && t.Kind() == reflect.Slice {
+		list, key := unwrapList(obj)
+		if key == "" {
+			return fmt.Errorf("expected a list, got an object with fields %s", strings.Join(sortedKeys(obj), ", "))
+		}
+		raw = list
+		data, _ = json.Marshal(list)
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if !errors.As(err, &typeErr) {
+			return err
+		}
+		// The rest of the output was still decoded.
+		rec.warn(fmt.Sprintf("%s: field %s: expected %s, got JSON %s", cmd, typeErr.Field, typeErr.Type, typeErr.Value))
+	}
+
+	var warnings []string
+	checkFields(raw, t, "", &warnings)
+	for _, w := range warnings[:min(len(warnings), maxWarnings)] {
+		rec.warn(cmd + ": " + w)
+	}
+	return nil
+}
+
+// versionKeys are the fields an output object may declare its schema
+// version in.
+var versionKeys = []string{"schema_version", "schemaVersion", "version"}
+
+// schemaVersion reads the version an output object declares, if any.
+func schemaVersion(raw any) (int, bool) {
+	obj, ok := raw.(map[string]any)
+	if !ok {
+		return 0, false
+	}
+	for _, key := range versionKeys {
+		switch v := obj[key].(type) {
+		case float64:
+			return int(v), true
+		case string:
+			// "2" or "v2"; a release string such as "0.4.1" is not a schema.
+			if n, err := strconv.Atoi(strings.TrimPrefix(v, "v")); err == nil {
+				return n, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// unwrapList finds the list in an object wrapping one, as in
+// {"schema_version": 2, "items": [...]}. It returns the list and its key,
+// or "" if the object does not hold exactly one list.
+func unwrapList(obj map[string]any) ([]any, string) {
+	var list []any
+	found := ""
+	for _, k := range sortedKeys(obj) {
+		if l, ok := obj[k].([]any); ok {
+			if found != "" {
+				return nil, ""
+			}
+			list, found = l, k
+		}
+	}
+	return list, found
+}
+
+// checkFields compares decoded JSON with the Go type it was decoded into,
+// appending a warning for each object field the type does not know and each
+// field tagged `schema:"required"` that is absent. Warnings for list
+// elements are reported once per path. Types that decode themselves are
+// trusted to be tolerant and not inspected.
+func checkFields(raw any, t reflect.Type, path string, warnings *[]string) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if reflect.PointerTo(t).Implements(reflect.TypeFor[json.Unmarshaler]()) {
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Slice:
+		list, ok := raw.([]any)
+		if !ok {
+			return
+		}
+		for _, el := range list {
+			checkFields(el, t.Elem(), path+"[]", warnings)
+		}
+	case reflect.Struct:
+		obj, ok := raw.(map[string]any)
+		if !ok {
+			return
+		}
+		fields := make(map[string]reflect.StructField)
+		if ig, ok := reflect.Zero(t).Interface().(fieldIgnorer); ok {
+			for _, name := range ig.ignoredFields() {
+				fields[name] = reflect.StructField{Type: reflect.TypeFor[any]()}
+			}
+		}
+		for i := range t.NumField() {
+			f := t.Field(i)
+			name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if name == "" || name == "-" {
+				continue
+			}
+			fields[name] = f
+			if f.Tag.Get("schema") == "required" {
+				if _, ok := obj[name]; !ok {
+					addWarning(warnings, "missing field "+join(path, name))
+				}
+			}
+		}
+		for _, k := range sortedKeys(obj) {
+			f, ok := fields[k]
+			if !ok {
+				if path != "" || !slices.Contains(versionKeys, k) {
+					addWarning(warnings, "unknown field "+join(path, k))
+				}
+				continue
+			}
+			checkFields(obj[k], f.Type, join(path, k), warnings)
+		}
+	}
+}
+
+// fieldIgnorer is implemented by types whose JSON carries fields Zeppelin
+// knowingly does not use, so they are not reported as unknown.
+type fieldIgnorer interface {
+	ignoredFields() []string
+}
+
+func addWarning(warnings *[]string, w string) {
+	if !slices.Contains(*warnings, w) {
+		*warnings = append(*warnings, w)
+	}
+}
+
+func join(path, field string) string {
+	if path == "" {
+		return field
+	}
+	return path + "." + field
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// agentDetails is an agent's free-form details. gt reports values of any
+// JSON type; they are kept as strings, with objects and lists as JSON.
+type agentDetails map[string]string
+
+func (d *agentDetails) UnmarshalJSON(data []byte) error {
+	var m map[string]any
+	if err := json.Unmarshal(data, &m); err != nil {
+		return err
+	}
+	*d = make(agentDetails, len(m))
+	for k, v := range m {
+		if s := jsonString(v); s != "" {
+			(*d)[k] = s
+		}
+	}
+	return nil
+}
+
+// jsonString renders a decoded JSON value as metadata: strings as they are,
+// numbers and booleans in JSON notation, null as "" and anything else as
+// JSON.
+func jsonString(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		b, _ := json.Marshal(v)
+		return string(b)
+	}
+}
@@ -0,0 +1,78 @@
+package poller
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"testing"
+)
+
+func TestDecodeJSONTolerant(t *testing.T) {
+	rec := &cmdRecorder{}
+	ctx := withRecorder(context.Background(), rec)
+	out := `{
+		"schema_version": 2,
+		"mayor": {"name": "mayor", "status": "running", "unread_mail": "3", "session": "gt-mayor"},
+		"rigs": [
+			{"name": "gastown", "polecats": [
+				{"name": "toast", "state": "working", "details": {"hooked_bead": "gt-12", "attempt": 2, "tmux": {"pane": 3}, "gone": null}},
+				{"name": "nux", "state": "idle"}
+			]},
+			{"witness": {"name": "witness"}}
+		]
+	}`
+
+	var st gtStatusOutput
+	if err := decodeJSON(ctx, "gt status", out, &st); err != nil {
+		t.Fatalf("decodeJSON: %v", err)
+	}
+
+	if st.Mayor == nil || agentState(*st.Mayor) != "running" {
+		t.Errorf("expected mayor running from its status field, got %+v", st.Mayor)
+	}
+	if len(st.Rigs) != 2 || len(st.Rigs[0].Polecats) != 2 {
+		t.Fatalf("expected the rest of the output decoded, got %+v", st.Rigs)
+	}
+	want := map[string]string{"hooked_bead": "gt-12", "attempt": "2", "tmux": `{"pane":3}`}
+	if got := st.Rigs[0].Polecats[0].Details; !maps.Equal(got, want) {
+		t.Errorf("expected details %v, got %v", want, got)
+	}
+
+	for _, w := range []string{
+		"gt status: schema version 2 is newer than supported version 1",
+		"gt status: field mayor.unread_mail: expected int, got JSON string",
+		"gt status: unknown field mayor.session",
+		"gt status: missing field rigs[].name",
+	} {
+		if !slices.Contains(rec.warnings, w) {
+			t.Errorf("expected warning %q, got %q", w, rec.warnings)
+		}
+	}
+	if len(rec.warnings) != 4 {
+		t.Errorf("expected 4 warnings, got %q", rec.warnings)
+	}
+}
+
+func TestDecodeJSONUnwrapsList(t *testing.T) {
+	rec := &cmdRecorder{}
+	ctx := withRecorder(context.Background(), rec)
+
+	var beads []beadInfo
+	out := `{"version": "1", "issues": [{"id": "gt-1", "status": "open", "created_at": "2026-01-02T03:04:05Z"}]}`
+	if err := decodeJSON(ctx, "bd list", out, &beads); err != nil {
+		t.Fatalf("decodeJSON: %v", err)
+	}
+	if len(beads) != 1 || beads[0].ID != "gt-1" {
+		t.Errorf("expected one bead, got %+v", beads)
+	}
+	if len(rec.warnings) != 0 {
+		t.Errorf("expected no warnings, got %q", rec.warnings)
+	}
+
+	if err := decodeJSON(ctx, "bd list", `{"open": [], "closed": []}`, &beads); err == nil {
+		t.Error("expected an error for an object holding several lists")
+	}
+	if err := decodeJSON(ctx, "bd list", `not json`, &beads); err == nil {
+		t.Error("expected an error for output that is not JSON")
+	}
+}
@@ -0,0 +1,38 @@
+package poller
+
+import (
+	"context"
+	"time"
+
+	"github.com/gronitab/zeppelin/internal/state"
+)
+
+// Source is a single feed of topology data, collected on its own schedule.
+// The fragment it returns replaces the one it reported previously; the store
+// merges the fragments of all sources into the snapshot.
+type Source interface {
+	// Name identifies the source's fragment in the store.
+	Name() string
+	// Interval is how often the source is collected, unless a change under
+	// the root triggers it sooner.
+	Interval() time.Duration
+	// Collect runs the source's commands and returns the nodes and edges it
+	// owns. An error means the source could not see the town at all; its
+	// previous fragment is then kept and marked stale.
+	Collect(ctx context.Context) (state.Fragment, error)
+}
+
+// NewSource builds a Source from a collect function.
+func NewSource(name string, interval time.Duration, collect func(ctx context.Context) (state.Fragment, error)) Source {
+	return &funcSource{name: name, interval: interval, collect: collect}
+}
+
+type funcSource struct {
+	name     string
+	interval time.Duration
+	collect  func(ctx context.Context) (state.Fragment, error)
+}
+
+func (s *funcSource) Name() string                                        { return s.name }
+func (s *funcSource) Interval() time.Duration                             { return s.interval }
+func (s *funcSource) Collect(ctx context.Context) (state.Fragment, error) { return s.collect(ctx) }
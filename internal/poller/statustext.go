@@ -0,0 +1,215 @@
+package poller
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// knownStates are the agent states the text parser recognizes as words.
+var knownStates = map[string]bool{
+	"running": true, "stopped": true, "working": true, "idle": true,
+	"stuck": true, "stalled": true, "done": true, "spawning": true,
+	"nuked": true, "dead": true, "paused": true,
+}
+
+// stateSymbols are the status glyphs gt prints beside agents.
+var stateSymbols = map[rune]string{
+	'●': "running",
+	'◉': "running",
+	'○': "stopped",
+	'◌': "stopped",
+}
+
+var (
+	beadIDRe   = regexp.MustCompile(`^[a-z][a-z0-9]*-[a-z0-9][a-z0-9.]*$`)
+	sectionRe  = regexp.MustCompile(`^(polecats|crew)(\(\d+\))?:?\d*$`)
+	rigTitleRe = regexp.MustCompile(`^rig:?$`)
+)
+
+// statusTitles are headings in the status output that are neither rigs nor
+// agents.
+var statusTitles = map[string]bool{
+	"town": true, "rigs": true, "agents": true, "status": true, "summary": true, "none": true,
+}
+
+// parseStatusText recovers the town tree from the human-readable output of
+// `gt status`, for gt builds without --json. It expects town agents and the
+// overseer at the top, then one block per rig: a header naming the rig,
+// the witness and refinery, and indented Polecats: and Crew: sections.
+// Decorations such as emoji and box drawing are ignored. Agents whose state
+// cannot be read are reported as "unknown" rather than assumed healthy.
+func parseStatusText(text string) gtStatusOutput {
+	var st gtStatusOutput
+	rig := -1 // index of the current rig in st.Rigs
+	section, sectionIndent := "", 0
+
+	for _, raw := range strings.Split(text, "\n") {
+		indent := len(raw) - len(strings.TrimLeft(raw, " \t"))
+		fields, symbolState := statusFields(raw)
+		if len(fields) == 0 {
+			continue
+		}
+		head := strings.ToLower(strings.TrimSuffix(fields[0], ":"))
+
+		// A section ends at the first line indented no deeper than its header.
+		if section != "" && indent <= sectionIndent {
+			section = ""
+		}
+
+		if head == "overseer" {
+			st.Overseer = parseOverseer(fields[1:])
+			continue
+		}
+		if name := rigHeader(raw, fields, indent, head); name != "" {
+			st.Rigs = append(st.Rigs, rigInfo{Name: name})
+			rig, section = len(st.Rigs)-1, ""
+			continue
+		}
+		if rig >= 0 && sectionRe.MatchString(strings.ToLower(strings.Join(fields, ""))) {
+			// "Polecats:", "Crew (2):"
+			section = "polecats"
+			if strings.HasPrefix(head, "crew") {
+				section = "crew"
+			}
+			sectionIndent = indent
+			continue
+		}
+
+		name := agentName(fields[0])
+		if name == "" {
+			continue
+		}
+		agent := parseAgentFields(name, fields[1:], symbolState)
+
+		switch {
+		case section == "polecats":
+			st.Rigs[rig].Polecats = append(st.Rigs[rig].Polecats, agent)
+		case section == "crew":
+			st.Rigs[rig].Crew = append(st.Rigs[rig].Crew, agent)
+		case rig >= 0 && name == "witness":
+			st.Rigs[rig].Witness = agent
+		case rig >= 0 && name == "refinery":
+			st.Rigs[rig].Refinery = agent
+		case name == "mayor":
+			st.Mayor = &agent
+		case name == "deacon":
+			st.Deacon = &agent
+		}
+	}
+	return st
+}
+
+// statusFields splits a status line into words, dropping decorations. It
+// also returns the state implied by a status glyph, if the line has one.
+func statusFields(line string) ([]string, string) {
+	symbolState := ""
+	cleaned := strings.Map(func(r rune) rune {
+		if s, ok := stateSymbols[r]; ok {
+			if symbolState == "" {
+				symbolState = s
+			}
+			return ' '
+		}
+		switch {
+		case unicode.IsLetter(r), unicode.IsDigit(r), strings.ContainsRune("-_./:@<>()#", r):
+			return r
+		default:
+			return ' '
+		}
+	}, line)
+	return strings.Fields(cleaned), symbolState
+}
+
+// rigHeader returns the rig a line names, or "" if it is not a rig header.
+// Headers are "Rig: gastown", a rule such as "── gastown ──", or an
+// unindented lone word such as "gastown:" or "gastown/" that is not a town
+// agent.
+func rigHeader(raw string, fields []string, indent int, head string) string {
+	if len(fields) == 2 && rigTitleRe.MatchString(strings.ToLower(fields[0])) {
+		return strings.Trim(fields[1], ":/")
+	}
+	if len(fields) != 1 || head == "mayor" || head == "deacon" || statusTitles[strings.Trim(head, "/")] || sectionRe.MatchString(head) {
+		return ""
+	}
+	name := strings.Trim(fields[0], ":/")
+	if strings.Contains(raw, "──") || strings.Contains(raw, "==") || strings.Contains(raw, "--") {
+		return name
+	}
+	if indent == 0 && (strings.HasSuffix(fields[0], ":") || strings.HasSuffix(fields[0], "/")) {
+		return name
+	}
+	return ""
+}
+
+// agentName normalizes the first word of an agent line, dropping a trailing
+// colon and any rig or role prefix such as "gastown/toast".
+func agentName(w string) string {
+	w = strings.Trim(w, ":()")
+	if i := strings.LastIndex(w, "/"); i >= 0 {
+		w = w[i+1:]
+	}
+	w = strings.ToLower(w)
+	if w == "" || statusTitles[w] || strings.ContainsAny(w, "<>@") {
+		return ""
+	}
+	return w
+}
+
+// parseAgentFields reads an agent's state, unread mail count and hooked bead
+// from the words following its name.
+func parseAgentFields(name string, rest []string, symbolState string) agentInfo {
+	a := agentInfo{Name: name}
+	for i := 0; i < len(rest); i++ {
+		w := strings.ToLower(strings.Trim(rest[i], "():,"))
+		switch {
+		case knownStates[w]:
+			if a.State == "" {
+				a.State = w
+			}
+		case (w == "mail" || w == "unread") && i+1 < len(rest):
+			if n, err := strconv.Atoi(strings.Trim(rest[i+1], "():,")); err == nil {
+				a.UnreadMail = &n
+				i++
+			}
+		case (w == "hook" || w == "hooked") && i+1 < len(rest):
+			setHook(&a, strings.Trim(rest[i+1], "():,"))
+			i++
+		case beadIDRe.MatchString(w):
+			setHook(&a, w)
+		default:
+			// "3 unread"
+			if n, err := strconv.Atoi(w); err == nil && i+1 < len(rest) && strings.ToLower(rest[i+1]) == "unread" {
+				a.UnreadMail = &n
+				i++
+			}
+		}
+	}
+	if a.State == "" {
+		a.State = orDefault(symbolState, "unknown")
+	}
+	return a
+}
+
+func setHook(a *agentInfo, bead string) {
+	if bead == "" || a.Details["hooked_bead"] != "" {
+		return
+	}
+	a.Details = map[string]string{"hooked_bead": bead}
+}
+
+// parseOverseer reads "Name <email>" from the words after "Overseer:".
+func parseOverseer(rest []string) *overseerInfo {
+	o := &overseerInfo{}
+	var name []string
+	for _, w := range rest {
+		if strings.HasPrefix(w, "<") || strings.Contains(w, "@") {
+			o.Email = strings.Trim(w, "<>")
+			continue
+		}
+		name = append(name, w)
+	}
+	o.Name = strings.Join(name, " ")
+	return o
+}
@@ -0,0 +1,119 @@
+package poller
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+const sampleStatusText = `Town: gt
+
+👤 Overseer: Ada Lovelace <ada@example.com>
+
+🎩 mayor        ● running   📬 2 unread
+🐺 deacon       ○ stopped
+
+─── gastown ──────────────────────────
+  🦉 witness    ● running
+  🏭 refinery   ○ stopped
+  Polecats (2):
+    toast       ● working   hook: gt-12
+    nux         ○ idle
+  Crew (1):
+    max         ●
+  witness       dead
+
+Rig: beads
+  refinery      running
+  Polecats: none
+`
+
+func TestParseStatusText(t *testing.T) {
+	st := parseStatusText(sampleStatusText)
+
+	if st.Overseer == nil || st.Overseer.Name != "Ada Lovelace" || st.Overseer.Email != "ada@example.com" {
+		t.Errorf("overseer = %+v", st.Overseer)
+	}
+	if st.Mayor == nil || st.Mayor.State != "running" || st.Mayor.UnreadMail == nil || *st.Mayor.UnreadMail != 2 {
+		t.Errorf("mayor = %+v", st.Mayor)
+	}
+	if st.Deacon == nil || st.Deacon.State != "stopped" {
+		t.Errorf("deacon = %+v", st.Deacon)
+	}
+
+	if len(st.Rigs) != 2 {
+		t.Fatalf("got %d rigs, want 2: %+v", len(st.Rigs), st.Rigs)
+	}
+	gt := st.Rigs[0]
+	if gt.Name != "gastown" {
+		t.Errorf("rig name = %q, want gastown", gt.Name)
+	}
+	// The later witness line, outside the crew section, wins.
+	if gt.Witness.State != "dead" || gt.Refinery.State != "stopped" {
+		t.Errorf("witness = %q, refinery = %q", gt.Witness.State, gt.Refinery.State)
+	}
+	if len(gt.Polecats) != 2 || gt.Polecats[0].Name != "toast" || gt.Polecats[0].State != "working" ||
+		gt.Polecats[0].Details["hooked_bead"] != "gt-12" || gt.Polecats[1].State != "idle" {
+		t.Errorf("polecats = %+v", gt.Polecats)
+	}
+	if len(gt.Crew) != 1 || gt.Crew[0].Name != "max" || gt.Crew[0].State != "running" {
+		t.Errorf("crew = %+v", gt.Crew)
+	}
+
+	beads := st.Rigs[1]
+	if beads.Name != "beads" || beads.Witness.Name != "" || beads.Refinery.State != "running" || len(beads.Polecats) != 0 {
+		t.Errorf("beads rig = %+v", beads)
+	}
+}
+
+func TestParseStatusTextUnknownState(t *testing.T) {
+	st := parseStatusText("gastown/\n  witness\n  refinery\n")
+	if len(st.Rigs) != 1 {
+		t.Fatalf("rigs = %+v", st.Rigs)
+	}
+	if s := st.Rigs[0].Witness.State; s != "unknown" {
+		t.Errorf("witness state = %q, want unknown rather than assumed running", s)
+	}
+}
+
+func TestTopologyFromStatusText(t *testing.T) {
+	p := replayPoller("text")
+	f, err := p.collectTopology(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, "text", "topology.golden.json", f)
+}
+
+func FuzzParseStatusText(f *testing.F) {
+	f.Add(sampleStatusText)
+	f.Add("Rig: gastown\n  Polecats:\n    a working\n")
+	f.Add("── x ──\n  crew (3):\n    y ○\n  witness ● mail 4\n")
+	f.Add("Polecats:\n  orphan running\n")
+	f.Add("")
+
+	f.Fuzz(func(t *testing.T, text string) {
+		st := parseStatusText(text)
+		for _, rig := range st.Rigs {
+			if rig.Name == "" {
+				t.Fatalf("rig with empty name from %q", text)
+			}
+			for _, a := range append(rig.Polecats, rig.Crew...) {
+				if a.Name == "" || strings.ContainsAny(a.Name, " \t\n/") {
+					t.Fatalf("bad agent name %q from %q", a.Name, text)
+				}
+				if a.State == "" {
+					t.Fatalf("agent %q without state from %q", a.Name, text)
+				}
+			}
+		}
+		// Whatever was parsed must build into valid nodes.
+		p := &Poller{}
+		nodes, _ := p.buildFromStatus(st)
+		for _, n := range nodes {
+			if n.ID == "" || n.State == "" {
+				t.Fatalf("invalid node %+v from %q", n, text)
+			}
+		}
+	})
+}
@@ -0,0 +1,217 @@
+package poller
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// fileBacked lists the sources whose data lives entirely in files under the
+// root. While the root is watched they poll only every SafetyInterval; the
+// others keep their interval, since an agent session can die without
+// touching the filesystem.
+var fileBacked = map[string]bool{
+	"beads":      true,
+	"convoys":    true,
+	"molecules":  true,
+	"mail":       true,
+	"mergequeue": true,
+}
+
+const (
+	// watchDebounce is how long the watcher waits for a burst of changes to
+	// settle before triggering a repoll.
+	watchDebounce = 250 * time.Millisecond
+	// watchRescan is how often the set of watched directories is refreshed
+	// as rigs and polecats come and go.
+	watchRescan = 5 * time.Second
+)
+
+// watchEvent reports a change to a watched directory or one of its entries.
+// An empty dir means changes may have been lost and every directory should
+// be treated as changed.
+type watchEvent struct {
+	dir  string
+	name string // entry within dir, or "" for dir itself
+}
+
+// fsWatcher reports changes to the entries of a set of directories, without
+// recursing into subdirectories.
+type fsWatcher interface {
+	// add starts watching dir. Adding a watched directory again is a no-op.
+	add(dir string) error
+	remove(dir string)
+	events() <-chan watchEvent
+	close() error
+}
+
+func isScanWatcher(w fsWatcher) bool {
+	_, ok := w.(*scanWatcher)
+	return ok
+}
+
+// watchTarget is a directory whose changes wake sources.
+type watchTarget struct {
+	dir        string
+	sources    []string
+	skipHidden bool // ignore dotfiles such as .git, and .beads, which is watched separately
+}
+
+// watchTargets returns the directories to watch: the beads databases, which
+// hold beads, convoys, molecules, mail and merge requests; the town and rig directories,
+// whose config and polecats define the topology; and each polecat worktree,
+// where hooks land.
+func (p *Poller) watchTargets() []watchTarget {
+	beadSources := []string{"beads", "convoys", "molecules", "mail", "mergequeue"}
+	agentSources := []string{"topology", "polecats", "rigs"}
+
+	targets := []watchTarget{
+		{dir: p.root, sources: agentSources, skipHidden: true},
+		{dir: filepath.Join(p.root, "mayor"), sources: agentSources},
+	}
+	for _, db := range p.beadDBs() {
+		targets = append(targets, watchTarget{dir: filepath.Join(db.dir, ".beads"), sources: beadSources})
+	}
+	for _, rig := range p.rigNames() {
+		rigDir := filepath.Join(p.root, rig)
+		polecatsDir := filepath.Join(rigDir, "polecats")
+		targets = append(targets,
+			watchTarget{dir: rigDir, sources: agentSources, skipHidden: true},
+			watchTarget{dir: polecatsDir, sources: agentSources, skipHidden: true},
+		)
+		entries, _ := os.ReadDir(polecatsDir)
+		for _, e := range entries {
+			if e.IsDir() && !strings.HasPrefix(e.Name(), ".") {
+				targets = append(targets, watchTarget{
+					dir:        filepath.Join(polecatsDir, e.Name()),
+					sources:    []string{"polecats", "molecules"},
+					skipHidden: true,
+				})
+			}
+		}
+	}
+	return targets
+}
+
+// ignoreChange reports whether a change to the named entry is noise: lock,
+// socket, log and shared-memory files that bd touches without changing data.
+func (t watchTarget) ignoreChange(name string) bool {
+	if t.skipHidden && strings.HasPrefix(name, ".") {
+		return true
+	}
+	for _, suffix := range []string{".lock", ".sock", ".log", ".pid", "-shm"} {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// startWatcher returns a watcher for the root, or nil when watching is
+// disabled. Where the platform's watcher cannot start, the root is scanned
+// for changes instead.
+func (p *Poller) startWatcher() fsWatcher {
+	if p.opts.NoWatch {
+		return nil
+	}
+	w, err := newFSWatcher()
+	if err != nil {
+		log.Printf("poller: watching %s: %v; scanning for changes instead", p.root, err)
+		return newScanWatcher()
+	}
+	return w
+}
+
+// runWatcher triggers the sources interested in each change once the
+// changes settle, and keeps the watched directories in step with the town.
+// If the watcher fails to add a directory that exists, such as when the
+// inotify watch limit is reached, it switches to scanning for changes.
+func (p *Poller) runWatcher(ctx context.Context, w fsWatcher) {
+	defer func() { w.close() }()
+
+	watched := make(map[string]watchTarget)
+	failed := make(map[string]bool) // directories whose failure was logged
+	var refresh func()
+	refresh = func() {
+		want := make(map[string]watchTarget)
+		for _, t := range p.watchTargets() {
+			if prev, ok := want[t.dir]; ok {
+				t.sources = append(prev.sources, t.sources...)
+			}
+			want[t.dir] = t
+		}
+		for dir := range watched {
+			if _, ok := want[dir]; !ok {
+				w.remove(dir)
+				delete(watched, dir)
+			}
+		}
+		for dir, t := range want {
+			// Re-adding also restores directories that were removed and
+			// recreated since the last refresh.
+			err := w.add(dir)
+			switch {
+			case err == nil:
+				delete(failed, dir)
+				watched[dir] = t
+				continue
+			case errors.Is(err, fs.ErrNotExist), errors.Is(err, syscall.ENOTDIR):
+				// Not created yet; a later rescan picks it up.
+			case !isScanWatcher(w):
+				log.Printf("poller: %v; scanning for changes instead", err)
+				w.close()
+				w = newScanWatcher()
+				clear(watched)
+				refresh()
+				return
+			case !failed[dir]:
+				log.Printf("poller: watching %s: %v", dir, err)
+				failed[dir] = true
+			}
+			delete(watched, dir)
+		}
+	}
+	refresh()
+
+	rescan := time.NewTicker(watchRescan)
+	defer rescan.Stop()
+	settle := time.NewTimer(watchDebounce)
+	settle.Stop()
+	pending := make(map[string]bool)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-rescan.C:
+			refresh()
+		case ev, ok := <-w.events():
+			if !ok {
+				return
+			}
+			for dir, t := range watched {
+				if (ev.dir == "" || ev.dir == dir) && !t.ignoreChange(ev.name) {
+					for _, name := range t.sources {
+						pending[name] = true
+					}
+				}
+			}
+			if len(pending) > 0 {
+				settle.Reset(watchDebounce)
+			}
+		case <-settle.C:
+			for name := range pending {
+				p.Trigger(name)
+			}
+			clear(pending)
+			// New rigs or polecats may have appeared.
+			refresh()
+		}
+	}
+}
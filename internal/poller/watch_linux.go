@@ -0,0 +1,119 @@
+//go:build linux
+
+package poller
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"sync"
+	"syscall"
+)
+
+const inotifyMask = syscall.IN_CREATE | syscall.IN_DELETE | syscall.IN_MODIFY |
+	syscall.IN_CLOSE_WRITE | syscall.IN_MOVED_FROM | syscall.IN_MOVED_TO |
+	syscall.IN_ATTRIB | syscall.IN_DELETE_SELF | syscall.IN_MOVE_SELF | syscall.IN_ONLYDIR
+
+// inotifyWatcher watches directories with Linux inotify.
+type inotifyWatcher struct {
+	fd   int
+	file *os.File // fd wrapped for the runtime poller, so close unblocks reads
+	ch   chan watchEvent
+	done chan struct{}
+
+	mu   sync.Mutex
+	dirs map[int]string // watch descriptor → directory
+	wds  map[string]int
+}
+
+func newFSWatcher() (fsWatcher, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, os.NewSyscallError("inotify_init1", err)
+	}
+	w := &inotifyWatcher{
+		fd:   fd,
+		file: os.NewFile(uintptr(fd), "inotify"),
+		ch:   make(chan watchEvent),
+		done: make(chan struct{}),
+		dirs: make(map[int]string),
+		wds:  make(map[string]int),
+	}
+	go w.read()
+	return w, nil
+}
+
+func (w *inotifyWatcher) add(dir string) error {
+	wd, err := syscall.InotifyAddWatch(w.fd, dir, inotifyMask)
+	if err != nil {
+		return &os.PathError{Op: "inotify_add_watch", Path: dir, Err: err}
+	}
+	w.mu.Lock()
+	w.dirs[wd] = dir
+	w.wds[dir] = wd
+	w.mu.Unlock()
+	return nil
+}
+
+func (w *inotifyWatcher) remove(dir string) {
+	w.mu.Lock()
+	wd, ok := w.wds[dir]
+	delete(w.wds, dir)
+	delete(w.dirs, wd)
+	w.mu.Unlock()
+	if ok {
+		syscall.InotifyRmWatch(w.fd, uint32(wd))
+	}
+}
+
+func (w *inotifyWatcher) events() <-chan watchEvent { return w.ch }
+
+func (w *inotifyWatcher) close() error {
+	close(w.done)
+	return w.file.Close()
+}
+
+// read decodes inotify events until the watcher is closed.
+func (w *inotifyWatcher) read() {
+	defer close(w.ch)
+
+	buf := make([]byte, 64*1024)
+	for {
+		n, err := w.file.Read(buf)
+		if err != nil {
+			return
+		}
+		for off := 0; off+syscall.SizeofInotifyEvent <= n; {
+			wd := int(int32(binary.NativeEndian.Uint32(buf[off:])))
+			mask := binary.NativeEndian.Uint32(buf[off+4:])
+			nameLen := int(binary.NativeEndian.Uint32(buf[off+12:]))
+			off += syscall.SizeofInotifyEvent
+			name := string(bytes.TrimRight(buf[off:off+nameLen], "\x00"))
+			off += nameLen
+
+			w.mu.Lock()
+			dir, ok := w.dirs[wd]
+			if mask&syscall.IN_IGNORED != 0 {
+				// The directory was removed or unwatched.
+				delete(w.dirs, wd)
+				if w.wds[dir] == wd {
+					delete(w.wds, dir)
+				}
+			}
+			w.mu.Unlock()
+
+			ev := watchEvent{dir: dir, name: name}
+			switch {
+			case mask&syscall.IN_Q_OVERFLOW != 0:
+				ev = watchEvent{}
+			case !ok:
+				continue
+			}
+			select {
+			case w.ch <- ev:
+			case <-w.done:
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,7 @@
+//go:build !linux
+
+package poller
+
+func newFSWatcher() (fsWatcher, error) {
+	return newScanWatcher(), nil
+}
@@ -0,0 +1,133 @@
+package poller
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// scanInterval is how often the mtime scanner compares directories.
+const scanInterval = time.Second
+
+// scanWatcher detects changes by comparing the modification times and sizes
+// of each directory's entries. It works on every platform, and stands in
+// where inotify is unavailable or fails.
+type scanWatcher struct {
+	ch   chan watchEvent
+	done chan struct{}
+
+	mu   sync.Mutex
+	dirs map[string]map[string]entryStamp // directory → entry name → stamp
+}
+
+type entryStamp struct {
+	mtime time.Time
+	size  int64
+}
+
+func newScanWatcher() *scanWatcher {
+	w := &scanWatcher{
+		ch:   make(chan watchEvent),
+		done: make(chan struct{}),
+		dirs: make(map[string]map[string]entryStamp),
+	}
+	go w.scan()
+	return w
+}
+
+func (w *scanWatcher) add(dir string) error {
+	w.mu.Lock()
+	_, ok := w.dirs[dir]
+	w.mu.Unlock()
+	if ok {
+		return nil
+	}
+	stamps, err := stampDir(dir)
+	if err != nil {
+		return err
+	}
+	w.mu.Lock()
+	w.dirs[dir] = stamps
+	w.mu.Unlock()
+	return nil
+}
+
+func (w *scanWatcher) remove(dir string) {
+	w.mu.Lock()
+	delete(w.dirs, dir)
+	w.mu.Unlock()
+}
+
+func (w *scanWatcher) events() <-chan watchEvent { return w.ch }
+
+func (w *scanWatcher) close() error {
+	close(w.done)
+	return nil
+}
+
+// scan rescans every directory each scanInterval and reports the entries
+// that were created, removed or modified since the previous scan.
+func (w *scanWatcher) scan() {
+	defer close(w.ch)
+
+	ticker := time.NewTicker(scanInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+		}
+
+		var changes []watchEvent
+		w.mu.Lock()
+		for dir, prev := range w.dirs {
+			cur, err := stampDir(dir)
+			if err != nil {
+				// Removed: report it once and stop scanning it.
+				delete(w.dirs, dir)
+				changes = append(changes, watchEvent{dir: dir})
+				continue
+			}
+			for name, st := range cur {
+				if old, ok := prev[name]; !ok || old != st {
+					changes = append(changes, watchEvent{dir: dir, name: name})
+				}
+			}
+			for name := range prev {
+				if _, ok := cur[name]; !ok {
+					changes = append(changes, watchEvent{dir: dir, name: name})
+				}
+			}
+			w.dirs[dir] = cur
+		}
+		w.mu.Unlock()
+
+		for _, ev := range changes {
+			select {
+			case w.ch <- ev:
+			case <-w.done:
+				return
+			}
+		}
+	}
+}
+
+// stampDir records the modification time and size of a directory's entries.
+func stampDir(dir string) (map[string]entryStamp, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	stamps := make(map[string]entryStamp, len(entries))
+	for _, e := range entries {
+		fi, err := os.Lstat(filepath.Join(dir, e.Name()))
+		if err != nil {
+			continue
+		}
+		stamps[e.Name()] = entryStamp{mtime: fi.ModTime(), size: fi.Size()}
+	}
+	return stamps, nil
+}
@@ -0,0 +1,108 @@
+package poller
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/gronitab/zeppelin/internal/state"
+)
+
+func TestWatcherReportsEntryChanges(t *testing.T) {
+	watchers := map[string]func() (fsWatcher, error){
+		"native": newFSWatcher,
+		"scan":   func() (fsWatcher, error) { return newScanWatcher(), nil },
+	}
+	for name, newWatcher := range watchers {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			w, err := newWatcher()
+			if err != nil {
+				t.Skipf("no watcher: %v", err)
+			}
+			defer w.close()
+			if err := w.add(dir); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := os.WriteFile(filepath.Join(dir, "issues.jsonl"), []byte("{}\n"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			waitForEvent(t, w, dir, "issues.jsonl")
+		})
+	}
+}
+
+// failingWatcher fails to add any directory, like inotify past its watch limit.
+type failingWatcher struct{ ch chan watchEvent }
+
+func (w failingWatcher) add(dir string) error {
+	return &os.PathError{Op: "inotify_add_watch", Path: dir, Err: syscall.ENOSPC}
+}
+func (w failingWatcher) remove(string)             {}
+func (w failingWatcher) events() <-chan watchEvent { return w.ch }
+func (w failingWatcher) close() error              { return nil }
+
+func TestWatcherFallsBackToScanning(t *testing.T) {
+	p := New(state.NewStore(), t.TempDir(), Options{}, func(*state.Diff) {})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		p.runWatcher(ctx, failingWatcher{make(chan watchEvent)})
+		close(done)
+	}()
+	defer func() { cancel(); <-done }()
+
+	// Let the first refresh switch to the scanner before changing anything.
+	time.Sleep(100 * time.Millisecond)
+	if err := os.Mkdir(filepath.Join(p.root, "gastown"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-p.wake["topology"]:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the scanner to report the new rig")
+	}
+}
+
+func waitForEvent(t *testing.T, w fsWatcher, dir, name string) {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case ev := <-w.events():
+			if ev.dir == dir && ev.name == name {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("no event for %s", name)
+		}
+	}
+}
+
+func TestWatchTargetIgnoresNoise(t *testing.T) {
+	root := watchTarget{dir: "/town", skipHidden: true}
+	beads := watchTarget{dir: "/town/.beads"}
+
+	cases := []struct {
+		t      watchTarget
+		name   string
+		ignore bool
+	}{
+		{root, "gastown", false},
+		{root, ".events.jsonl", true},
+		{beads, "beads.db", false},
+		{beads, "beads.db-wal", false},
+		{beads, "beads.db-shm", true},
+		{beads, "daemon.lock", true},
+		{beads, "", false},
+	}
+	for _, c := range cases {
+		if got := c.t.ignoreChange(c.name); got != c.ignore {
+			t.Errorf("%s: ignoreChange(%q) = %v, want %v", c.t.dir, c.name, got, c.ignore)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package server
+
+import (
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gronitab/zeppelin/internal/sse"
+	"github.com/gronitab/zeppelin/internal/state"
+)
+
+// maxReplay is the most diffs replayed to a resuming client. Further behind,
+// a snapshot is smaller.
+const maxReplay = 1000
+
+// townVersion is a town store's epoch and version, written "<epoch>-<version>"
+// as the SSE id of a town's events.
+type townVersion struct {
+	epoch   string
+	version uint64
+}
+
+func (v townVersion) String() string {
+	return v.epoch + "-" + strconv.FormatUint(v.version, 10)
+}
+
+func parseTownVersion(id string) (townVersion, bool) {
+	epoch, n, ok := strings.Cut(id, "-")
+	if !ok || epoch == "" {
+		return townVersion{}, false
+	}
+	version, err := strconv.ParseUint(n, 10, 64)
+	if err != nil {
+		return townVersion{}, false
+	}
+	return townVersion{epoch, version}, true
+}
+
+// covers reports whether a client at v has the event at o.
+func (v townVersion) covers(o townVersion) bool {
+	return v.epoch == o.epoch && o.version <= v.version
+}
+
+// townVersions holds every town's version, written "town:<epoch>-<version>"
+// separated by spaces, which town names cannot contain, as the SSE id of the
+// all-towns stream's events.
+type townVersions map[string]townVersion
+
+func (vs townVersions) String() string {
+	parts := make([]string, 0, len(vs))
+	for _, name := range slices.Sorted(maps.Keys(vs)) {
+		parts = append(parts, name+state.TownSeparator+vs[name].String())
+	}
+	return strings.Join(parts, " ")
+}
+
+func parseTownVersions(id string) (townVersions, bool) {
+	vs := make(townVersions)
+	for part := range strings.FieldsSeq(id) {
+		name, vid, ok := strings.Cut(part, state.TownSeparator)
+		v, vok := parseTownVersion(vid)
+		if !ok || !vok {
+			return nil, false
+		}
+		vs[name] = v
+	}
+	return vs, len(vs) > 0
+}
+
+// covers reports whether a client at vs has every event up to other.
+func (vs townVersions) covers(other townVersions) bool {
+	for name, o := range other {
+		if v, ok := vs[name]; !ok || !v.covers(o) {
+			return false
+		}
+	}
+	return true
+}
+
+func serveEvents(w http.ResponseWriter, r *http.Request, t *Town) {
+	t.Broker.ServeHTTPResumable(w, r, func(lastID string) ([]sse.Event, func(string) bool) {
+		// Replay what a resuming client missed, if the store still has it;
+		// otherwise send the full snapshot.
+		var first []sse.Event
+		at, ok := parseTownVersion(lastID)
+		var diffs []*state.Diff
+		if ok {
+			var err error
+			diffs, err = t.Store.DiffsSince(at.epoch, at.version, maxReplay)
+			ok = err == nil
+		}
+		if ok {
+			for _, d := range diffs {
+				at.version = d.Version
+				first = append(first, sse.Event{ID: at.String(), Data: d})
+			}
+		} else {
+			snap := t.Store.GetSnapshot()
+			at = townVersion{snap.Epoch, snap.Version}
+			first = []sse.Event{{ID: at.String(), Data: snap}}
+		}
+		return first, func(id string) bool {
+			v, ok := parseTownVersion(id)
+			return ok && at.covers(v)
+		}
+	})
+}
+
+// serveAllEvents serves the all-towns stream, resuming each town where the
+// client left off.
+func (s *Server) serveAllEvents(w http.ResponseWriter, r *http.Request) {
+	s.all.ServeHTTPResumable(w, r, func(lastID string) ([]sse.Event, func(string) bool) {
+		first, at, ok := s.replayAll(lastID)
+		if !ok {
+			var snap state.Snapshot
+			snap, at = s.combinedSnapshotVersions()
+			first = []sse.Event{{ID: at.String(), Data: snap}}
+		}
+		return first, func(id string) bool {
+			vs, ok := parseTownVersions(id)
+			return ok && at.covers(vs)
+		}
+	})
+}
+
+// replayAll returns every town's diffs since the versions in lastID, in the
+// order they were made, with the versions they bring the client to. It
+// reports false if any town cannot be replayed.
+func (s *Server) replayAll(lastID string) ([]sse.Event, townVersions, bool) {
+	at, ok := parseTownVersions(lastID)
+	if !ok {
+		return nil, nil, false
+	}
+	type townDiff struct {
+		town string
+		diff *state.Diff
+	}
+	var missed []townDiff
+	for _, t := range s.towns {
+		v, ok := at[t.Name]
+		if !ok {
+			return nil, nil, false
+		}
+		diffs, err := t.Store.DiffsSince(v.epoch, v.version, maxReplay)
+		if err != nil {
+			return nil, nil, false
+		}
+		for _, d := range diffs {
+			missed = append(missed, townDiff{t.Name, d.InTown(t.Name)})
+		}
+	}
+	if len(missed) > maxReplay {
+		return nil, nil, false
+	}
+	slices.SortStableFunc(missed, func(a, b townDiff) int {
+		return a.diff.Timestamp.Compare(b.diff.Timestamp)
+	})
+
+	// Town summaries mean nothing to the combined view; the last diff
+	// carries the combined one.
+	summary := false
+	first := make([]sse.Event, 0, len(missed))
+	for _, m := range missed {
+		summary = summary || m.diff.Summary != nil
+		m.diff.Summary = nil
+		at[m.town] = townVersion{at[m.town].epoch, m.diff.Version}
+		first = append(first, sse.Event{ID: at.String(), Data: m.diff})
+	}
+	if summary {
+		sum := s.combinedSnapshot().Summary
+		first[len(first)-1].Data.(*state.Diff).Summary = &sum
+	}
+	return first, at, true
+}
@@ -1,27 +1,73 @@
 package server
 
 import (
+	"crypto/subtle"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io/fs"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/gronitab/zeppelin/internal/sse"
 	"github.com/gronitab/zeppelin/internal/state"
 )
 
+// Town is one Gas Town root with its own store and SSE broker.
+type Town struct {
+	Name   string
+	Root   string
+	Store  *state.Store
+	Broker *sse.Broker
+}
+
+// townInfo describes a town in the /api/towns listing.
+type townInfo struct {
+	Name    string        `json:"name"`
+	Root    string        `json:"root"`
+	Summary state.Summary `json:"summary"`
+}
+
 // Server is the Zeppelin HTTP server.
 type Server struct {
-	store  *state.Store
-	broker *sse.Broker
+	towns  []*Town
+	byName map[string]*Town
+	all    *sse.Broker // clients of the all-towns stream
 	mux    *http.ServeMux
+
+	mu   sync.Mutex
+	sent townVersions // latest version of each town sent to the all-towns stream
+
+	// Token, if set before serving, is required on every API request, as
+	// an "Authorization: Bearer" header or, for EventSource clients that
+	// cannot set headers, a token query parameter.
+	Token string
+	// ActivityLimit caps the activity in the all-towns snapshot.
+	ActivityLimit int
 }
 
-// New creates a Zeppelin HTTP server.
-func New(store *state.Store, broker *sse.Broker, frontendFS fs.FS) *Server {
+// New creates a Zeppelin HTTP server for the given towns. With a single town
+// the top-level endpoints serve it unchanged; with several they serve every
+// town at once, with node IDs namespaced by town name, and all is the broker
+// of that combined stream.
+func New(towns []*Town, all *sse.Broker, frontendFS fs.FS) *Server {
 	s := &Server{
-		store:  store,
-		broker: broker,
+		towns:  towns,
+		byName: make(map[string]*Town, len(towns)),
+		all:    all,
 		mux:    http.NewServeMux(),
+		sent:   make(townVersions),
+
+		ActivityLimit: state.DefaultActivityLimit,
+	}
+	for _, t := range towns {
+		s.byName[t.Name] = t
+		snap := t.Store.GetSnapshot()
+		s.sent[t.Name] = townVersion{snap.Epoch, snap.Version}
 	}
 	s.routes(frontendFS)
 	return s
@@ -30,20 +76,95 @@ func New(store *state.Store, broker *sse.Broker, frontendFS fs.FS) *Server {
 func (s *Server) routes(frontendFS fs.FS) {
 	// SSE events endpoint.
 	s.mux.HandleFunc("/api/events", func(w http.ResponseWriter, r *http.Request) {
-		// Send full snapshot to the connecting client, then stream updates.
-		snap := s.store.GetSnapshot()
-		s.broker.ServeHTTPWithInitial(w, r, snap)
+		if t := s.single(); t != nil {
+			serveEvents(w, r, t)
+			return
+		}
+		s.serveAllEvents(w, r)
 	})
 
-	// API snapshot endpoint (for one-time fetch).
+	// API snapshot endpoint (for one-time fetch). With ?at=<time>, the town
+	// is rebuilt as it was then.
 	s.mux.HandleFunc("/api/snapshot", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		snap := s.store.GetSnapshot()
-		data, _ := json.Marshal(snap)
-		w.Write(data)
+		if t := s.single(); t != nil {
+			serveSnapshot(w, r, t)
+			return
+		}
+		at, ok := timeParam(w, r, "at")
+		if !ok {
+			return
+		}
+		if at.IsZero() {
+			writeJSON(w, s.combinedSnapshot())
+			return
+		}
+		snaps := make([]state.Snapshot, 0, len(s.towns))
+		for _, t := range s.towns {
+			snap, err := t.Store.SnapshotAt(at)
+			if err != nil {
+				writeHistoryError(w, err)
+				return
+			}
+			snaps = append(snaps, snap.InTown(t.Name))
+		}
+		snap := state.CombineSnapshots(snaps, s.ActivityLimit)
+		snap.Timestamp = at
+		writeJSON(w, snap)
+	})
+
+	// The state at ?from= and the diffs up to ?to=, for scrubbing through
+	// recent history.
+	s.mux.HandleFunc("/api/history", func(w http.ResponseWriter, r *http.Request) {
+		if t := s.single(); t != nil {
+			serveHistory(w, r, t)
+			return
+		}
+		from, to, ok := historyRange(w, r)
+		if !ok {
+			return
+		}
+		out := state.History{Type: "history", From: from, To: to, Diffs: []*state.Diff{}}
+		snaps := make([]state.Snapshot, 0, len(s.towns))
+		for _, t := range s.towns {
+			h, err := t.Store.History(from, to)
+			if err != nil {
+				writeHistoryError(w, err)
+				return
+			}
+			snaps = append(snaps, h.Snapshot.InTown(t.Name))
+			for _, d := range h.Diffs {
+				out.Diffs = append(out.Diffs, d.InTown(t.Name))
+			}
+		}
+		out.Snapshot = state.CombineSnapshots(snaps, s.ActivityLimit)
+		out.Snapshot.Timestamp = from
+		slices.SortStableFunc(out.Diffs, func(a, b *state.Diff) int {
+			return a.Timestamp.Compare(b.Timestamp)
+		})
+		writeJSON(w, out)
 	})
 
+	// Per-source health: last success, last error and command details.
+	s.mux.HandleFunc("/api/sources", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, s.health())
+	})
+
+	s.mux.HandleFunc("/api/towns", func(w http.ResponseWriter, r *http.Request) {
+		infos := make([]townInfo, 0, len(s.towns))
+		for _, t := range s.towns {
+			infos = append(infos, townInfo{Name: t.Name, Root: t.Root, Summary: t.Store.GetSnapshot().Summary})
+		}
+		writeJSON(w, infos)
+	})
+
+	// The same endpoints for a single town, with town-local IDs.
+	s.mux.HandleFunc("/api/towns/{town}/events", s.withTown(serveEvents))
+	s.mux.HandleFunc("/api/towns/{town}/snapshot", s.withTown(serveSnapshot))
+	s.mux.HandleFunc("/api/towns/{town}/history", s.withTown(serveHistory))
+	s.mux.HandleFunc("/api/towns/{town}/sources", s.withTown(func(w http.ResponseWriter, r *http.Request, t *Town) {
+		writeJSON(w, t.Store.HealthEvent())
+	}))
+
 	// Serve frontend static files.
 	fileServer := http.FileServer(http.FS(frontendFS))
 	s.mux.Handle("/", fileServer)
@@ -51,5 +172,221 @@ func (s *Server) routes(frontendFS fs.FS) {
 
 // ServeHTTP implements http.Handler.
 func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if strings.HasPrefix(r.URL.Path, "/api/") && !s.authorized(r) {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="zeppelin"`)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
 	s.mux.ServeHTTP(w, r)
 }
+
+// authorized reports whether the request carries the API token, if one is
+// required.
+func (s *Server) authorized(r *http.Request) bool {
+	if s.Token == "" {
+		return true
+	}
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		token = r.URL.Query().Get("token")
+	}
+	return subtle.ConstantTimeCompare([]byte(token), []byte(s.Token)) == 1
+}
+
+// Publish sends a diff or health event from a town to that town's clients
+// and, when several towns are served, to clients of the all-towns stream.
+// Diffs carry their version as the event id, so clients can resume.
+func (s *Server) Publish(t *Town, v any) {
+	d, isDiff := v.(*state.Diff)
+	var at townVersion
+	if isDiff {
+		at = townVersion{t.Store.Epoch(), d.Version}
+	}
+	if t.Broker.ClientCount() > 0 {
+		if isDiff {
+			t.Broker.BroadcastEvent(sse.Event{ID: at.String(), Data: d})
+		} else {
+			t.Broker.Broadcast(v)
+		}
+	}
+	if s.single() != nil {
+		return
+	}
+	if isDiff {
+		s.publishAll(t, d, at)
+		return
+	}
+	if s.all.ClientCount() == 0 {
+		return
+	}
+	switch v := v.(type) {
+	case state.HealthEvent:
+		// Clients replace their source list, so send every town's.
+		s.all.Broadcast(s.health())
+	default:
+		s.all.Broadcast(v)
+	}
+}
+
+// publishAll sends a town's diff to the all-towns stream. The event id holds
+// the latest version sent of every town; it is kept up to date even without
+// clients, since clients connecting later resume from it.
+func (s *Server) publishAll(t *Town, d *state.Diff, at townVersion) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// Diffs from concurrent polls can arrive out of order; clients have
+	// both once they receive the later one.
+	if prev, ok := s.sent[t.Name]; !ok || !prev.covers(at) {
+		s.sent[t.Name] = at
+	}
+	if s.all.ClientCount() == 0 {
+		return
+	}
+	d = d.InTown(t.Name)
+	if d.Summary != nil {
+		sum := s.combinedSnapshot().Summary
+		d.Summary = &sum
+	}
+	s.all.BroadcastEvent(sse.Event{ID: s.sent.String(), Data: d})
+}
+
+// single returns the only town, or nil when several are served.
+func (s *Server) single() *Town {
+	if len(s.towns) == 1 {
+		return s.towns[0]
+	}
+	return nil
+}
+
+func (s *Server) combinedSnapshot() state.Snapshot {
+	snap, _ := s.combinedSnapshotVersions()
+	return snap
+}
+
+// combinedSnapshotVersions returns the all-towns snapshot and the version of
+// each town it includes.
+func (s *Server) combinedSnapshotVersions() (state.Snapshot, townVersions) {
+	snaps := make([]state.Snapshot, 0, len(s.towns))
+	at := make(townVersions, len(s.towns))
+	for _, t := range s.towns {
+		snap := t.Store.GetSnapshot()
+		at[t.Name] = townVersion{snap.Epoch, snap.Version}
+		snaps = append(snaps, snap.InTown(t.Name))
+	}
+	return state.CombineSnapshots(snaps, s.ActivityLimit), at
+}
+
+func (s *Server) health() state.HealthEvent {
+	if t := s.single(); t != nil {
+		return t.Store.HealthEvent()
+	}
+	ev := state.HealthEvent{Type: "sources"}
+	for _, t := range s.towns {
+		h := t.Store.HealthEvent().InTown(t.Name)
+		ev.Sources = append(ev.Sources, h.Sources...)
+		ev.Dangling = append(ev.Dangling, h.Dangling...)
+	}
+	return ev
+}
+
+// withTown resolves the {town} path parameter, answering 404 for unknown
+// towns.
+func (s *Server) withTown(h func(http.ResponseWriter, *http.Request, *Town)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		t, ok := s.byName[r.PathValue("town")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		h(w, r, t)
+	}
+}
+
+func serveSnapshot(w http.ResponseWriter, r *http.Request, t *Town) {
+	at, ok := timeParam(w, r, "at")
+	if !ok {
+		return
+	}
+	if at.IsZero() {
+		writeJSON(w, t.Store.GetSnapshot())
+		return
+	}
+	snap, err := t.Store.SnapshotAt(at)
+	if err != nil {
+		writeHistoryError(w, err)
+		return
+	}
+	writeJSON(w, snap)
+}
+
+func serveHistory(w http.ResponseWriter, r *http.Request, t *Town) {
+	from, to, ok := historyRange(w, r)
+	if !ok {
+		return
+	}
+	h, err := t.Store.History(from, to)
+	if err != nil {
+		writeHistoryError(w, err)
+		return
+	}
+	writeJSON(w, h)
+}
+
+// historyRange reads ?from= and ?to=. from is required; to defaults to now.
+func historyRange(w http.ResponseWriter, r *http.Request) (from, to time.Time, ok bool) {
+	if from, ok = timeParam(w, r, "from"); !ok {
+		return
+	}
+	if to, ok = timeParam(w, r, "to"); !ok {
+		return
+	}
+	if from.IsZero() {
+		http.Error(w, "from is required", http.StatusBadRequest)
+		return from, to, false
+	}
+	if to.IsZero() {
+		to = time.Now()
+	}
+	if to.Before(from) {
+		http.Error(w, "to is before from", http.StatusBadRequest)
+		return from, to, false
+	}
+	return from, to, true
+}
+
+// timeParam reads a time query parameter: an RFC 3339 time, Unix seconds,
+// or a negative duration relative to now such as "-10m". A missing
+// parameter yields the zero time. On a bad value it answers 400 and
+// reports false.
+func timeParam(w http.ResponseWriter, r *http.Request, name string) (time.Time, bool) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return time.Time{}, true
+	}
+	if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+		return t, true
+	}
+	if sec, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return time.Unix(sec, 0), true
+	}
+	if d, err := time.ParseDuration(v); err == nil && d <= 0 {
+		return time.Now().Add(d), true
+	}
+	http.Error(w, fmt.Sprintf("%s: expected an RFC 3339 time, Unix seconds or a duration such as -10m, got %q", name, v), http.StatusBadRequest)
+	return time.Time{}, false
+}
+
+func writeHistoryError(w http.ResponseWriter, err error) {
+	if errors.Is(err, state.ErrHistoryGone) {
+		http.Error(w, err.Error(), http.StatusGone)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	data, _ := json.Marshal(v)
+	w.Write(data)
+}
@@ -11,13 +11,36 @@ import (
 // Broker manages SSE client connections and broadcasts events.
 type Broker struct {
 	mu      sync.RWMutex
-	clients map[chan []byte]struct{}
+	clients map[chan message]struct{}
+
+	// OnConnect, if set before serving, is called with the new client count
+	// each time a client connects.
+	OnConnect func(clients int)
+}
+
+// Event is a message with an SSE id. Browsers send the id of the last event
+// they received back as Last-Event-ID when they reconnect.
+type Event struct {
+	ID   string
+	Data any
+}
+
+// Resume decides what a connecting client is sent before live events.
+// lastID is the client's Last-Event-ID, empty on a first connection. It
+// returns the events to send first, such as a snapshot or the events the
+// client missed, and covered, which reports whether a live event with the
+// given id is already included in them. covered may be nil.
+type Resume func(lastID string) (first []Event, covered func(id string) bool)
+
+type message struct {
+	id   string
+	data []byte
 }
 
 // NewBroker creates an SSE broker.
 func NewBroker() *Broker {
 	return &Broker{
-		clients: make(map[chan []byte]struct{}),
+		clients: make(map[chan message]struct{}),
 	}
 }
 
@@ -29,6 +52,20 @@ func (b *Broker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 // ServeHTTPWithInitial handles SSE connections and sends an initial message
 // directly to the connecting client before entering the broadcast loop.
 func (b *Broker) ServeHTTPWithInitial(w http.ResponseWriter, r *http.Request, initial any) {
+	b.ServeHTTPResumable(w, r, func(string) ([]Event, func(string) bool) {
+		if initial == nil {
+			return nil, nil
+		}
+		return []Event{{Data: initial}}, nil
+	})
+}
+
+// ServeHTTPResumable handles SSE connections from clients that may be
+// resuming. The client is registered before resume is called, so no event
+// broadcast in between is lost. The client's last event id comes from the
+// Last-Event-ID header, or a last_event_id query parameter for clients that
+// reconnect by themselves.
+func (b *Broker) ServeHTTPResumable(w http.ResponseWriter, r *http.Request, resume Resume) {
 	flusher, ok := w.(http.Flusher)
 	if !ok {
 		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
@@ -40,52 +77,80 @@ func (b *Broker) ServeHTTPWithInitial(w http.ResponseWriter, r *http.Request, in
 	w.Header().Set("Connection", "keep-alive")
 	w.Header().Set("Access-Control-Allow-Origin", "*")
 
-	ch := make(chan []byte, 64)
-	b.addClient(ch)
+	ch := make(chan message, 64)
+	n := b.addClient(ch)
 	defer b.removeClient(ch)
+	if b.OnConnect != nil {
+		b.OnConnect(n)
+	}
 
 	// Send initial connection event.
 	fmt.Fprintf(w, "event: connected\ndata: {}\n\n")
 	flusher.Flush()
 
-	// Send initial snapshot directly to this client.
-	if initial != nil {
-		data, err := json.Marshal(initial)
-		if err == nil {
-			fmt.Fprintf(w, "data: %s\n\n", data)
-			flusher.Flush()
+	lastID := r.Header.Get("Last-Event-ID")
+	if lastID == "" {
+		lastID = r.URL.Query().Get("last_event_id")
+	}
+	first, covered := resume(lastID)
+	for _, e := range first {
+		data, err := json.Marshal(e.Data)
+		if err != nil {
+			log.Printf("sse: marshal error: %v", err)
+			continue
 		}
+		writeMessage(w, message{id: e.ID, data: data})
 	}
+	flusher.Flush()
 
 	ctx := r.Context()
 	for {
 		select {
 		case <-ctx.Done():
 			return
-		case data, ok := <-ch:
+		case msg, ok := <-ch:
 			if !ok {
 				return
 			}
-			fmt.Fprintf(w, "data: %s\n\n", data)
+			if covered != nil && msg.id != "" && covered(msg.id) {
+				continue
+			}
+			writeMessage(w, msg)
 			flusher.Flush()
 		}
 	}
 }
 
+func writeMessage(w http.ResponseWriter, msg message) {
+	if msg.id != "" {
+		fmt.Fprintf(w, "id: %s\n", msg.id)
+	}
+	fmt.Fprintf(w, "data: %s\n\n", msg.data)
+}
+
 // Broadcast sends data to all connected SSE clients.
 func (b *Broker) Broadcast(v any) {
-	data, err := json.Marshal(v)
+	b.BroadcastEvent(Event{Data: v})
+}
+
+// BroadcastEvent sends an event to all connected SSE clients. A client too
+// slow to keep up is disconnected, so that it reconnects and resumes rather
+// than silently missing the event.
+func (b *Broker) BroadcastEvent(e Event) {
+	data, err := json.Marshal(e.Data)
 	if err != nil {
 		log.Printf("sse: marshal error: %v", err)
 		return
 	}
-	b.mu.RLock()
-	defer b.mu.RUnlock()
+	b.mu.Lock()
+	defer b.mu.Unlock()
 	for ch := range b.clients {
 		select {
-		case ch <- data:
+		case ch <- message{id: e.ID, data: data}:
 		default:
-			// Client too slow, skip this event.
+			log.Printf("sse: client too slow, disconnecting")
+			delete(b.clients, ch)
+			close(ch)
 		}
 	}
 }
@@ -97,16 +162,20 @@ func (b *Broker) ClientCount() int {
 	return len(b.clients)
 }
 
-func (b *Broker) addClient(ch chan []byte) {
+func (b *Broker) addClient(ch chan message) int {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 	b.clients[ch] = struct{}{}
 	log.Printf("sse: client connected (%d total)", len(b.clients))
+	return len(b.clients)
 }
 
-func (b *Broker) removeClient(ch chan []byte) {
+func (b *Broker) removeClient(ch chan message) {
 	b.mu.Lock()
 	defer b.mu.Unlock()
+	if _, ok := b.clients[ch]; !ok {
+		return // already dropped by BroadcastEvent
+	}
 	delete(b.clients, ch)
 	close(ch)
 	log.Printf("sse: client disconnected (%d total)", len(b.clients))
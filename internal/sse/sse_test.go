@@ -1,6 +1,12 @@
 package sse
 
 import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -20,7 +26,7 @@ func TestBroadcastNoClients(t *testing.T) {
 func TestClientManagement(t *testing.T) {
 	b := NewBroker()
 
-	ch := make(chan []byte, 64)
+	ch := make(chan message, 64)
 	b.addClient(ch)
 	if b.ClientCount() != 1 {
 		t.Errorf("expected 1 client, got %d", b.ClientCount())
@@ -35,18 +41,95 @@ func TestClientManagement(t *testing.T) {
 func TestBroadcastToClient(t *testing.T) {
 	b := NewBroker()
 
-	ch := make(chan []byte, 64)
+	ch := make(chan message, 64)
 	b.addClient(ch)
 	defer b.removeClient(ch)
 
 	b.Broadcast(map[string]string{"type": "test"})
 
 	select {
-	case data := <-ch:
-		if len(data) == 0 {
+	case msg := <-ch:
+		if len(msg.data) == 0 {
 			t.Error("expected non-empty data")
 		}
 	default:
 		t.Error("expected data on channel")
 	}
 }
+
+func TestOnConnect(t *testing.T) {
+	b := NewBroker()
+	connected := make(chan int, 1)
+	b.OnConnect = func(n int) { connected <- n }
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		req := httptest.NewRequest("GET", "/api/events", nil).WithContext(ctx)
+		b.ServeHTTP(httptest.NewRecorder(), req)
+	}()
+
+	if n := <-connected; n != 1 {
+		t.Errorf("OnConnect got %d clients, want 1", n)
+	}
+	cancel()
+	<-done
+	if b.ClientCount() != 0 {
+		t.Errorf("expected 0 clients after disconnect, got %d", b.ClientCount())
+	}
+}
+
+func TestResume(t *testing.T) {
+	b := NewBroker()
+	connected := make(chan struct{})
+	b.OnConnect = func(int) { close(connected) }
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b.ServeHTTPResumable(w, r, func(lastID string) ([]Event, func(string) bool) {
+			if lastID != "1" {
+				t.Errorf("expected Last-Event-ID 1, got %q", lastID)
+			}
+			return []Event{{ID: "2", Data: "missed"}}, func(id string) bool { return id <= "2" }
+		})
+	}))
+	defer srv.Close()
+
+	req, _ := http.NewRequest("GET", srv.URL, nil)
+	req.Header.Set("Last-Event-ID", "1")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	<-connected
+	b.BroadcastEvent(Event{ID: "2", Data: "missed"}) // already replayed
+	b.BroadcastEvent(Event{ID: "3", Data: "live"})
+
+	var ids []string
+	scanner := bufio.NewScanner(resp.Body)
+	for len(ids) < 2 && scanner.Scan() {
+		if id, ok := strings.CutPrefix(scanner.Text(), "id: "); ok {
+			ids = append(ids, id)
+		}
+	}
+	if !slices.Equal(ids, []string{"2", "3"}) {
+		t.Errorf("expected the replay then the live event once each, got %v", ids)
+	}
+}
+
+func TestSlowClientDisconnected(t *testing.T) {
+	b := NewBroker()
+	ch := make(chan message, 1)
+	b.addClient(ch)
+	b.Broadcast("first")
+	b.Broadcast("second")
+	if b.ClientCount() != 0 {
+		t.Errorf("expected the slow client dropped, got %d clients", b.ClientCount())
+	}
+	<-ch
+	if _, ok := <-ch; ok {
+		t.Error("expected the slow client's channel closed")
+	}
+	b.removeClient(ch) // must not close it twice
+}
@@ -0,0 +1,76 @@
+package state
+
+// deriveActivities turns node changes in a diff into typed activity events
+// for the feed: polecats spawning and being nuked, beads being opened, hooked,
+// closed or escalated, and agents changing state.
+func deriveActivities(oldNodes []Node, d *Diff) []Activity {
+	oldNodeMap := make(map[string]Node, len(oldNodes))
+	for _, n := range oldNodes {
+		oldNodeMap[n.ID] = n
+	}
+
+	var acts []Activity
+	add := func(event, agent, detail string) {
+		acts = append(acts, Activity{
+			Timestamp: d.Timestamp,
+			Event:     event,
+			Agent:     agent,
+			Detail:    detail,
+		})
+	}
+
+	for _, n := range d.NodesAdded {
+		switch n.Type {
+		case "polecat":
+			add("polecat_spawned", n.ID, "Spawned in "+n.Rig)
+		case "bead":
+			if n.State == "hooked" {
+				add("bead_hooked", beadAgent(n), "Hooked "+n.Label)
+			} else {
+				add("bead_opened", beadAgent(n), "Opened "+n.Label)
+			}
+		}
+	}
+
+	for _, id := range d.NodesRemoved {
+		if old := oldNodeMap[id]; old.Type == "polecat" {
+			add("polecat_nuked", old.ID, "Removed from "+old.Rig)
+		}
+	}
+
+	for _, n := range d.NodesUpdated {
+		old := oldNodeMap[n.ID]
+		if old.State == n.State {
+			continue
+		}
+		switch n.Type {
+		case "bead":
+			switch n.State {
+			case "closed":
+				add("bead_closed", beadAgent(n), "Closed "+n.Label)
+			case "hooked":
+				add("bead_hooked", beadAgent(n), "Hooked "+n.Label)
+			case "escalated":
+				add("escalation", beadAgent(n), "Escalated "+n.Label)
+			}
+		case "polecat":
+			if n.State == "nuked" {
+				add("polecat_nuked", n.ID, "Nuked")
+				continue
+			}
+			add("state_change", n.ID, old.State+" → "+n.State)
+		case "mayor", "deacon", "witness", "refinery", "crew":
+			add("state_change", n.ID, old.State+" → "+n.State)
+		}
+	}
+
+	return acts
+}
+
+// beadAgent attributes a bead event to its assignee, falling back to the bead.
+func beadAgent(n Node) string {
+	if a := n.Metadata["assignee"]; a != "" {
+		return a
+	}
+	return n.ID
+}
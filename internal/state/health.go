@@ -0,0 +1,100 @@
+package state
+
+import (
+	"slices"
+	"time"
+)
+
+// SourceHealth reports how a poller source's collections have gone.
+type SourceHealth struct {
+	Name        string          `json:"name"`
+	LastRun     time.Time       `json:"last_run,omitzero"`
+	LastSuccess time.Time       `json:"last_success,omitzero"`
+	LastError   string          `json:"last_error,omitempty"`
+	DurationMS  int64           `json:"duration_ms"`
+	Stale       bool            `json:"stale"`
+	Commands    []CommandHealth `json:"commands,omitempty"`
+	// Warnings describe output the source could only partly understand,
+	// such as unknown fields after a gt or bd upgrade.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// CommandHealth reports the most recent run of a single CLI command.
+type CommandHealth struct {
+	Command     string    `json:"command"`
+	LastRun     time.Time `json:"last_run,omitzero"`
+	LastSuccess time.Time `json:"last_success,omitzero"`
+	LastError   string    `json:"last_error,omitempty"`
+	Stderr      string    `json:"stderr,omitempty"`
+	DurationMS  int64     `json:"duration_ms"`
+}
+
+// DanglingEdge is an edge a source reported to or from a node that no source
+// reports. Such edges are withheld from clients and listed here instead.
+type DanglingEdge struct {
+	Source  string `json:"source"` // the poller source that reported the edge
+	Edge    Edge   `json:"edge"`
+	Missing string `json:"missing"` // the endpoint with no node
+}
+
+// HealthEvent is the SSE message sent when a source's health changes.
+type HealthEvent struct {
+	Type     string         `json:"type"` // always "sources"
+	Sources  []SourceHealth `json:"sources"`
+	Dangling []DanglingEdge `json:"dangling_edges,omitempty"`
+}
+
+// SetSourceHealth records a source's health. It reports whether the change is
+// worth telling clients about: the source went stale or recovered, or its
+// error or warnings changed.
+func (s *Store) SetSourceHealth(h SourceHealth) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, old := range s.health {
+		if old.Name == h.Name {
+			s.health[i] = h
+			return old.Stale != h.Stale || old.LastError != h.LastError || !slices.Equal(old.Warnings, h.Warnings)
+		}
+	}
+	s.health = append(s.health, h)
+	return true
+}
+
+// SourceHealth returns the health of every source that has reported.
+func (s *Store) SourceHealth() []SourceHealth {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return slices.Clone(s.health)
+}
+
+// DanglingEdges returns the edges withheld from the snapshot because an
+// endpoint is missing.
+func (s *Store) DanglingEdges() []DanglingEdge {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return slices.Clone(s.dangling)
+}
+
+// HealthEvent returns the current health of all sources as an SSE message.
+func (s *Store) HealthEvent() HealthEvent {
+	return HealthEvent{Type: "sources", Sources: s.SourceHealth(), Dangling: s.DanglingEdges()}
+}
+
+// MarkStale flags the nodes last reported by the named source as stale,
+// instead of removing them, and returns the diff. Nodes another, healthy
+// source also reports stay fresh. It returns nil if nothing changed.
+func (s *Store) MarkStale(name string) *Diff {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.sources[name]; !ok || s.stale[name] {
+		return nil
+	}
+	s.stale[name] = true
+
+	nodes, edges := s.merge()
+	diff := s.apply(nodes, edges, summarize(nodes), true)
+	s.record(diff)
+	return diff
+}
@@ -0,0 +1,237 @@
+package state
+
+import (
+	"errors"
+	"slices"
+	"time"
+)
+
+const (
+	// DefaultHistoryLimit is how many diffs a store keeps for time travel
+	// unless SetHistoryLimit changes it.
+	DefaultHistoryLimit = 5000
+	// keyframeEvery is how many diffs pass between full snapshots in the
+	// history. Rebuilding a moment replays at most this many diffs.
+	keyframeEvery = 100
+)
+
+// ErrHistoryGone is returned for moments and versions the retained history
+// no longer covers.
+var ErrHistoryGone = errors.New("state: requested point is not in the retained history")
+
+// History is the town's state at From and every diff up to To, so a client
+// can rebuild and scrub through the period.
+type History struct {
+	Type     string    `json:"type"` // always "history"
+	From     time.Time `json:"from"`
+	To       time.Time `json:"to"`
+	Snapshot Snapshot  `json:"snapshot"`
+	Diffs    []*Diff   `json:"diffs"`
+}
+
+// history is a bounded record of the store's diffs, with keyframes: full
+// snapshots taken every keyframeEvery diffs. It always starts at a keyframe,
+// so any retained moment can be rebuilt by replaying diffs onto the last
+// keyframe before it.
+type history struct {
+	limit     int
+	seq       uint64  // sequence number of the last recorded diff
+	diffs     []*Diff // oldest first; diffs[i] has sequence number first+i
+	first     uint64
+	keyframes []keyframe // oldest first
+}
+
+type keyframe struct {
+	snap Snapshot
+	next uint64 // sequence number of the first diff after the keyframe
+}
+
+// newHistory starts a history at snap, whose version is the last one before
+// the history's first diff.
+func newHistory(snap Snapshot) history {
+	// Nothing before the store's creation can be rebuilt.
+	snap.Timestamp = time.Now()
+	return history{
+		limit:     DefaultHistoryLimit,
+		seq:       snap.Version,
+		first:     snap.Version + 1,
+		keyframes: []keyframe{{snap: cloneSnapshot(snap), next: snap.Version + 1}},
+	}
+}
+
+// record adds a diff that produced snap, numbering it with the next version,
+// taking a keyframe when one is due and dropping the oldest keyframe's diffs
+// once over the limit.
+func (h *history) record(d *Diff, snap Snapshot) {
+	h.seq++
+	d.Version = h.seq
+	h.diffs = append(h.diffs, d)
+	if h.seq-h.keyframes[len(h.keyframes)-1].next+1 >= keyframeEvery {
+		snap.Timestamp = d.Timestamp
+		snap.Version = d.Version
+		h.keyframes = append(h.keyframes, keyframe{snap: cloneSnapshot(snap), next: h.seq + 1})
+	}
+	for len(h.diffs) > h.limit && len(h.keyframes) > 1 {
+		drop := h.keyframes[1].next - h.first
+		h.diffs = slices.Delete(h.diffs, 0, int(drop))
+		h.first += drop
+		h.keyframes = h.keyframes[1:]
+	}
+}
+
+// at rebuilds the state at t. activityLimit caps the rebuilt activity.
+func (h *history) at(t time.Time, activityLimit int) (Snapshot, error) {
+	i, found := slices.BinarySearchFunc(h.keyframes, t, func(k keyframe, t time.Time) int {
+		return k.snap.Timestamp.Compare(t)
+	})
+	if !found {
+		i--
+	}
+	if i < 0 {
+		return Snapshot{}, ErrHistoryGone
+	}
+	kf := h.keyframes[i]
+	snap := cloneSnapshot(kf.snap)
+	for _, d := range h.diffs[kf.next-h.first:] {
+		if d.Timestamp.After(t) {
+			break
+		}
+		snap = applyDiff(snap, d, activityLimit)
+	}
+	snap.Type = "snapshot"
+	snap.Timestamp = t
+	return snap, nil
+}
+
+// since returns the diffs after version v, or ErrHistoryGone if some of them
+// are no longer retained or there are more than limit.
+func (h *history) since(v uint64, limit int) ([]*Diff, error) {
+	if v > h.seq || v+1 < h.first || h.seq-v > uint64(limit) {
+		return nil, ErrHistoryGone
+	}
+	return slices.Clone(h.diffs[v+1-h.first:]), nil
+}
+
+// between returns the diffs recorded after from and up to to.
+func (h *history) between(from, to time.Time) []*Diff {
+	out := []*Diff{}
+	for _, d := range h.diffs {
+		if d.Timestamp.After(from) && !d.Timestamp.After(to) {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// applyDiff returns snap with the diff applied, as a client would apply it.
+func applyDiff(snap Snapshot, d *Diff, activityLimit int) Snapshot {
+	if len(d.NodesRemoved) > 0 || len(d.NodesUpdated) > 0 {
+		updated := make(map[string]Node, len(d.NodesUpdated))
+		for _, n := range d.NodesUpdated {
+			updated[n.ID] = n
+		}
+		nodes := snap.Nodes[:0:0]
+		for _, n := range snap.Nodes {
+			if slices.Contains(d.NodesRemoved, n.ID) {
+				continue
+			}
+			if u, ok := updated[n.ID]; ok {
+				n = u
+			}
+			nodes = append(nodes, n)
+		}
+		snap.Nodes = nodes
+	}
+	snap.Nodes = append(snap.Nodes, d.NodesAdded...)
+
+	if len(d.EdgesRemoved) > 0 {
+		snap.Edges = slices.DeleteFunc(slices.Clone(snap.Edges), func(e Edge) bool {
+			return slices.Contains(d.EdgesRemoved, edgeKey(e))
+		})
+	}
+	snap.Edges = append(snap.Edges, d.EdgesAdded...)
+
+	snap.Activity = append(snap.Activity, d.ActivityAppend...)
+	if len(snap.Activity) > activityLimit {
+		snap.Activity = snap.Activity[len(snap.Activity)-activityLimit:]
+	}
+	if d.Summary != nil {
+		snap.Summary = *d.Summary
+	}
+	snap.Version = d.Version
+	return snap
+}
+
+// cloneSnapshot copies the snapshot's slices, so appending to either copy
+// cannot write into the other. Nodes, edges and activities are never
+// modified in place, so they are shared.
+func cloneSnapshot(s Snapshot) Snapshot {
+	s.Nodes = slices.Clone(s.Nodes)
+	s.Edges = slices.Clone(s.Edges)
+	s.Activity = slices.Clone(s.Activity)
+	return s
+}
+
+// record adds a diff to the history and the journal. s.mu must be held.
+func (s *Store) record(d *Diff) {
+	if d == nil {
+		return
+	}
+	s.history.record(d, s.snapshot)
+	s.snapshot.Version = d.Version
+	if s.journal != nil {
+		s.journal.Record(d, s.snapshot)
+	}
+}
+
+// SetHistoryLimit sets how many diffs the store keeps for time travel. The
+// history is trimmed a keyframe at a time, so it can briefly hold more.
+// Limits below 1 are ignored, and others below keyframeEvery are raised to it.
+func (s *Store) SetHistoryLimit(n int) {
+	if n < 1 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.history.limit = max(n, keyframeEvery)
+}
+
+// SnapshotAt rebuilds the state as it was at t. It returns ErrHistoryGone if
+// t is older than the retained history.
+func (s *Store) SnapshotAt(t time.Time) (Snapshot, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.history.at(t, s.activityLimit)
+}
+
+// Epoch returns the epoch of the store's versions.
+func (s *Store) Epoch() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.snapshot.Epoch
+}
+
+// DiffsSince returns the diffs made after the given version, for a client
+// catching up from it. It returns ErrHistoryGone if the version belongs to
+// another epoch, or if the diffs since are no longer retained or number more
+// than limit, in which case the client needs a snapshot.
+func (s *Store) DiffsSince(epoch string, version uint64, limit int) ([]*Diff, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if epoch != s.snapshot.Epoch {
+		return nil, ErrHistoryGone
+	}
+	return s.history.since(version, limit)
+}
+
+// History returns the state at from and the diffs recorded after it, up to
+// to.
+func (s *Store) History(from, to time.Time) (History, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	snap, err := s.history.at(from, s.activityLimit)
+	if err != nil {
+		return History{}, err
+	}
+	return History{Type: "history", From: from, To: to, Snapshot: snap, Diffs: s.history.between(from, to)}, nil
+}
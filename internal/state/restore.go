@@ -0,0 +1,106 @@
+package state
+
+import (
+	"slices"
+	"time"
+)
+
+// restoredSource names the fragment holding a restored state. It merges after
+// every live source, so it only fills in what they have not reported yet.
+const restoredSource = "(restored)"
+
+// Journal persists a store's changes, such as to an on-disk log. The store
+// calls it with its lock held, so calls arrive in order and the snapshots
+// must not be retained past the call.
+type Journal interface {
+	// Start begins the journal at the store's current state.
+	Start(snap Snapshot)
+	// Record adds a change along with the state it produced.
+	Record(d *Diff, snap Snapshot)
+}
+
+// SetJournal starts j at the current state and sends it every later change.
+func (s *Store) SetJournal(j Journal) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.journal = j
+	snap := s.snapshot
+	snap.Type = "snapshot"
+	if snap.Timestamp.IsZero() {
+		snap.Timestamp = time.Now()
+	}
+	j.Start(snap)
+}
+
+// Restore loads a state saved by an earlier run: snap, then diffs applied
+// in order. The diffs also become the store's history, and the store carries
+// on the saved epoch and versions. The restored nodes are marked stale and
+// stay until DropRestored, filling in for sources that have not polled yet;
+// call it before any source reports.
+func (s *Store) Restore(snap Snapshot, diffs []*Diff) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snap = cloneSnapshot(snap)
+	if snap.Timestamp.IsZero() {
+		snap.Timestamp = time.Now()
+	}
+	if snap.Epoch == "" {
+		snap.Epoch = s.snapshot.Epoch
+	}
+	limit := s.history.limit
+	s.history = newHistory(snap)
+	s.history.limit = limit
+	s.history.keyframes[0].snap.Timestamp = snap.Timestamp
+	for _, d := range diffs {
+		// removedEdges is not saved; rebuild it for Diff.InTown.
+		for _, e := range snap.Edges {
+			if slices.Contains(d.EdgesRemoved, edgeKey(e)) {
+				d.removedEdges = append(d.removedEdges, e)
+			}
+		}
+		snap = applyDiff(snap, d, s.activityLimit)
+		s.history.record(d, snap)
+	}
+
+	snap.Type = "snapshot"
+	if snap.Edges == nil {
+		snap.Edges = []Edge{}
+	}
+	if snap.Activity == nil {
+		snap.Activity = []Activity{}
+	}
+	s.snapshot = snap
+
+	// Marking the nodes stale is a change like any other, so clients resuming
+	// from the saved versions see it.
+	nodes := slices.Clone(snap.Nodes)
+	for i := range nodes {
+		nodes[i].Stale = true
+	}
+	s.record(s.apply(nodes, snap.Edges, snap.Summary, true))
+
+	s.sources[restoredSource] = Fragment{Nodes: nodes, Edges: snap.Edges}
+	s.order = append(s.order, restoredSource)
+	s.stale[restoredSource] = true
+}
+
+// DropRestored discards what remains of a restored state, once every source
+// has polled, and returns the diff. It returns nil if nothing was restored
+// or nothing changed.
+func (s *Store) DropRestored() *Diff {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.sources[restoredSource]; !ok {
+		return nil
+	}
+	delete(s.sources, restoredSource)
+	delete(s.stale, restoredSource)
+	s.order = slices.DeleteFunc(s.order, func(name string) bool { return name == restoredSource })
+
+	nodes, edges := s.merge()
+	diff := s.apply(nodes, edges, summarize(nodes), true)
+	s.record(diff)
+	return diff
+}
@@ -2,6 +2,8 @@ package state
 
 import (
 	"encoding/json"
+	"slices"
+	"strconv"
 	"sync"
 	"time"
 )
@@ -14,6 +16,10 @@ type Node struct {
 	Rig      string            `json:"rig,omitempty"`
 	State    string            `json:"state"`
 	Metadata map[string]string `json:"metadata,omitempty"`
+	// Stale is set when the node reflects an earlier collection because what
+	// reports it is failing: its source, or a part of it such as one rig's
+	// beads database.
+	Stale bool `json:"stale,omitempty"`
 }
 
 // Edge represents a relationship between two nodes.
@@ -23,6 +29,9 @@ type Edge struct {
 	Type     string            `json:"type"`
 	Label    string            `json:"label,omitempty"`
 	Metadata map[string]string `json:"metadata,omitempty"`
+	// ExpiresAt marks a transient edge, such as a mail flow, that the store
+	// drops once the time has passed.
+	ExpiresAt time.Time `json:"expires_at,omitzero"`
 }
 
 // Activity represents a recent event in the system.
@@ -31,6 +40,19 @@ type Activity struct {
 	Event     string    `json:"event"`
 	Agent     string    `json:"agent"`
 	Detail    string    `json:"detail"`
+	// From and To identify sender and recipient for events between two
+	// agents, such as mail_sent.
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+	// Key, if set, identifies what the activity reports, such as a merge of
+	// one branch, so an event several producers observe is recorded once.
+	Key string `json:"key,omitempty"`
+}
+
+// MergeKey is the Key of a merge_complete or merge_failed activity for
+// branch, shared by the merge queue and the events feed, which both see it.
+func MergeKey(event, branch string) string {
+	return event + ":" + branch
 }
 
 // Summary contains aggregate counts for the status bar.
@@ -49,12 +71,19 @@ type Snapshot struct {
 	Edges     []Edge     `json:"edges"`
 	Activity  []Activity `json:"activity"`
 	Summary   Summary    `json:"summary"`
+	// Epoch identifies the store's sequence of versions, and Version is that
+	// of the last diff the snapshot includes. Versions from different epochs
+	// cannot be compared.
+	Epoch   string `json:"epoch,omitempty"`
+	Version uint64 `json:"version,omitempty"`
 }
 
 // Diff represents changes between two snapshots.
 type Diff struct {
-	Type           string     `json:"type"`
-	Timestamp      time.Time  `json:"timestamp"`
+	Type      string    `json:"type"`
+	Timestamp time.Time `json:"timestamp"`
+	// Version numbers the store's diffs in the order they were made.
+	Version        uint64     `json:"version,omitempty"`
 	NodesAdded     []Node     `json:"nodes_added,omitempty"`
 	NodesRemoved   []string   `json:"nodes_removed,omitempty"`
 	NodesUpdated   []Node     `json:"nodes_updated,omitempty"`
@@ -62,24 +91,81 @@ type Diff struct {
 	EdgesRemoved   []string   `json:"edges_removed,omitempty"`
 	ActivityAppend []Activity `json:"activity_append,omitempty"`
 	Summary        *Summary   `json:"summary,omitempty"`
+
+	removedEdges []Edge // the edges behind EdgesRemoved, for InTown
+}
+
+// Fragment is the part of the topology reported by a single poller source.
+type Fragment struct {
+	Nodes []Node
+	Edges []Edge
+	// Annotations decorate nodes owned by other sources, keyed by node ID.
+	// Annotations for nodes that no source reports are dropped.
+	Annotations map[string]Annotation
+	// Activities are events the source observed directly. Unlike the
+	// fragment's nodes and edges they are appended, not replaced.
+	Activities []Activity
+}
+
+// Annotation adds metadata to an existing node.
+type Annotation struct {
+	Metadata map[string]string
+	// State, if set, replaces the node's state, for sources that know more
+	// about a node's progress than its owner, such as a bead in the merge
+	// queue.
+	State string
 }
 
 // Store holds the current topology state and computes diffs.
 type Store struct {
 	mu       sync.RWMutex
 	snapshot Snapshot
+
+	// Fragments reported via UpdateSource, merged in registration order.
+	sources map[string]Fragment
+	order   []string
+	stale   map[string]bool // sources whose last collection failed
+
+	health   []SourceHealth
+	dangling []DanglingEdge // edges withheld by the last merge
+
+	activityLimit int // activity entries kept; see SetActivityLimit
+	history       history
+	journal       Journal // see SetJournal
 }
 
+// DefaultActivityLimit is how many activity entries a store keeps unless
+// SetActivityLimit changes it.
+const DefaultActivityLimit = 100
+
 // NewStore creates an empty state store.
 func NewStore() *Store {
-	return &Store{
+	s := &Store{
 		snapshot: Snapshot{
-			Type:  "snapshot",
-			Nodes: []Node{},
-			Edges: []Edge{},
+			Type:     "snapshot",
+			Nodes:    []Node{},
+			Edges:    []Edge{},
 			Activity: []Activity{},
+			Epoch:    strconv.FormatInt(time.Now().UnixNano(), 36),
 		},
+		sources:       make(map[string]Fragment),
+		stale:         make(map[string]bool),
+		activityLimit: DefaultActivityLimit,
+	}
+	s.history = newHistory(s.snapshot)
+	return s
+}
+
+// SetActivityLimit sets how many activity entries the store keeps, trimming
+// the oldest if it already holds more. Limits below 1 are ignored.
+func (s *Store) SetActivityLimit(n int) {
+	if n < 1 {
+		return
 	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.activityLimit = n
+	s.appendActivity()
 }
 
 // GetSnapshot returns a copy of the current snapshot.
@@ -99,33 +185,299 @@ func (s *Store) Update(nodes []Node, edges []Edge, summary Summary) *Diff {
 	defer s.mu.Unlock()
 
 	wasEmpty := len(s.snapshot.Nodes) == 0
+	diff := s.apply(nodes, edges, summary, wasEmpty)
+	s.record(diff)
+	if wasEmpty {
+		return nil
+	}
+	return diff
+}
+
+// UpdateSource replaces the fragment reported by the named source, merges all
+// source fragments into the current snapshot and returns the diff. The summary
+// is derived from the merged nodes. It returns nil when nothing changed; unlike
+// Update, the first report into an empty store still yields a diff, so clients
+// that connected before the first poll receive it.
+func (s *Store) UpdateSource(name string, f Fragment) *Diff {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, seen := s.sources[name]
+	if !seen {
+		// Live sources take precedence over a restored state.
+		i := len(s.order)
+		if _, ok := s.sources[restoredSource]; ok {
+			i--
+		}
+		s.order = slices.Insert(s.order, i, name)
+	}
+	s.sources[name] = f
+	delete(s.stale, name)
+
+	// A source's first report only reveals what already existed, so it does
+	// not produce activity.
+	nodes, edges := s.merge()
+	diff := s.apply(nodes, edges, summarize(nodes), !seen)
+	if acts := s.unrecorded(f.Activities); len(acts) > 0 {
+		if diff == nil {
+			diff = &Diff{Type: "diff", Timestamp: time.Now()}
+		}
+		diff.ActivityAppend = append(diff.ActivityAppend, acts...)
+		s.appendActivity(acts...)
+	}
+	s.record(diff)
+	return diff
+}
+
+// Expire drops transient edges whose ExpiresAt has passed and returns the
+// resulting diff, or nil if nothing expired.
+func (s *Store) Expire(now time.Time) *Diff {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	expired := false
+	for name, f := range s.sources {
+		var kept []Edge
+		dropped := false
+		for _, e := range f.Edges {
+			if !e.ExpiresAt.IsZero() && !e.ExpiresAt.After(now) {
+				dropped = true
+				continue
+			}
+			kept = append(kept, e)
+		}
+		if dropped {
+			f.Edges = kept
+			s.sources[name] = f
+			expired = true
+		}
+	}
+	if !expired {
+		return nil
+	}
+
+	nodes, edges := s.merge()
+	diff := s.apply(nodes, edges, summarize(nodes), true)
+	s.record(diff)
+	return diff
+}
 
+// apply swaps in the new state and returns the diff, or nil if nothing changed.
+// Unless quiet is set, activities derived from the diff are appended to it and
+// to the activity buffer. s.mu must be held.
+func (s *Store) apply(nodes []Node, edges []Edge, summary Summary, quiet bool) *Diff {
 	diff := computeDiff(s.snapshot.Nodes, nodes, s.snapshot.Edges, edges, s.snapshot.Summary, summary)
+	if !quiet {
+		diff.ActivityAppend = deriveActivities(s.snapshot.Nodes, diff)
+		s.appendActivity(diff.ActivityAppend...)
+	}
 
 	s.snapshot.Nodes = nodes
 	s.snapshot.Edges = edges
 	s.snapshot.Summary = summary
 	s.snapshot.Timestamp = time.Now()
 
-	if wasEmpty {
-		return nil
-	}
-
 	if diff.isEmpty() {
 		return nil
 	}
 	return diff
 }
 
-// AddActivity appends an activity event to the store and returns it.
+// AddActivity appends an activity event to the store, unless one with the
+// same key is already recorded.
 func (s *Store) AddActivity(a Activity) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.snapshot.Activity = append(s.snapshot.Activity, a)
-	// Keep only last 100 activity entries.
-	if len(s.snapshot.Activity) > 100 {
-		s.snapshot.Activity = s.snapshot.Activity[len(s.snapshot.Activity)-100:]
+	acts := s.unrecorded([]Activity{a})
+	if len(acts) == 0 {
+		return
+	}
+	s.appendActivity(acts...)
+	s.record(&Diff{Type: "diff", Timestamp: time.Now(), ActivityAppend: acts})
+}
+
+// AppendActivity records activity events and returns a diff carrying them, so
+// they can be pushed to clients without waiting for the next poll. Events
+// whose key is already recorded are dropped; it returns nil if none are left.
+func (s *Store) AppendActivity(acts ...Activity) *Diff {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	acts = s.unrecorded(acts)
+	if len(acts) == 0 {
+		return nil
+	}
+	s.appendActivity(acts...)
+	diff := &Diff{
+		Type:           "diff",
+		Timestamp:      time.Now(),
+		ActivityAppend: acts,
+	}
+	s.record(diff)
+	return diff
+}
+
+// unrecorded returns acts without those whose key an earlier activity in the
+// buffer, or in acts, already has. s.mu must be held.
+func (s *Store) unrecorded(acts []Activity) []Activity {
+	var keys map[string]bool
+	kept := acts[:0:0]
+	for _, a := range acts {
+		if a.Key != "" {
+			if keys == nil {
+				keys = make(map[string]bool)
+				for _, old := range s.snapshot.Activity {
+					if old.Key != "" {
+						keys[old.Key] = true
+					}
+				}
+			}
+			if keys[a.Key] {
+				continue
+			}
+			keys[a.Key] = true
+		}
+		kept = append(kept, a)
+	}
+	return kept
+}
+
+// appendActivity adds to the activity ring buffer. s.mu must be held.
+func (s *Store) appendActivity(acts ...Activity) {
+	s.snapshot.Activity = append(s.snapshot.Activity, acts...)
+	// Keep only the most recent entries.
+	if len(s.snapshot.Activity) > s.activityLimit {
+		s.snapshot.Activity = s.snapshot.Activity[len(s.snapshot.Activity)-s.activityLimit:]
+	}
+}
+
+// merge combines all source fragments. Nodes are de-duplicated by ID and edges
+// by key; the first source to report a node wins, and metadata keys missing
+// from it, and its state if "unknown", are filled in from later sources. A
+// node is stale only if every
+// source reporting it is stale or reports it as stale. Edges whose endpoints no source reports are
+// withheld and recorded in s.dangling. s.mu must be held.
+func (s *Store) merge() ([]Node, []Edge) {
+	nodes := []Node{}
+	edges := []Edge{}
+	var owners []string // reporting source of each edge
+	nodeIdx := make(map[string]int)
+	edgeSeen := make(map[string]bool)
+
+	for _, name := range s.order {
+		f := s.sources[name]
+		stale := s.stale[name]
+		for _, n := range f.Nodes {
+			i, exists := nodeIdx[n.ID]
+			if !exists {
+				n.Stale = n.Stale || stale
+				nodeIdx[n.ID] = len(nodes)
+				nodes = append(nodes, n)
+				continue
+			}
+			nodes[i].Metadata = mergeMetadata(nodes[i].Metadata, n.Metadata)
+			if nodes[i].State == "unknown" && n.State != "" {
+				nodes[i].State = n.State
+			}
+			nodes[i].Stale = nodes[i].Stale && (n.Stale || stale)
+		}
+		for _, e := range f.Edges {
+			k := edgeKey(e)
+			if edgeSeen[k] {
+				continue
+			}
+			edgeSeen[k] = true
+			edges = append(edges, e)
+			owners = append(owners, name)
+		}
+	}
+
+	// Edges can only be checked once every source's nodes are known.
+	s.dangling = nil
+	kept := edges[:0]
+	for i, e := range edges {
+		missing := ""
+		if _, ok := nodeIdx[e.Source]; !ok {
+			missing = e.Source
+		} else if _, ok := nodeIdx[e.Target]; !ok {
+			missing = e.Target
+		}
+		if missing != "" {
+			// A restored edge going missing is expected, not worth reporting.
+			if owners[i] != restoredSource {
+				s.dangling = append(s.dangling, DanglingEdge{Source: owners[i], Edge: e, Missing: missing})
+			}
+			continue
+		}
+		kept = append(kept, e)
+	}
+	edges = kept
+
+	// Annotations apply once every source's nodes are known, and override the
+	// owning source's state and values for the keys they set.
+	for _, name := range s.order {
+		for id, a := range s.sources[name].Annotations {
+			i, exists := nodeIdx[id]
+			if !exists {
+				continue
+			}
+			md := make(map[string]string, len(nodes[i].Metadata)+len(a.Metadata))
+			for k, v := range nodes[i].Metadata {
+				md[k] = v
+			}
+			for k, v := range a.Metadata {
+				md[k] = v
+			}
+			nodes[i].Metadata = md
+			if a.State != "" {
+				nodes[i].State = a.State
+			}
+		}
+	}
+	return nodes, edges
+}
+
+// mergeMetadata returns dst with keys from src that dst does not have. dst is
+// copied before writing so fragments are never mutated.
+func mergeMetadata(dst, src map[string]string) map[string]string {
+	var out map[string]string
+	for k, v := range src {
+		if _, ok := dst[k]; ok {
+			continue
+		}
+		if out == nil {
+			out = make(map[string]string, len(dst)+len(src))
+			for dk, dv := range dst {
+				out[dk] = dv
+			}
+		}
+		out[k] = v
+	}
+	if out == nil {
+		return dst
+	}
+	return out
+}
+
+// summarize derives the status bar counts from the merged nodes.
+func summarize(nodes []Node) Summary {
+	var sum Summary
+	for _, n := range nodes {
+		switch n.Type {
+		case "witness":
+			sum.RigCount++ // one witness per rig
+		case "polecat":
+			if n.State == "working" {
+				sum.ActivePolecats++
+			}
+		case "bead":
+			sum.OpenBeads++
+		case "convoy":
+			if n.State == "active" {
+				sum.ActiveConvoys++
+			}
+		}
 	}
+	return sum
 }
 
 func computeDiff(oldNodes, newNodes []Node, oldEdges, newEdges []Edge, oldSummary, newSummary Summary) *Diff {
@@ -176,9 +528,10 @@ func computeDiff(oldNodes, newNodes []Node, oldEdges, newEdges []Edge, oldSummar
 			d.EdgesAdded = append(d.EdgesAdded, e)
 		}
 	}
-	for k := range oldEdgeMap {
+	for k, e := range oldEdgeMap {
 		if _, exists := newEdgeMap[k]; !exists {
 			d.EdgesRemoved = append(d.EdgesRemoved, k)
+			d.removedEdges = append(d.removedEdges, e)
 		}
 	}
 
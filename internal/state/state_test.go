@@ -1,7 +1,12 @@
 package state
 
 import (
+	"encoding/json"
+	"slices"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestNewStore(t *testing.T) {
@@ -171,3 +176,606 @@ func TestSummaryDiff(t *testing.T) {
 		t.Errorf("expected rig_count 2, got %d", diff.Summary.RigCount)
 	}
 }
+
+func TestUpdateSourceMerges(t *testing.T) {
+	s := NewStore()
+	s.UpdateSource("topology", Fragment{
+		Nodes: []Node{
+			{ID: "mayor", Type: "mayor", Label: "Mayor", State: "running"},
+			{ID: "zeppelin/witness", Type: "witness", Label: "Witness", Rig: "zeppelin", State: "running"},
+			{ID: "zeppelin/polecats/rust", Type: "polecat", Label: "rust", Rig: "zeppelin", State: "working",
+				Metadata: map[string]string{"hooked_bead": "zep-1"}},
+		},
+		Edges: []Edge{{Source: "zeppelin/witness", Target: "zeppelin/polecats/rust", Type: "monitoring"}},
+	})
+
+	// A second source reporting the same polecat must not duplicate it.
+	diff := s.UpdateSource("polecats", Fragment{
+		Nodes: []Node{
+			{ID: "zeppelin/polecats/rust", Type: "polecat", Label: "rust", Rig: "zeppelin", State: "idle",
+				Metadata: map[string]string{"hooked_bead": "zep-2", "session": "abc"}},
+		},
+		Edges: []Edge{{Source: "zeppelin/witness", Target: "zeppelin/polecats/rust", Type: "monitoring"}},
+	})
+	if diff == nil {
+		t.Fatal("expected diff for metadata merge, got nil")
+	}
+
+	snap := s.GetSnapshot()
+	if len(snap.Nodes) != 3 {
+		t.Fatalf("expected 3 nodes, got %d", len(snap.Nodes))
+	}
+	if len(snap.Edges) != 1 {
+		t.Errorf("expected 1 edge, got %d", len(snap.Edges))
+	}
+	pc := snap.Nodes[2]
+	if pc.State != "working" {
+		t.Errorf("expected first source to win state, got %q", pc.State)
+	}
+	if pc.Metadata["hooked_bead"] != "zep-1" || pc.Metadata["session"] != "abc" {
+		t.Errorf("unexpected merged metadata: %v", pc.Metadata)
+	}
+	if snap.Summary.RigCount != 1 || snap.Summary.ActivePolecats != 1 {
+		t.Errorf("unexpected summary: %+v", snap.Summary)
+	}
+}
+
+func TestUnknownStateFilledIn(t *testing.T) {
+	s := NewStore()
+	s.UpdateSource("topology", Fragment{Nodes: []Node{
+		{ID: "gastown/refinery", Type: "refinery", State: "unknown"},
+		{ID: "gastown/witness", Type: "witness", State: "running"},
+	}})
+	s.UpdateSource("rigs", Fragment{Nodes: []Node{
+		{ID: "gastown/refinery", Type: "refinery", State: "stopped"},
+		{ID: "gastown/witness", Type: "witness", State: "stopped"},
+	}})
+	want := map[string]string{"gastown/refinery": "stopped", "gastown/witness": "running"}
+	for _, n := range s.GetSnapshot().Nodes {
+		if n.State != want[n.ID] {
+			t.Errorf("%s: state %q, want %q", n.ID, n.State, want[n.ID])
+		}
+	}
+}
+
+func TestUpdateSourceReplacesFragment(t *testing.T) {
+	s := NewStore()
+	s.UpdateSource("topology", Fragment{Nodes: []Node{{ID: "mayor", Type: "mayor", State: "running"}}})
+	s.UpdateSource("beads", Fragment{Nodes: []Node{{ID: "bead:zep-1", Type: "bead", State: "hooked"}}})
+
+	diff := s.UpdateSource("beads", Fragment{})
+	if diff == nil {
+		t.Fatal("expected diff, got nil")
+	}
+	if len(diff.NodesRemoved) != 1 || diff.NodesRemoved[0] != "bead:zep-1" {
+		t.Errorf("expected bead removed, got %v", diff.NodesRemoved)
+	}
+	if diff.Summary == nil || diff.Summary.OpenBeads != 0 {
+		t.Errorf("expected open_beads 0 in summary, got %+v", diff.Summary)
+	}
+}
+
+func TestSummaryCountsActiveConvoys(t *testing.T) {
+	s := NewStore()
+	s.UpdateSource("convoys", Fragment{Nodes: []Node{
+		{ID: "convoy:hq-cv-1", Type: "convoy", State: "active"},
+		{ID: "convoy:hq-cv-2", Type: "convoy", State: "completed"},
+	}})
+
+	snap := s.GetSnapshot()
+	if snap.Summary.ActiveConvoys != 1 {
+		t.Errorf("expected active_convoys 1, got %d", snap.Summary.ActiveConvoys)
+	}
+}
+
+func TestAppendActivity(t *testing.T) {
+	s := NewStore()
+	if diff := s.AppendActivity(); diff != nil {
+		t.Errorf("expected nil diff for no activity, got %+v", diff)
+	}
+
+	diff := s.AppendActivity(Activity{Event: "bead_hooked", Detail: "zep-1"})
+	if diff == nil || len(diff.ActivityAppend) != 1 {
+		t.Fatalf("expected diff with 1 activity, got %+v", diff)
+	}
+	if diff.Type != "diff" {
+		t.Errorf("expected type 'diff', got %q", diff.Type)
+	}
+	if snap := s.GetSnapshot(); len(snap.Activity) != 1 {
+		t.Errorf("expected 1 activity in snapshot, got %d", len(snap.Activity))
+	}
+}
+
+func TestActivityKeyRecordedOnce(t *testing.T) {
+	s := NewStore()
+	key := MergeKey("merge_complete", "polecat/toast/gt-12")
+	if d := s.AppendActivity(Activity{Event: "merge_complete", Agent: "gastown/refinery", Key: key}); d == nil {
+		t.Fatal("expected the first report recorded")
+	}
+	if d := s.AppendActivity(Activity{Event: "merge_complete", Agent: "gastown/refinery", Key: key}); d != nil {
+		t.Errorf("expected a repeated key dropped, got %+v", d)
+	}
+	d := s.UpdateSource("mergequeue", Fragment{Activities: []Activity{
+		{Event: "merge_complete", Agent: "gastown/polecats/toast", Key: key},
+		{Event: "merge_failed", Agent: "gastown/polecats/nux"},
+	}})
+	if d == nil || len(d.ActivityAppend) != 1 || d.ActivityAppend[0].Event != "merge_failed" {
+		t.Errorf("expected only the unkeyed failure appended, got %+v", d)
+	}
+	if n := len(s.GetSnapshot().Activity); n != 2 {
+		t.Errorf("expected 2 activities, got %d", n)
+	}
+}
+
+func TestUpdateDerivesActivity(t *testing.T) {
+	s := NewStore()
+	s.UpdateSource("topology", Fragment{Nodes: []Node{
+		{ID: "zeppelin/polecats/rust", Type: "polecat", Label: "rust", Rig: "zeppelin", State: "working"},
+	}})
+	s.UpdateSource("beads", Fragment{Nodes: []Node{
+		{ID: "bead:zep-1", Type: "bead", Label: "zep-1", State: "in_progress"},
+		{ID: "bead:zep-2", Type: "bead", Label: "zep-2", State: "unassigned"},
+	}})
+
+	diff := s.UpdateSource("topology", Fragment{Nodes: []Node{
+		{ID: "zeppelin/polecats/nux", Type: "polecat", Label: "nux", Rig: "zeppelin", State: "working"},
+	}})
+	if diff == nil {
+		t.Fatal("expected diff, got nil")
+	}
+	assertEvents(t, diff.ActivityAppend, "polecat_spawned", "polecat_nuked")
+
+	diff = s.UpdateSource("beads", Fragment{Nodes: []Node{
+		{ID: "bead:zep-1", Type: "bead", Label: "zep-1", State: "closed",
+			Metadata: map[string]string{"assignee": "zeppelin/polecats/rust"}},
+		{ID: "bead:zep-2", Type: "bead", Label: "zep-2", State: "hooked"},
+	}})
+	if diff == nil {
+		t.Fatal("expected diff, got nil")
+	}
+	assertEvents(t, diff.ActivityAppend, "bead_closed", "bead_hooked")
+	if diff.ActivityAppend[0].Agent != "zeppelin/polecats/rust" {
+		t.Errorf("expected bead_closed attributed to assignee, got %q", diff.ActivityAppend[0].Agent)
+	}
+
+	if snap := s.GetSnapshot(); len(snap.Activity) != 4 {
+		t.Errorf("expected 4 activities in snapshot, got %d", len(snap.Activity))
+	}
+}
+
+func TestFirstSourceReportIsQuiet(t *testing.T) {
+	s := NewStore()
+	s.UpdateSource("topology", Fragment{Nodes: []Node{{ID: "mayor", Type: "mayor", State: "running"}}})
+
+	diff := s.UpdateSource("polecats", Fragment{Nodes: []Node{
+		{ID: "zeppelin/polecats/rust", Type: "polecat", Label: "rust", Rig: "zeppelin", State: "working"},
+	}})
+	if diff == nil {
+		t.Fatal("expected diff, got nil")
+	}
+	if len(diff.ActivityAppend) != 0 {
+		t.Errorf("expected no activity for a source's first report, got %+v", diff.ActivityAppend)
+	}
+}
+
+func assertEvents(t *testing.T, acts []Activity, want ...string) {
+	t.Helper()
+	if len(acts) != len(want) {
+		t.Fatalf("expected events %v, got %+v", want, acts)
+	}
+	for i, w := range want {
+		if acts[i].Event != w {
+			t.Errorf("activity %d: expected %q, got %q", i, w, acts[i].Event)
+		}
+	}
+}
+
+func TestAnnotations(t *testing.T) {
+	s := NewStore()
+	s.UpdateSource("topology", Fragment{Nodes: []Node{
+		{ID: "zeppelin/polecats/rust", Type: "polecat", Label: "rust", State: "working",
+			Metadata: map[string]string{"hooked_bead": "zep-1"}},
+	}})
+	s.UpdateSource("molecules", Fragment{Annotations: map[string]Annotation{
+		"zeppelin/polecats/rust": {Metadata: map[string]string{"molecule": "zep-mol-1"}},
+		"zeppelin/polecats/gone": {Metadata: map[string]string{"molecule": "zep-mol-2"}},
+	}})
+
+	snap := s.GetSnapshot()
+	if len(snap.Nodes) != 1 {
+		t.Fatalf("expected annotations not to create nodes, got %d nodes", len(snap.Nodes))
+	}
+	md := snap.Nodes[0].Metadata
+	if md["molecule"] != "zep-mol-1" || md["hooked_bead"] != "zep-1" {
+		t.Errorf("unexpected annotated metadata: %v", md)
+	}
+}
+
+func TestAnnotationState(t *testing.T) {
+	s := NewStore()
+	s.UpdateSource("beads", Fragment{Nodes: []Node{
+		{ID: "bead:zep-1", Type: "bead", Label: "zep-1", State: "hooked"},
+	}})
+	diff := s.UpdateSource("mergequeue", Fragment{Annotations: map[string]Annotation{
+		"bead:zep-1": {State: "in_refinery", Metadata: map[string]string{"mq_status": "pending"}},
+	}})
+	if diff == nil || len(diff.NodesUpdated) != 1 || diff.NodesUpdated[0].State != "in_refinery" {
+		t.Fatalf("expected bead to move to in_refinery, got %+v", diff)
+	}
+
+	// Once the merge request leaves the queue the owner's state returns.
+	s.UpdateSource("mergequeue", Fragment{})
+	if st := s.GetSnapshot().Nodes[0].State; st != "hooked" {
+		t.Errorf("expected state hooked after annotation removed, got %q", st)
+	}
+}
+
+func TestFragmentActivities(t *testing.T) {
+	s := NewStore()
+	s.UpdateSource("topology", Fragment{Nodes: []Node{{ID: "mayor", Type: "mayor", State: "running"}}})
+
+	diff := s.UpdateSource("mail", Fragment{Activities: []Activity{
+		{Event: "mail_sent", From: "zeppelin/polecats/rust", To: "mayor"},
+	}})
+	if diff == nil {
+		t.Fatal("expected diff carrying the activity, got nil")
+	}
+	assertEvents(t, diff.ActivityAppend, "mail_sent")
+	if diff.ActivityAppend[0].From != "zeppelin/polecats/rust" {
+		t.Errorf("expected sender to be kept, got %q", diff.ActivityAppend[0].From)
+	}
+}
+
+func TestExpireTransientEdges(t *testing.T) {
+	s := NewStore()
+	now := time.Now()
+	s.UpdateSource("topology", Fragment{Nodes: []Node{
+		{ID: "mayor", Type: "mayor", State: "running"},
+		{ID: "deacon", Type: "deacon", State: "running"},
+	}})
+	s.UpdateSource("mail", Fragment{Edges: []Edge{
+		{Source: "mayor", Target: "deacon", Type: "mail", ExpiresAt: now.Add(time.Second)},
+	}})
+
+	if diff := s.Expire(now); diff != nil {
+		t.Errorf("expected nothing to expire yet, got %+v", diff)
+	}
+
+	diff := s.Expire(now.Add(2 * time.Second))
+	if diff == nil {
+		t.Fatal("expected diff, got nil")
+	}
+	if len(diff.EdgesRemoved) != 1 || diff.EdgesRemoved[0] != "mail:mayor:deacon" {
+		t.Errorf("expected mail edge removed, got %v", diff.EdgesRemoved)
+	}
+	if snap := s.GetSnapshot(); len(snap.Edges) != 0 {
+		t.Errorf("expected 0 edges, got %d", len(snap.Edges))
+	}
+}
+
+func TestDanglingEdges(t *testing.T) {
+	s := NewStore()
+	s.UpdateSource("topology", Fragment{Nodes: []Node{
+		{ID: "mayor", Type: "mayor", State: "running"},
+		{ID: "zeppelin/polecats/rust", Type: "polecat", State: "working"},
+	}})
+	s.UpdateSource("polecats", Fragment{Edges: []Edge{
+		{Source: "zeppelin/polecats/rust", Target: "bead:zep-1", Type: "hook"},
+	}})
+
+	if snap := s.GetSnapshot(); len(snap.Edges) != 0 {
+		t.Errorf("expected edge to missing bead to be withheld, got %+v", snap.Edges)
+	}
+	dangling := s.DanglingEdges()
+	if len(dangling) != 1 || dangling[0].Source != "polecats" || dangling[0].Missing != "bead:zep-1" {
+		t.Fatalf("expected hook edge reported as dangling, got %+v", dangling)
+	}
+	if ev := s.HealthEvent(); len(ev.Dangling) != 1 {
+		t.Errorf("expected dangling edge in health event, got %+v", ev.Dangling)
+	}
+
+	// Once the bead shows up, the edge is sent.
+	diff := s.UpdateSource("beads", Fragment{Nodes: []Node{{ID: "bead:zep-1", Type: "bead", State: "hooked"}}})
+	if diff == nil || len(diff.EdgesAdded) != 1 {
+		t.Fatalf("expected hook edge added, got %+v", diff)
+	}
+	if dangling := s.DanglingEdges(); len(dangling) != 0 {
+		t.Errorf("expected no dangling edges, got %+v", dangling)
+	}
+}
+
+func TestMarkStaleKeepsNodes(t *testing.T) {
+	s := NewStore()
+	s.UpdateSource("topology", Fragment{Nodes: []Node{
+		{ID: "mayor", Type: "mayor", State: "running"},
+		{ID: "zeppelin/polecats/rust", Type: "polecat", State: "working"},
+	}})
+	s.UpdateSource("beads", Fragment{Nodes: []Node{{ID: "bead:zep-1", Type: "bead", State: "hooked"}}})
+	s.UpdateSource("polecats", Fragment{Nodes: []Node{{ID: "zeppelin/polecats/rust", Type: "polecat", State: "working"}}})
+
+	diff := s.MarkStale("topology")
+	if diff == nil {
+		t.Fatal("expected diff, got nil")
+	}
+	if len(diff.NodesRemoved) != 0 {
+		t.Errorf("expected no nodes removed, got %v", diff.NodesRemoved)
+	}
+	if len(diff.NodesUpdated) != 1 || diff.NodesUpdated[0].ID != "mayor" || !diff.NodesUpdated[0].Stale {
+		t.Errorf("expected only mayor to turn stale, got %+v", diff.NodesUpdated)
+	}
+	if again := s.MarkStale("topology"); again != nil {
+		t.Errorf("expected nil diff when already stale, got %+v", again)
+	}
+
+	// A successful report clears the flag.
+	diff = s.UpdateSource("topology", Fragment{Nodes: []Node{{ID: "mayor", Type: "mayor", State: "running"}}})
+	if diff == nil {
+		t.Fatal("expected diff, got nil")
+	}
+	for _, n := range s.GetSnapshot().Nodes {
+		if n.Stale {
+			t.Errorf("expected %s to be fresh", n.ID)
+		}
+	}
+}
+
+func TestNodeReportedStale(t *testing.T) {
+	s := NewStore()
+	s.UpdateSource("beads", Fragment{Nodes: []Node{
+		{ID: "bead:gt-1", Type: "bead", State: "hooked", Stale: true},
+		{ID: "bead:hq-1", Type: "bead", State: "open"},
+	}})
+	s.UpdateSource("convoys", Fragment{Nodes: []Node{{ID: "bead:hq-1", Type: "bead", State: "open", Stale: true}}})
+	for _, n := range s.GetSnapshot().Nodes {
+		if want := n.ID == "bead:gt-1"; n.Stale != want {
+			t.Errorf("%s: stale = %v, want %v", n.ID, n.Stale, want)
+		}
+	}
+}
+
+func TestSetSourceHealth(t *testing.T) {
+	s := NewStore()
+	if !s.SetSourceHealth(SourceHealth{Name: "beads"}) {
+		t.Error("expected first report to be a change")
+	}
+	if s.SetSourceHealth(SourceHealth{Name: "beads", DurationMS: 12}) {
+		t.Error("expected duration-only update not to be a change")
+	}
+	if !s.SetSourceHealth(SourceHealth{Name: "beads", Stale: true, LastError: "bd: exit status 1"}) {
+		t.Error("expected failure to be a change")
+	}
+
+	ev := s.HealthEvent()
+	if ev.Type != "sources" || len(ev.Sources) != 1 || !ev.Sources[0].Stale {
+		t.Errorf("unexpected health event %+v", ev)
+	}
+}
+
+func TestDiffInTown(t *testing.T) {
+	s := NewStore()
+	s.UpdateSource("topology", Fragment{
+		Nodes: []Node{
+			{ID: "gastown/polecats/rust", Type: "polecat", Rig: "gastown", State: "working"},
+			{ID: "bead:gt-1", Type: "bead", State: "hooked"},
+		},
+		Edges: []Edge{{Source: "gastown/polecats/rust", Target: "bead:gt-1", Type: "hook"}},
+	})
+	diff := s.UpdateSource("topology", Fragment{Nodes: []Node{
+		{ID: "gastown/polecats/rust", Type: "polecat", Rig: "gastown", State: "idle"},
+	}})
+
+	d := diff.InTown("team")
+	if len(d.NodesRemoved) != 1 || d.NodesRemoved[0] != "team:bead:gt-1" {
+		t.Errorf("expected namespaced node removal, got %v", d.NodesRemoved)
+	}
+	if len(d.NodesUpdated) != 1 || d.NodesUpdated[0].ID != "team:gastown/polecats/rust" || d.NodesUpdated[0].Rig != "team:gastown" {
+		t.Errorf("expected namespaced node update, got %+v", d.NodesUpdated)
+	}
+	if len(d.EdgesRemoved) != 1 || d.EdgesRemoved[0] != "hook:team:gastown/polecats/rust:team:bead:gt-1" {
+		t.Errorf("expected namespaced edge key, got %v", d.EdgesRemoved)
+	}
+	if diff.NodesRemoved[0] != "bead:gt-1" {
+		t.Errorf("expected original diff untouched, got %v", diff.NodesRemoved)
+	}
+}
+
+func TestCombineSnapshots(t *testing.T) {
+	a, b := NewStore(), NewStore()
+	a.UpdateSource("topology", Fragment{Nodes: []Node{{ID: "mayor", Type: "mayor", State: "running"}, {ID: "gastown/witness", Type: "witness"}}})
+	b.UpdateSource("topology", Fragment{Nodes: []Node{{ID: "mayor", Type: "mayor", State: "running"}}})
+
+	snap := CombineSnapshots([]Snapshot{a.GetSnapshot().InTown("home"), b.GetSnapshot().InTown("team")}, DefaultActivityLimit)
+	var ids []string
+	for _, n := range snap.Nodes {
+		ids = append(ids, n.ID)
+	}
+	if want := []string{"home:mayor", "home:gastown/witness", "team:mayor"}; !slices.Equal(ids, want) {
+		t.Errorf("expected nodes %v, got %v", want, ids)
+	}
+	if snap.Summary.RigCount != 1 {
+		t.Errorf("expected summed summary, got %+v", snap.Summary)
+	}
+}
+
+func TestSnapshotAt(t *testing.T) {
+	s := NewStore()
+	states := []string{"working", "idle", "stuck"}
+	var times []time.Time
+	var want []Snapshot
+	for i := range 250 {
+		s.UpdateSource("topology", Fragment{Nodes: []Node{
+			{ID: "mayor", Type: "mayor", State: "running"},
+			{ID: "gastown/polecats/rust", Type: "polecat", State: states[i%len(states)], Metadata: map[string]string{"n": strconv.Itoa(i)}},
+		}})
+		if i%7 == 0 {
+			s.AppendActivity(Activity{Event: "test", Detail: strconv.Itoa(i)})
+		}
+		times = append(times, time.Now())
+		want = append(want, s.GetSnapshot())
+	}
+
+	for _, i := range []int{0, 1, 98, 99, 100, 150, 249} {
+		got, err := s.SnapshotAt(times[i])
+		if err != nil {
+			t.Fatalf("SnapshotAt(%d): %v", i, err)
+		}
+		if !snapshotsEqual(got, want[i]) {
+			t.Errorf("SnapshotAt(%d): got nodes %+v, activity %d; want nodes %+v, activity %d",
+				i, got.Nodes, len(got.Activity), want[i].Nodes, len(want[i].Activity))
+		}
+	}
+
+	if _, err := s.SnapshotAt(times[0].Add(-time.Hour)); err != ErrHistoryGone {
+		t.Errorf("expected ErrHistoryGone before the store existed, got %v", err)
+	}
+
+	h, err := s.History(times[10], times[20])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !snapshotsEqual(h.Snapshot, want[10]) || len(h.Diffs) < 10 {
+		t.Errorf("expected history from update 10 with its diffs, got %d diffs", len(h.Diffs))
+	}
+}
+
+func TestHistoryLimit(t *testing.T) {
+	s := NewStore()
+	s.SetHistoryLimit(150)
+	var times []time.Time
+	for i := range 400 {
+		s.UpdateSource("topology", Fragment{Nodes: []Node{
+			{ID: "mayor", Type: "mayor", State: "running", Metadata: map[string]string{"n": strconv.Itoa(i)}},
+		}})
+		times = append(times, time.Now())
+	}
+	if _, err := s.SnapshotAt(times[10]); err != ErrHistoryGone {
+		t.Errorf("expected old history to be dropped, got %v", err)
+	}
+	snap, err := s.SnapshotAt(times[350])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := snap.Nodes[0].Metadata["n"]; n != "350" {
+		t.Errorf("expected state after update 350, got %s", n)
+	}
+	if len(s.history.diffs) > 150+keyframeEvery {
+		t.Errorf("expected history trimmed near its limit, holds %d diffs", len(s.history.diffs))
+	}
+}
+
+func TestDiffsSince(t *testing.T) {
+	s := NewStore()
+	var versions []uint64
+	for i := range 5 {
+		d := s.UpdateSource("topology", Fragment{Nodes: []Node{
+			{ID: "mayor", Type: "mayor", State: "running", Metadata: map[string]string{"n": strconv.Itoa(i)}},
+		}})
+		versions = append(versions, d.Version)
+	}
+	if !slices.Equal(versions, []uint64{1, 2, 3, 4, 5}) {
+		t.Errorf("expected consecutive versions, got %v", versions)
+	}
+	snap := s.GetSnapshot()
+	if snap.Version != 5 || snap.Epoch == "" {
+		t.Errorf("expected the snapshot at version 5 of an epoch, got %q-%d", snap.Epoch, snap.Version)
+	}
+
+	diffs, err := s.DiffsSince(snap.Epoch, 2, 10)
+	if err != nil || len(diffs) != 3 || diffs[0].Version != 3 {
+		t.Errorf("expected versions 3-5, got %d diffs, %v", len(diffs), err)
+	}
+	if diffs, err := s.DiffsSince(snap.Epoch, 5, 10); err != nil || len(diffs) != 0 {
+		t.Errorf("expected nothing missed at the current version, got %d diffs, %v", len(diffs), err)
+	}
+	for _, tt := range []struct {
+		epoch   string
+		version uint64
+		limit   int
+	}{
+		{"other", 2, 10},    // another run's versions
+		{snap.Epoch, 6, 10}, // a version not reached yet
+		{snap.Epoch, 0, 3},  // too far behind
+	} {
+		if _, err := s.DiffsSince(tt.epoch, tt.version, tt.limit); err != ErrHistoryGone {
+			t.Errorf("DiffsSince(%q, %d, %d): expected ErrHistoryGone, got %v", tt.epoch, tt.version, tt.limit, err)
+		}
+	}
+}
+
+// memJournal keeps what a store journals, for TestRestore.
+type memJournal struct {
+	start Snapshot
+	diffs []*Diff
+}
+
+func (j *memJournal) Start(snap Snapshot) { j.start = cloneSnapshot(snap) }
+
+func (j *memJournal) Record(d *Diff, snap Snapshot) { j.diffs = append(j.diffs, d) }
+
+func TestRestore(t *testing.T) {
+	s := NewStore()
+	j := &memJournal{}
+	s.SetJournal(j)
+	s.UpdateSource("topology", Fragment{
+		Nodes: []Node{{ID: "mayor", Type: "mayor", State: "running"}, {ID: "gastown/polecats/rust", Type: "polecat", State: "working"}},
+		Edges: []Edge{{Source: "mayor", Target: "gastown/polecats/rust", Type: "assigned"}},
+	})
+	s.UpdateSource("beads", Fragment{Nodes: []Node{{ID: "bead:gt-1", Type: "bead", State: "open"}}})
+	s.UpdateSource("topology", Fragment{Nodes: []Node{{ID: "mayor", Type: "mayor", State: "running"}, {ID: "gastown/polecats/rust", Type: "polecat", State: "idle"}}})
+	s.AppendActivity(Activity{Event: "test"})
+
+	r := NewStore()
+	r.Restore(j.start, j.diffs)
+	got := r.GetSnapshot()
+	if !snapshotsEqual(withStale(s.GetSnapshot()), got) {
+		t.Fatalf("expected the journaled state restored as stale, got %+v", got)
+	}
+
+	// Live sources win over the restored state, which fills in the rest.
+	diff := r.UpdateSource("topology", Fragment{Nodes: []Node{{ID: "mayor", Type: "mayor", State: "stopped"}}})
+	if diff == nil || len(diff.NodesUpdated) != 1 || diff.NodesUpdated[0].State != "stopped" || diff.NodesUpdated[0].Stale {
+		t.Errorf("expected the live mayor to replace the restored one, got %+v", diff)
+	}
+	if len(diff.ActivityAppend) != 0 {
+		t.Errorf("expected a source's first report after a restore to be quiet, got %+v", diff.ActivityAppend)
+	}
+	if n := len(r.GetSnapshot().Nodes); n != 3 {
+		t.Errorf("expected restored nodes kept until dropped, got %d nodes", n)
+	}
+
+	before := s.GetSnapshot()
+	if got.Epoch != before.Epoch || got.Version != before.Version+1 {
+		t.Errorf("expected versions to carry on from %s-%d, got %s-%d", before.Epoch, before.Version, got.Epoch, got.Version)
+	}
+
+	diff = r.DropRestored()
+	if diff == nil || len(diff.NodesRemoved) != 2 {
+		t.Errorf("expected unreported nodes removed, got %+v", diff)
+	}
+	if r.DropRestored() != nil {
+		t.Error("expected nothing left to drop")
+	}
+}
+
+// withStale returns the snapshot with every node marked stale.
+func withStale(snap Snapshot) Snapshot {
+	snap.Nodes = slices.Clone(snap.Nodes)
+	for i := range snap.Nodes {
+		snap.Nodes[i].Stale = true
+	}
+	return snap
+}
+
+// snapshotsEqual compares the nodes, edges, activity and summary of two
+// snapshots, ignoring order.
+func snapshotsEqual(a, b Snapshot) bool {
+	sorted := func(s Snapshot) string {
+		nodes := slices.Clone(s.Nodes)
+		slices.SortFunc(nodes, func(x, y Node) int { return strings.Compare(x.ID, y.ID) })
+		edges := slices.Clone(s.Edges)
+		slices.SortFunc(edges, func(x, y Edge) int { return strings.Compare(edgeKey(x), edgeKey(y)) })
+		data, _ := json.Marshal([]any{nodes, edges, s.Activity, s.Summary})
+		return string(data)
+	}
+	return sorted(a) == sorted(b)
+}
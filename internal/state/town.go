@@ -0,0 +1,134 @@
+package state
+
+import "slices"
+
+// TownSeparator separates the town name from the town-local ID in namespaced
+// node IDs, as in "team:gastown/polecats/rust".
+const TownSeparator = ":"
+
+// Namespace returns the ID of a town-local node in a view spanning several
+// towns. Empty IDs stay empty.
+func Namespace(town, id string) string {
+	if id == "" {
+		return ""
+	}
+	return town + TownSeparator + id
+}
+
+// InTown returns a copy of the snapshot with node IDs, rigs, edge endpoints
+// and activity agents namespaced by town.
+func (s Snapshot) InTown(town string) Snapshot {
+	s.Nodes = nodesInTown(town, s.Nodes)
+	s.Edges = edgesInTown(town, s.Edges)
+	s.Activity = activitiesInTown(town, s.Activity)
+	return s
+}
+
+// InTown returns a copy of the diff namespaced by town, like Snapshot.InTown.
+func (d *Diff) InTown(town string) *Diff {
+	out := *d
+	out.NodesAdded = nodesInTown(town, d.NodesAdded)
+	out.NodesUpdated = nodesInTown(town, d.NodesUpdated)
+	out.NodesRemoved = nil
+	for _, id := range d.NodesRemoved {
+		out.NodesRemoved = append(out.NodesRemoved, Namespace(town, id))
+	}
+	out.EdgesAdded = edgesInTown(town, d.EdgesAdded)
+	// Node IDs may contain colons, so removed edge keys are rebuilt from the
+	// edges rather than split.
+	out.removedEdges = edgesInTown(town, d.removedEdges)
+	out.EdgesRemoved = nil
+	for _, e := range out.removedEdges {
+		out.EdgesRemoved = append(out.EdgesRemoved, edgeKey(e))
+	}
+	out.ActivityAppend = activitiesInTown(town, d.ActivityAppend)
+	return &out
+}
+
+// InTown returns a copy of the health event with source names and dangling
+// edges namespaced by town.
+func (h HealthEvent) InTown(town string) HealthEvent {
+	h.Sources = slices.Clone(h.Sources)
+	for i := range h.Sources {
+		h.Sources[i].Name = Namespace(town, h.Sources[i].Name)
+	}
+	h.Dangling = slices.Clone(h.Dangling)
+	for i, d := range h.Dangling {
+		h.Dangling[i].Source = Namespace(town, d.Source)
+		h.Dangling[i].Edge = edgesInTown(town, []Edge{d.Edge})[0]
+		h.Dangling[i].Missing = Namespace(town, d.Missing)
+	}
+	return h
+}
+
+// Add returns the sum of two summaries.
+func (s Summary) Add(o Summary) Summary {
+	return Summary{
+		RigCount:       s.RigCount + o.RigCount,
+		ActivePolecats: s.ActivePolecats + o.ActivePolecats,
+		OpenBeads:      s.OpenBeads + o.OpenBeads,
+		ActiveConvoys:  s.ActiveConvoys + o.ActiveConvoys,
+	}
+}
+
+// CombineSnapshots merges already namespaced snapshots of several towns into
+// one. Activity is interleaved by time, keeping the latest limit entries.
+func CombineSnapshots(snaps []Snapshot, limit int) Snapshot {
+	out := Snapshot{Type: "snapshot", Nodes: []Node{}, Edges: []Edge{}, Activity: []Activity{}}
+	for _, s := range snaps {
+		out.Nodes = append(out.Nodes, s.Nodes...)
+		out.Edges = append(out.Edges, s.Edges...)
+		out.Activity = append(out.Activity, s.Activity...)
+		out.Summary = out.Summary.Add(s.Summary)
+		if s.Timestamp.After(out.Timestamp) {
+			out.Timestamp = s.Timestamp
+		}
+	}
+	slices.SortStableFunc(out.Activity, func(a, b Activity) int {
+		return a.Timestamp.Compare(b.Timestamp)
+	})
+	if len(out.Activity) > limit {
+		out.Activity = out.Activity[len(out.Activity)-limit:]
+	}
+	return out
+}
+
+func nodesInTown(town string, nodes []Node) []Node {
+	if nodes == nil {
+		return nil
+	}
+	out := make([]Node, len(nodes))
+	for i, n := range nodes {
+		n.ID = Namespace(town, n.ID)
+		n.Rig = Namespace(town, n.Rig)
+		out[i] = n
+	}
+	return out
+}
+
+func edgesInTown(town string, edges []Edge) []Edge {
+	if edges == nil {
+		return nil
+	}
+	out := make([]Edge, len(edges))
+	for i, e := range edges {
+		e.Source = Namespace(town, e.Source)
+		e.Target = Namespace(town, e.Target)
+		out[i] = e
+	}
+	return out
+}
+
+func activitiesInTown(town string, acts []Activity) []Activity {
+	if acts == nil {
+		return nil
+	}
+	out := make([]Activity, len(acts))
+	for i, a := range acts {
+		a.Agent = Namespace(town, a.Agent)
+		a.From = Namespace(town, a.From)
+		a.To = Namespace(town, a.To)
+		out[i] = a
+	}
+	return out
+}